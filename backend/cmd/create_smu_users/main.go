@@ -3,13 +3,21 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 func main() {
-	baseURL := "http://localhost:8080/api/v1/auth/register"
+	baseURL := flag.String("url", "http://localhost:8080", "backend base URL")
+	domain := flag.String("domain", "smu.edu", "email domain of the institution")
+	host := flag.String("host", "", "hostname of the institution's tenant, when it differs from -url")
+	name := flag.String("name", "SMU", "institution name used in log output")
+	flag.Parse()
+
+	registerURL := strings.TrimSuffix(*baseURL, "/") + "/api/v1/auth/register"
 
 	users := []struct {
 		Email    string `json:"email"`
@@ -17,17 +25,27 @@ func main() {
 		Name     string `json:"name"`
 		Role     string `json:"role"`
 	}{
-		{"editor@smu.edu", "123456", "Editor User", "editor"},
-		{"admin@smu.edu", "123456", "Admin User", "admin"},
-		{"coordinator@smu.edu", "123456", "Coordinator User", "coordinator"},
+		{"editor@" + *domain, "123456", "Editor User", "editor"},
+		{"admin@" + *domain, "123456", "Admin User", "admin"},
+		{"coordinator@" + *domain, "123456", "Coordinator User", "coordinator"},
 	}
 
-	fmt.Println("Creating SMU users...")
+	fmt.Printf("Creating %s users...\n", *name)
 
 	for _, u := range users {
 		jsonData, _ := json.Marshal(u)
-		resp, err := http.Post(baseURL, "application/json", bytes.NewBuffer(jsonData))
+		req, err := http.NewRequest(http.MethodPost, registerURL, bytes.NewBuffer(jsonData))
+		if err != nil {
+			fmt.Printf("❌ Failed to create %s: %v\n", u.Name, err)
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+		// The backend resolves the tenant from the Host header
+		if *host != "" {
+			req.Host = *host
+		}
 
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			fmt.Printf("❌ Failed to create %s: %v\n", u.Name, err)
 			continue
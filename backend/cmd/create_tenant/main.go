@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"strings"
+
+	"rpms-backend/internal/config"
+	"rpms-backend/internal/database"
+	"rpms-backend/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	slug := flag.String("slug", "", "short unique name of the institution, e.g. aau")
+	name := flag.String("name", "", "full name of the institution")
+	hosts := flag.String("hosts", "", "comma separated hostnames served for this institution")
+	prefix := flag.String("prefix", "", "publication ID prefix (defaults to <SLUG>_P)")
+	start := flag.Int64("start", 1, "first publication ID number")
+	bucket := flag.String("bucket", "", "Supabase storage bucket for uploads (defaults to the shared bucket)")
+	flag.Parse()
+
+	if *slug == "" || *name == "" {
+		log.Fatal("-slug and -name are required")
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg := config.New()
+
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	if err := database.RunMigrations(db); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	var hostnames []string
+	for _, h := range strings.Split(*hosts, ",") {
+		if h = tenant.NormalizeHost(h); h != "" {
+			hostnames = append(hostnames, h)
+		}
+	}
+
+	t := tenant.Tenant{
+		Slug:      strings.ToLower(*slug),
+		Name:      *name,
+		Hostnames: hostnames,
+		Config: tenant.Config{
+			Branding:            tenant.Branding{DisplayName: *name},
+			PublicationIDPrefix: *prefix,
+			PublicationIDStart:  *start,
+			SupabaseBucket:      *bucket,
+		},
+	}
+	if t.Config.PublicationIDPrefix == "" {
+		t.Config.PublicationIDPrefix = t.PublicationIDPrefix()
+	}
+	if t.Hostnames == nil {
+		t.Hostnames = []string{}
+	}
+
+	var id uuid.UUID
+	err = db.Pool.QueryRow(context.Background(), `
+		INSERT INTO tenants (slug, name, hostnames, config)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, t.Slug, t.Name, t.Hostnames, t.Config).Scan(&id)
+	if err != nil {
+		log.Fatal("Failed to create tenant:", err)
+	}
+
+	log.Printf("Created tenant %s (%s) with ID %s", t.Name, t.Slug, id)
+	log.Printf("Publication IDs start at %s", t.NextPublicationID(""))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"rpms-backend/internal/config"
+	"rpms-backend/internal/database"
+	"rpms-backend/internal/importer"
+	"rpms-backend/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	kind := flag.String("kind", importer.KindPapers, "what the CSV contains: papers or projects")
+	file := flag.String("file", "", "path to the CSV export")
+	dryRun := flag.Bool("dry-run", true, "preview the import without writing to the database")
+	tenantFlag := flag.String("tenant", tenant.DefaultID.String(), "ID of the institution to import into")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+	tenantID, err := uuid.Parse(*tenantFlag)
+	if err != nil {
+		log.Fatal("Invalid -tenant:", err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg := config.New()
+
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal("Failed to open CSV:", err)
+	}
+	defer f.Close()
+
+	report, err := importer.New(db, tenantID).Import(context.Background(), f, *kind, *dryRun)
+	if err != nil {
+		log.Fatal("Import failed:", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	if report.DryRun {
+		log.Printf("Dry run: %d valid, %d invalid rows. Re-run with -dry-run=false to import.", report.ValidRows, report.InvalidRows)
+	} else {
+		log.Printf("Imported %d rows (%d authors created), skipped %d invalid rows.", report.Imported, report.AuthorsCreated, report.InvalidRows)
+	}
+}
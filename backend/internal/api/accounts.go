@@ -0,0 +1,653 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const accountSummaryColumns = `
+	SELECT u.id, u.email, u.name, u.role, COALESCE(u.is_verified, FALSE), u.is_active, u.reverification_required,
+		   COALESCE(u.totp_enabled, FALSE), u.deactivated_at,
+		   (SELECT MAX(a.created_at) FROM login_attempts a WHERE a.user_id = u.id AND a.outcome = 'success'),
+		   u.created_at
+	FROM users u
+	WHERE u.tenant_id = $1`
+
+func scanAccountSummary(row pgx.Row, account *models.AccountSummary) error {
+	return row.Scan(&account.ID, &account.Email, &account.Name, &account.Role, &account.IsVerified, &account.IsActive,
+		&account.ReverificationRequired, &account.MFAEnabled, &account.DeactivatedAt, &account.LastLoginAt, &account.CreatedAt)
+}
+
+// accountTarget parses the user in the path, refusing the caller's own account for
+// changes an admin must not make to themselves
+func accountTarget(c *gin.Context, action string) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	if userID.String() == c.GetString("user_id") {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You cannot %s your own account", action)})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// ListUsers searches every user of the tenant, filtered by name or email, role and status
+func (s *Server) ListUsers(c *gin.Context) {
+	filters := ""
+	args := []interface{}{tenantID(c)}
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		args = append(args, "%"+q+"%")
+		filters += fmt.Sprintf(" AND (u.name ILIKE $%d OR u.email ILIKE $%d)", len(args), len(args))
+	}
+	if role := c.Query("role"); role != "" {
+		args = append(args, role)
+		filters += fmt.Sprintf(` AND (u.role = $%d OR EXISTS (
+			SELECT 1 FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+			WHERE ur.user_id = u.id AND r.name = $%d))`, len(args), len(args))
+	}
+	switch c.Query("status") {
+	case "":
+	case "active":
+		filters += " AND u.is_active"
+	case "inactive":
+		filters += " AND NOT u.is_active"
+	case "unverified":
+		filters += " AND NOT COALESCE(u.is_verified, FALSE)"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected active, inactive or unverified"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ctx := c.Request.Context()
+	result := models.AccountPage{Users: []models.AccountSummary{}, Page: page, Limit: limit}
+
+	if err := s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM users u WHERE u.tenant_id = $1"+filters, args...).Scan(&result.Total); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	query := accountSummaryColumns + filters + fmt.Sprintf(" ORDER BY u.created_at DESC, u.email LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var account models.AccountSummary
+		if err := scanAccountSummary(rows, &account); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan user"})
+			return
+		}
+		result.Users = append(result.Users, account)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetUserDetails returns a user's profile, status, roles and the content they own
+func (s *Server) GetUserDetails(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var detail models.AccountDetail
+	err = scanAccountSummary(s.db.Pool.QueryRow(ctx, accountSummaryColumns+" AND u.id = $2", tenantID(c), userID), &detail.AccountSummary)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	profile := &detail.Profile
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT id, email, name, role, COALESCE(avatar, ''), COALESCE(bio, ''), preferences, COALESCE(is_verified, FALSE), created_at, updated_at,
+			   COALESCE(academic_year, ''), COALESCE(author_type, ''), COALESCE(author_category, ''), COALESCE(academic_rank, ''),
+			   COALESCE(qualification, ''), COALESCE(employment_type, ''), COALESCE(gender, ''), COALESCE(date_of_birth, ''),
+			   COALESCE(orcid, ''), expertise_keywords, expertise_fields, tenant_id
+		FROM users WHERE id = $1
+	`, userID).Scan(
+		&profile.ID, &profile.Email, &profile.Name, &profile.Role, &profile.Avatar, &profile.Bio, &profile.Preferences, &profile.IsVerified,
+		&profile.CreatedAt, &profile.UpdatedAt, &profile.AcademicYear, &profile.AuthorType, &profile.AuthorCategory, &profile.AcademicRank,
+		&profile.Qualification, &profile.EmploymentType, &profile.Gender, &profile.DateOfBirth, &profile.ORCID,
+		&profile.ExpertiseKeywords, &profile.ExpertiseFields, &profile.TenantID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	if detail.Roles, err = s.userRoles(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT (SELECT COUNT(*) FROM papers WHERE author_id = $1 AND deleted_at IS NULL),
+			   (SELECT COUNT(*) FROM events WHERE coordinator_id = $1),
+			   (SELECT COUNT(*) FROM news WHERE editor_id = $1),
+			   (SELECT COUNT(*) FROM calls WHERE coordinator_id = $1),
+			   (SELECT COUNT(*) FROM reviews WHERE reviewer_id = $1)
+	`, userID).Scan(&detail.Papers, &detail.Events, &detail.News, &detail.Calls, &detail.Reviews)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count the user's content"})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// ChangeUserRole sets a user's primary role. Access tokens carrying the old role stop
+// working at once; the user keeps their sessions and picks up the new role on refresh.
+func (s *Server) ChangeUserRole(c *gin.Context) {
+	userID, ok := accountTarget(c, "change the role of")
+	if !ok {
+		return
+	}
+
+	var req models.ChangeUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var previous string
+	err = tx.QueryRow(ctx, "SELECT role FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if previous == req.Role {
+		c.JSON(http.StatusOK, gin.H{"message": "Role unchanged", "role": req.Role})
+		return
+	}
+	if !s.mayAssignRole(c, previous, req.Role) {
+		return
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2", req.Role, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+		return
+	}
+	// The provider is updated before committing so both sides agree if it fails
+	if err := s.identity.UpdateMetadata(ctx, userID, map[string]interface{}{"role": req.Role}); err != nil {
+		fmt.Printf("Failed to update the identity provider: %v\n", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to update the identity provider"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+		return
+	}
+
+	s.permissions.Invalidate()
+	if err := s.tokens.ExpireAccessTokens(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Role changed, but failed to expire the user's access tokens"})
+		return
+	}
+
+	go func() {
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message) VALUES ($1, $2)",
+			userID, fmt.Sprintf("Your role was changed from %s to %s by an administrator.", previous, req.Role))
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role changed", "role": req.Role})
+}
+
+// mayAssignRole rejects a role change that would let the caller hand out more than they hold.
+// Without role.manage, admins cannot be made or demoted, and the new role may only grant
+// permissions the caller has.
+func (s *Server) mayAssignRole(c *gin.Context, previous, role string) bool {
+	if s.can(c, rbac.RoleManage) {
+		return true
+	}
+	if previous == rbac.RoleAdmin || role == rbac.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Granting or removing the admin role requires role.manage"})
+		return false
+	}
+
+	var granted []string
+	err := s.db.Pool.QueryRow(c.Request.Context(),
+		"SELECT permissions FROM roles WHERE tenant_id = $1 AND name = $2", tenantID(c), role).Scan(&granted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Tenants created without seeded roles use the built-in definition
+		granted, err = rbac.DefaultRoles[role], nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role"})
+		return false
+	}
+	for _, permission := range granted {
+		if !s.can(c, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "The role grants permissions you do not hold"})
+			return false
+		}
+	}
+	return true
+}
+
+// setAccountActive flips users.is_active together with the provider's sign in ban
+func (s *Server) setAccountActive(ctx context.Context, userID uuid.UUID, active bool) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET is_active = $1, deactivated_at = CASE WHEN $1 THEN NULL ELSE NOW() END, updated_at = NOW()
+		WHERE id = $2
+	`, active, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	if err := s.identity.SetDisabled(ctx, userID, !active); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeactivateUser blocks a user from signing in and ends their sessions, keeping their data
+func (s *Server) DeactivateUser(c *gin.Context) {
+	userID, ok := accountTarget(c, "deactivate")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := s.setAccountActive(ctx, userID, false)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		fmt.Printf("Failed to deactivate user: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate user"})
+		return
+	}
+	if err := s.tokens.RevokeUser(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User deactivated, but failed to end their sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deactivated"})
+}
+
+// ReactivateUser lets a deactivated user sign in again
+func (s *Server) ReactivateUser(c *gin.Context) {
+	userID, ok := accountTarget(c, "reactivate")
+	if !ok {
+		return
+	}
+
+	err := s.setAccountActive(c.Request.Context(), userID, true)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		fmt.Printf("Failed to reactivate user: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate user"})
+		return
+	}
+
+	go func() {
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message) VALUES ($1, $2)",
+			userID, "Your account was reactivated by an administrator.")
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": "User reactivated"})
+}
+
+// sendReverification emails a fresh code to an account that has to confirm its email again.
+// The code is checked by VerifyEmail against users.verification_code.
+func (s *Server) sendReverification(ctx context.Context, userID uuid.UUID, email string) error {
+	code, err := auth.NewVerificationCode()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE users
+		SET is_verified = FALSE, reverification_required = TRUE, verification_code = $1, verification_sent_at = NOW()
+		WHERE id = $2
+	`, auth.HashCode(code), userID)
+	if err != nil {
+		return err
+	}
+	return s.emailSender.SendVerificationEmail(email, code)
+}
+
+// ForceReverification signs a user out and requires them to confirm their email with a new
+// code before they can sign in again
+func (s *Server) ForceReverification(c *gin.Context) {
+	userID, email, ok := s.userEmail(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := s.sendReverification(ctx, userID, email); err != nil {
+		fmt.Printf("Failed to send verification code: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification code"})
+		return
+	}
+	if err := s.tokens.RevokeUser(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Verification required, but failed to end the user's sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code sent to " + email})
+}
+
+// reverificationTTL bounds how long a code sent by ForceReverification stays valid
+const reverificationTTL = 24 * time.Hour
+
+// verifyExistingAccount confirms the email of an account an admin asked to reverify. It reports
+// false when the email has no such account, leaving the registration flow to handle it.
+func (s *Server) verifyExistingAccount(c *gin.Context, email, code string) bool {
+	ctx := c.Request.Context()
+
+	var userID uuid.UUID
+	var codeHash string
+	var sentAt *time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, COALESCE(verification_code, ''), verification_sent_at FROM users
+		WHERE LOWER(email) = LOWER($1) AND reverification_required
+	`, email).Scan(&userID, &codeHash, &sentAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error checking user"})
+		return true
+	}
+
+	key := "reverify:" + userID.String()
+	if !s.mfaLimiter.Allow(key) {
+		rejectRateLimited(c, s.mfaLimiter.RetryAfter(key))
+		return true
+	}
+	if sentAt == nil || time.Since(*sentAt) > reverificationTTL || auth.HashCode(strings.TrimSpace(code)) != codeHash {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired verification code"})
+		return true
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE users
+		SET is_verified = TRUE, reverification_required = FALSE, verification_code = '', verification_sent_at = NULL
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user status in local database"})
+		return true
+	}
+
+	user, err := s.sessionUser(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return true
+	}
+	if !accountInTenant(c, user.TenantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account belongs to another institution"})
+		return true
+	}
+	user.IsVerified = true
+	s.completeSignIn(c, user)
+	return true
+}
+
+// resendReverification sends a new code to an account an admin asked to reverify, reporting
+// false when the email has no such account
+func (s *Server) resendReverification(c *gin.Context, email string) bool {
+	ctx := c.Request.Context()
+
+	var userID uuid.UUID
+	var address string
+	var sentAt *time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, email, verification_sent_at FROM users
+		WHERE LOWER(email) = LOWER($1) AND reverification_required
+	`, email).Scan(&userID, &address, &sentAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend code"})
+		return true
+	}
+	if sentAt != nil && time.Since(*sentAt) < time.Minute {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait a few seconds before requesting another code."})
+		return true
+	}
+
+	if err := s.sendReverification(ctx, userID, address); err != nil {
+		fmt.Printf("Failed to resend code: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resend code"})
+		return true
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code resent successfully"})
+	return true
+}
+
+// AdminResetPassword either sets a new password for a user or emails them a reset link.
+// Setting it directly signs the user out everywhere.
+func (s *Server) AdminResetPassword(c *gin.Context) {
+	userID, email, ok := s.userEmail(c)
+	if !ok {
+		return
+	}
+
+	var req models.AdminResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.Password == "" {
+		token, err := s.tokens.IssuePasswordReset(ctx, userID, c.ClientIP())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue password reset"})
+			return
+		}
+		if err := s.emailSender.SendPasswordResetEmail(email, token); err != nil {
+			fmt.Printf("Failed to send password reset email: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send password reset email"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Password reset link sent to " + email})
+		return
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+	if err := s.identity.UpdatePassword(ctx, userID, req.Password); err != nil {
+		fmt.Printf("Failed to update the identity provider: %v\n", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to update the identity provider"})
+		return
+	}
+	if _, err := s.db.Pool.Exec(ctx, "UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2", hash, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+	if err := s.tokens.RevokeUser(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password updated, but failed to end the user's sessions"})
+		return
+	}
+
+	go func() {
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message) VALUES ($1, $2)",
+			userID, "Your password was reset by an administrator.")
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated"})
+}
+
+// ownedContent lists the tables whose rows belong to a user and are deleted with them
+var ownedContent = []struct{ table, column string }{
+	{"papers", "author_id"},
+	{"events", "coordinator_id"},
+	{"news", "editor_id"},
+	{"calls", "coordinator_id"},
+}
+
+// DeleteUser removes a user from the database and the identity provider. Their papers,
+// events, news and calls would be deleted with them, so when they own any the admin has to
+// name another user of the tenant in ?reassign_to= to take them over.
+func (s *Server) DeleteUser(c *gin.Context) {
+	userID, ok := accountTarget(c, "delete")
+	if !ok {
+		return
+	}
+
+	var reassignTo *uuid.UUID
+	if raw := c.Query("reassign_to"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil || id == userID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reassign_to user"})
+			return
+		}
+		reassignTo = &id
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, "SELECT id FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	if reassignTo != nil {
+		var active bool
+		err := tx.QueryRow(ctx, "SELECT is_active FROM users WHERE id = $1 AND tenant_id = $2", *reassignTo, tenantID(c)).Scan(&active)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && !active) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reassign_to must be an active user of this institution"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+	}
+
+	owned := gin.H{}
+	reassigned := gin.H{}
+	for _, content := range ownedContent {
+		if reassignTo == nil {
+			var n int
+			query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = $1", content.table, content.column)
+			if err := tx.QueryRow(ctx, query, userID).Scan(&n); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count the user's content"})
+				return
+			}
+			if n > 0 {
+				owned[content.table] = n
+			}
+			continue
+		}
+		query := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2", content.table, content.column, content.column)
+		tag, err := tx.Exec(ctx, query, *reassignTo, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to reassign %s", content.table)})
+			return
+		}
+		reassigned[content.table] = tag.RowsAffected()
+	}
+	if len(owned) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "The user owns content; pass reassign_to to hand it over", "owned": owned})
+		return
+	}
+
+	var address string
+	if err := tx.QueryRow(ctx, "DELETE FROM users WHERE id = $1 RETURNING email", userID).Scan(&address); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+	// A registration still pending for the address must not bring the account back
+	if _, err := tx.Exec(ctx, "DELETE FROM pending_registrations WHERE email = LOWER($1)", address); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+	s.permissions.Invalidate()
+
+	// The provider is only told once the local delete has committed, so that a failed commit
+	// cannot leave a local account the provider no longer knows
+	if err := s.identity.Delete(ctx, userID); err != nil {
+		fmt.Printf("Failed to delete the user from the identity provider: %v\n", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "User deleted, but failed to remove them from the identity provider"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted", "reassigned": reassigned})
+}
@@ -0,0 +1,383 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const assignmentColumns = `ra.id, ra.paper_id, COALESCE(p.title, ''), ra.reviewer_id, COALESCE(u.name, 'Unknown'), ra.assigned_by,
+	ra.status, ra.due_at, ra.responded_at, ra.completed_at, COALESCE(ra.decline_reason, ''), ra.created_at`
+
+const assignmentJoins = `
+	JOIN papers p ON p.id = ra.paper_id
+	LEFT JOIN users u ON u.id = ra.reviewer_id`
+
+func scanAssignment(row pgx.Row, a *models.ReviewAssignment) error {
+	return row.Scan(&a.ID, &a.PaperID, &a.PaperTitle, &a.ReviewerID, &a.ReviewerName, &a.AssignedBy,
+		&a.Status, &a.DueAt, &a.RespondedAt, &a.CompletedAt, &a.DeclineReason, &a.CreatedAt)
+}
+
+func (s *Server) listAssignments(c *gin.Context, where string, args ...interface{}) {
+	rows, err := s.db.Pool.Query(c.Request.Context(), `
+		SELECT `+assignmentColumns+`
+		FROM review_assignments ra`+assignmentJoins+`
+		WHERE `+where+`
+		ORDER BY ra.created_at DESC
+	`, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review assignments"})
+		return
+	}
+	defer rows.Close()
+
+	assignments := []models.ReviewAssignment{}
+	for rows.Next() {
+		var a models.ReviewAssignment
+		if err := scanAssignment(rows, &a); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan review assignment"})
+			return
+		}
+		assignments = append(assignments, a)
+	}
+
+	c.JSON(http.StatusOK, assignments)
+}
+
+// GetPaperReviewAssignments lists who has been invited to review a paper
+func (s *Server) GetPaperReviewAssignments(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	s.listAssignments(c, "ra.paper_id = $1", paperID)
+}
+
+// GetMyReviewAssignments lists the caller's review invitations, open ones first
+func (s *Server) GetMyReviewAssignments(c *gin.Context) {
+	where := "ra.reviewer_id = $1 AND p.deleted_at IS NULL"
+	args := []interface{}{c.GetString("user_id")}
+	if status := c.Query("status"); status != "" {
+		args = append(args, status)
+		where += " AND ra.status = $2"
+	}
+
+	s.listAssignments(c, where, args...)
+}
+
+// CreateReviewAssignment invites a researcher of the institution to review a paper by a due date.
+// A reviewer who declined or whose invitation was cancelled can be invited again.
+func (s *Server) CreateReviewAssignment(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.CreateReviewAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.DueAt == nil {
+		due := time.Now().AddDate(0, 0, models.DefaultReviewDays)
+		req.DueAt = &due
+	}
+	if !req.DueAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Due date must be in the future"})
+		return
+	}
+
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+	if !s.paperAcceptsReviews(c, paperID) {
+		return
+	}
+
+	assignedBy, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var inTenant, isAuthor bool
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM users WHERE id = $2 AND tenant_id = $3 AND is_active),
+			   EXISTS (SELECT 1 FROM (`+authorships+`) a WHERE a.paper_id = $1 AND a.user_id = $2)
+	`, paperID, req.ReviewerID, tenantID(c)).Scan(&inTenant, &isAuthor)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check reviewer"})
+		return
+	}
+	if !inTenant {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if isAuthor {
+		c.JSON(http.StatusConflict, gin.H{"error": "Authors cannot review their own paper"})
+		return
+	}
+
+	var id uuid.UUID
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO review_assignments (paper_id, reviewer_id, assigned_by, due_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (paper_id, reviewer_id) DO UPDATE
+		SET status = 'invited', assigned_by = EXCLUDED.assigned_by, due_at = EXCLUDED.due_at,
+			responded_at = NULL, completed_at = NULL, decline_reason = NULL, created_at = NOW()
+		WHERE review_assignments.status IN ('declined', 'cancelled')
+		RETURNING id
+	`, paperID, req.ReviewerID, assignedBy, req.DueAt).Scan(&id)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "This reviewer has already been invited to review this paper"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review assignment"})
+		return
+	}
+
+	var a models.ReviewAssignment
+	err = scanAssignment(s.db.Pool.QueryRow(ctx, "SELECT "+assignmentColumns+" FROM review_assignments ra"+assignmentJoins+" WHERE ra.id = $1", id), &a)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review assignment"})
+		return
+	}
+
+	go func() {
+		message := fmt.Sprintf("You have been invited to review '%s' by %s", a.PaperTitle, a.DueAt.Format("2 Jan 2006"))
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+			a.ReviewerID, message, a.PaperID)
+	}()
+
+	c.JSON(http.StatusCreated, a)
+}
+
+// respondToAssignment moves the caller's open invitation to accepted or declined
+func (s *Server) respondToAssignment(c *gin.Context, status, reason string) (*models.ReviewAssignment, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID"})
+		return nil, false
+	}
+
+	ctx := c.Request.Context()
+	var a models.ReviewAssignment
+	err = scanAssignment(s.db.Pool.QueryRow(ctx, `
+		WITH ra AS (
+			UPDATE review_assignments
+			SET status = $1, responded_at = NOW(), decline_reason = NULLIF($2, '')
+			WHERE id = $3 AND reviewer_id = $4 AND status = 'invited'
+			RETURNING *
+		)
+		SELECT `+assignmentColumns+`
+		FROM ra`+assignmentJoins,
+		status, reason, id, c.GetString("user_id")), &a)
+	if err == pgx.ErrNoRows {
+		var current string
+		err = s.db.Pool.QueryRow(ctx, "SELECT status FROM review_assignments WHERE id = $1 AND reviewer_id = $2", id, c.GetString("user_id")).Scan(&current)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Review assignment not found"})
+		} else {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("This invitation is already %s", current)})
+		}
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review assignment"})
+		return nil, false
+	}
+
+	if a.AssignedBy != nil {
+		assignedBy := *a.AssignedBy
+		go func() {
+			message := fmt.Sprintf("%s has %s the invitation to review '%s'", a.ReviewerName, status, a.PaperTitle)
+			s.db.Pool.Exec(context.Background(),
+				"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+				assignedBy, message, a.PaperID)
+		}()
+	}
+
+	return &a, true
+}
+
+// AcceptReviewAssignment accepts an invitation to review; editors also join the paper's staff
+func (s *Server) AcceptReviewAssignment(c *gin.Context) {
+	a, ok := s.respondToAssignment(c, models.AssignmentAccepted, "")
+	if !ok {
+		return
+	}
+	s.assignPaperStaff(c.Request.Context(), a.PaperID, a.ReviewerID, a.AssignedBy)
+
+	c.JSON(http.StatusOK, a)
+}
+
+// DeclineReviewAssignment declines an invitation to review, optionally with a reason
+func (s *Server) DeclineReviewAssignment(c *gin.Context) {
+	var req models.DeclineReviewAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a, ok := s.respondToAssignment(c, models.AssignmentDeclined, strings.TrimSpace(req.Reason))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, a)
+}
+
+// CancelReviewAssignment withdraws an open invitation
+func (s *Server) CancelReviewAssignment(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var paperID uuid.UUID
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT ra.paper_id FROM review_assignments ra JOIN papers p ON p.id = ra.paper_id
+		WHERE ra.id = $1 AND p.tenant_id = $2
+	`, id, tenantID(c)).Scan(&paperID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review assignment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review assignment"})
+		return
+	}
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	tag, err := s.db.Pool.Exec(ctx,
+		"UPDATE review_assignments SET status = 'cancelled' WHERE id = $1 AND status IN ('invited', 'accepted')", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel review assignment"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only open invitations can be cancelled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review assignment cancelled"})
+}
+
+// completeAssignment marks the reviewer's open assignment for the paper as done once their review is submitted
+func (s *Server) completeAssignment(ctx context.Context, paperID, reviewerID uuid.UUID) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE review_assignments
+		SET status = 'completed', completed_at = NOW(), responded_at = COALESCE(responded_at, NOW())
+		WHERE paper_id = $1 AND reviewer_id = $2 AND status IN ('invited', 'accepted')
+	`, paperID, reviewerID)
+	return err
+}
+
+// GetReviewerStats reports, for everyone who has been invited to review or has reviewed in the
+// institution, their completed reviews, turnaround, decline rate, overdue work, calibration and open load
+func (s *Server) GetReviewerStats(c *gin.Context) {
+	// Staff limited to their units only see activity on papers within them
+	scope, err := s.staffUnitScope(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+		return
+	}
+	inScope := "($2::uuid[] IS NULL OR p.unit_id IS NULL OR " + inUnitSubtree("p.unit_id", 2) + ")"
+
+	rows, err := s.db.Pool.Query(c.Request.Context(), `
+		WITH a AS (
+			SELECT ra.reviewer_id,
+				   COUNT(*) FILTER (WHERE ra.status != 'cancelled') AS invitations,
+				   COUNT(*) FILTER (WHERE ra.status = 'declined') AS declined,
+				   COUNT(*) FILTER (WHERE ra.status IN ('invited', 'accepted')) AS open_load,
+				   COUNT(*) FILTER (WHERE ra.status IN ('invited', 'accepted') AND ra.due_at < NOW()) AS overdue,
+				   COUNT(*) FILTER (WHERE ra.status = 'completed' AND ra.completed_at > ra.due_at) AS late,
+				   (AVG(EXTRACT(EPOCH FROM ra.completed_at - ra.created_at)) FILTER (WHERE ra.status = 'completed') / 86400)::float8 AS turnaround
+			FROM review_assignments ra
+			JOIN papers p ON p.id = ra.paper_id
+			WHERE p.tenant_id = $1 AND p.deleted_at IS NULL AND `+inScope+`
+			GROUP BY ra.reviewer_id
+		),
+		r AS (
+			SELECT r.reviewer_id,
+				   COUNT(*) AS completed,
+				   AVG(r.rating)::float8 AS avg_rating,
+				   COUNT(*) FILTER (WHERE p.status IN ('approved', 'published', 'rejected')) AS decided,
+				   COUNT(*) FILTER (WHERE (p.status IN ('approved', 'published') AND r.recommendation IN ('accept', 'minor_revision'))
+									   OR (p.status = 'rejected' AND r.recommendation IN ('reject', 'major_revision'))) AS agreed,
+				   AVG(r.rating - others.avg_rating)::float8 AS deviation
+			FROM reviews r
+			JOIN papers p ON p.id = r.paper_id
+			LEFT JOIN LATERAL (
+				SELECT AVG(o.rating) AS avg_rating FROM reviews o
+				WHERE o.paper_id = r.paper_id AND o.reviewer_id != r.reviewer_id AND o.status != 'draft'
+			) others ON TRUE
+			WHERE r.tenant_id = $1 AND r.status != 'draft' AND p.deleted_at IS NULL AND `+inScope+`
+			GROUP BY r.reviewer_id
+		)
+		SELECT u.id, u.name, u.email, u.role,
+			   COALESCE(r.completed, 0), a.turnaround, COALESCE(a.invitations, 0), COALESCE(a.declined, 0),
+			   COALESCE(a.overdue, 0), COALESCE(a.late, 0), COALESCE(a.open_load, 0),
+			   r.avg_rating, COALESCE(r.decided, 0), COALESCE(r.agreed, 0), r.deviation
+		FROM users u
+		LEFT JOIN a ON a.reviewer_id = u.id
+		LEFT JOIN r ON r.reviewer_id = u.id
+		WHERE u.tenant_id = $1 AND (a.reviewer_id IS NOT NULL OR r.reviewer_id IS NOT NULL)
+		ORDER BY COALESCE(a.open_load, 0) DESC, u.name ASC
+	`, tenantID(c), scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviewer statistics"})
+		return
+	}
+	defer rows.Close()
+
+	stats := []models.ReviewerStats{}
+	for rows.Next() {
+		var st models.ReviewerStats
+		var agreed int
+		err := rows.Scan(&st.UserID, &st.Name, &st.Email, &st.Role,
+			&st.ReviewsCompleted, &st.AverageTurnaroundDays, &st.Invitations, &st.Declined,
+			&st.Overdue, &st.CompletedLate, &st.OpenLoad,
+			&st.AverageRating, &st.DecidedReviews, &agreed, &st.RatingDeviation)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan reviewer statistics"})
+			return
+		}
+		st.DeclineRate = ratio(st.Declined, st.Invitations)
+		st.DecisionAgreement = ratio(agreed, st.DecidedReviews)
+		stats = append(stats, st)
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// ratio returns n/total, or nil when there is nothing to divide by
+func ratio(n, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	r := float64(n) / float64(total)
+	return &r
+}
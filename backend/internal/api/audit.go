@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rpms-backend/internal/audit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// auditFilter reads the audit log filters of the query string: actor, action (a trailing
+// dot matches all actions of an entity), entity_type, entity_id, and from/to as RFC 3339
+// times or dates
+func auditFilter(c *gin.Context) (audit.Filter, error) {
+	f := audit.Filter{
+		TenantID:   tenantID(c),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+	}
+	if raw := c.Query("actor"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return f, fmt.Errorf("invalid actor %q", raw)
+		}
+		f.ActorID = &id
+	}
+	for _, bound := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		raw := c.Query(bound.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", raw); err != nil {
+				return f, fmt.Errorf("invalid %s %q, expected an RFC 3339 time or a date", bound.name, raw)
+			}
+			// A date as the upper bound includes that whole day
+			if bound.name == "to" {
+				t = t.AddDate(0, 0, 1)
+			}
+		}
+		*bound.dst = &t
+	}
+	return f, nil
+}
+
+// GetAuditLog lists audit entries, newest first
+func (s *Server) GetAuditLog(c *gin.Context) {
+	filter, err := auditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	ctx := c.Request.Context()
+	total, err := s.auditLog.Count(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	entries := []audit.Entry{}
+	err = s.auditLog.Each(ctx, filter, limit, (page-1)*limit, func(e *audit.Entry) error {
+		entries = append(entries, *e)
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "total": total, "page": page, "limit": limit})
+}
+
+// ExportAuditLog streams every matching audit entry as CSV
+func (s *Server) ExportAuditLog(c *gin.Context) {
+	filter, err := auditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-log-%s.csv"`, time.Now().Format("20060102-150405")))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"id", "created_at", "actor_id", "actor_email", "actor_role", "action", "entity_type", "entity_id",
+		"method", "path", "status", "changes", "ip_address", "user_agent", "prev_hash", "hash",
+	})
+	err = s.auditLog.Each(c.Request.Context(), filter, 0, 0, func(e *audit.Entry) error {
+		actor := ""
+		if e.ActorID != nil {
+			actor = e.ActorID.String()
+		}
+		changes, _ := json.Marshal(e.Changes)
+		return w.Write([]string{
+			strconv.FormatInt(e.ID, 10), e.CreatedAt.UTC().Format(time.RFC3339Nano), actor, e.ActorEmail, e.ActorRole,
+			e.Action, e.EntityType, e.EntityID, e.Method, e.Path, strconv.Itoa(e.Status), string(changes),
+			e.IPAddress, e.UserAgent, e.PrevHash, e.Hash,
+		})
+	})
+	w.Flush()
+	if err != nil {
+		// The header is already sent, so the failure can only be reported in the body
+		fmt.Fprintf(c.Writer, "\nexport failed: %v\n", err)
+	}
+}
+
+// VerifyAuditLog recomputes the tenant's hash chain and reports the first entry that does not match
+func (s *Server) VerifyAuditLog(c *gin.Context) {
+	result, err := s.auditLog.Verify(c.Request.Context(), tenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
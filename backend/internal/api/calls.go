@@ -0,0 +1,481 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const callColumns = `id, title, COALESCE(description, ''), type, COALESCE(eligible_categories, '{}'), COALESCE(eligible_ranks, '{}'),
+	COALESCE(required_fields, '{}'), COALESCE(rubric, '[]'), opens_at, closes_at, coordinator_id, created_at, updated_at`
+
+func scanCall(row pgx.Row, call *models.Call) error {
+	return row.Scan(
+		&call.ID, &call.Title, &call.Description, &call.Type, &call.EligibleCategories, &call.EligibleRanks,
+		&call.RequiredFields, &call.Rubric, &call.OpensAt, &call.ClosesAt, &call.CoordinatorID, &call.CreatedAt, &call.UpdatedAt,
+	)
+}
+
+func (s *Server) getCall(ctx context.Context, id uuid.UUID) (*models.Call, error) {
+	var call models.Call
+	err := scanCall(s.db.Pool.QueryRow(ctx, "SELECT "+callColumns+" FROM calls WHERE id = $1", id), &call)
+	if err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// validateCallRequest checks the parts of a call definition that binding tags cannot express
+func validateCallRequest(opensAt, closesAt time.Time, requiredFields []string) string {
+	if !closesAt.After(opensAt) {
+		return "closes_at must be after opens_at"
+	}
+	for _, field := range requiredFields {
+		if !models.IsValidCallField(field) {
+			return fmt.Sprintf("Unknown required field: %s", field)
+		}
+	}
+	return ""
+}
+
+// GetCalls lists calls, optionally filtered by type and by state (upcoming, open, closed)
+func (s *Server) GetCalls(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := "SELECT " + callColumns + " FROM calls WHERE tenant_id = $1"
+	args := []interface{}{tenantID(c)}
+
+	if callType := c.Query("type"); callType != "" {
+		args = append(args, callType)
+		query += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+
+	switch c.Query("state") {
+	case "upcoming":
+		query += " AND opens_at > NOW()"
+	case "open":
+		query += " AND opens_at <= NOW() AND closes_at > NOW()"
+	case "closed":
+		query += " AND closes_at <= NOW()"
+	}
+
+	query += " ORDER BY closes_at DESC"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calls"})
+		return
+	}
+	defer rows.Close()
+
+	calls := []models.Call{}
+	for rows.Next() {
+		var call models.Call
+		if err := scanCall(rows, &call); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan call"})
+			return
+		}
+		calls = append(calls, call)
+	}
+
+	c.JSON(http.StatusOK, calls)
+}
+
+func (s *Server) GetCall(c *gin.Context) {
+	callID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid call ID"})
+		return
+	}
+
+	call, err := s.getCall(c.Request.Context(), callID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Call not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"call": call, "state": call.State(time.Now())})
+}
+
+func (s *Server) CreateCall(c *gin.Context) {
+	var req models.CreateCallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if msg := validateCallRequest(req.OpensAt, req.ClosesAt, req.RequiredFields); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	coordinatorID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Calls without their own rubric use the institution's default rubric
+	if req.Rubric == nil {
+		req.Rubric = currentTenant(c).Config.Rubric
+	}
+	if req.Rubric == nil {
+		req.Rubric = []models.RubricCriterion{}
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		INSERT INTO calls (title, description, type, eligible_categories, eligible_ranks, required_fields, rubric, opens_at, closes_at, coordinator_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + callColumns
+
+	var call models.Call
+	err = scanCall(s.db.Pool.QueryRow(ctx, query,
+		req.Title, req.Description, req.Type, req.EligibleCategories, req.EligibleRanks, req.RequiredFields,
+		req.Rubric, req.OpensAt, req.ClosesAt, coordinatorID,
+	), &call)
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create call"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, call)
+}
+
+// requireCallOwner responds with 404 or 403 unless the call exists and was created by the
+// caller; those who manage the institution may change any call
+func (s *Server) requireCallOwner(c *gin.Context, callID uuid.UUID) bool {
+	var coordinatorID uuid.UUID
+	err := s.db.Pool.QueryRow(c.Request.Context(), "SELECT coordinator_id FROM calls WHERE id = $1", callID).Scan(&coordinatorID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Call not found"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch call"})
+		return false
+	}
+	if coordinatorID.String() != c.GetString("user_id") && !s.can(c, rbac.TenantManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the coordinator who created this call can change it"})
+		return false
+	}
+	return true
+}
+
+func (s *Server) UpdateCall(c *gin.Context) {
+	callID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid call ID"})
+		return
+	}
+
+	var req models.UpdateCallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if msg := validateCallRequest(req.OpensAt, req.ClosesAt, req.RequiredFields); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if req.Rubric == nil {
+		req.Rubric = []models.RubricCriterion{}
+	}
+
+	if !s.requireCallOwner(c, callID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		UPDATE calls
+		SET title = $1, description = $2, type = $3, eligible_categories = $4, eligible_ranks = $5,
+			required_fields = $6, rubric = $7, opens_at = $8, closes_at = $9, updated_at = NOW()
+		WHERE id = $10
+		RETURNING ` + callColumns
+
+	var call models.Call
+	err = scanCall(s.db.Pool.QueryRow(ctx, query,
+		req.Title, req.Description, req.Type, req.EligibleCategories, req.EligibleRanks, req.RequiredFields,
+		req.Rubric, req.OpensAt, req.ClosesAt, callID,
+	), &call)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Call not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update call"})
+		return
+	}
+
+	c.JSON(http.StatusOK, call)
+}
+
+func (s *Server) DeleteCall(c *gin.Context) {
+	callID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid call ID"})
+		return
+	}
+
+	if !s.requireCallOwner(c, callID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	result, err := s.db.Pool.Exec(ctx, "DELETE FROM calls WHERE id = $1", callID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete call"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Call not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Call deleted successfully"})
+}
+
+// SubmitToCall creates a paper submitted against a call, enforcing its window, eligibility and required fields
+func (s *Server) SubmitToCall(c *gin.Context) {
+	callID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid call ID"})
+		return
+	}
+
+	var req models.CreatePaperRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	authorID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	call, err := s.getCall(ctx, callID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Call not found"})
+		return
+	}
+
+	now := time.Now()
+	if call.IsUpcoming(now) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This call is not open for submissions yet"})
+		return
+	}
+	if call.IsClosed(now) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This call has closed"})
+		return
+	}
+
+	var authorCategory, academicRank string
+	err = s.db.Pool.QueryRow(ctx,
+		"SELECT COALESCE(author_category, ''), COALESCE(academic_rank, '') FROM users WHERE id = $1",
+		authorID).Scan(&authorCategory, &academicRank)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !call.IsEligible(authorCategory, academicRank) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not eligible to submit to this call"})
+		return
+	}
+
+	if missing := call.MissingFields(&req); len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required fields", "missing_fields": missing})
+		return
+	}
+
+	paper := models.Paper{
+		Title:                   req.Title,
+		Abstract:                req.Abstract,
+		Content:                 req.Content,
+		FileUrl:                 req.FileUrl,
+		AuthorID:                authorID,
+		Status:                  "submitted",
+		Type:                    req.Type,
+		PublicationTitleAmharic: req.PublicationTitleAmharic,
+		PublicationISCEDBand:    req.PublicationISCEDBand,
+		PublicationType:         req.PublicationType,
+		JournalType:             req.JournalType,
+		JournalName:             req.JournalName,
+		Keywords:                models.NormalizeKeywords(req.Keywords),
+		CallID:                  &call.ID,
+		UnitID:                  req.UnitID,
+	}
+
+	if paper.Type == "" {
+		paper.Type = "Research Paper" // Default
+	}
+
+	if err := s.insertPaper(ctx, &paper); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create paper"})
+		return
+	}
+
+	go s.notifyEditors(fmt.Sprintf("New submission to call '%s': %s", call.Title, paper.Title), paper.ID)
+
+	c.JSON(http.StatusCreated, paper)
+}
+
+// GetCallSubmissions returns the editorial queue of a call, optionally filtered by status
+func (s *Server) GetCallSubmissions(c *gin.Context) {
+	callID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid call ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		SELECT p.id, p.title, p.status, COALESCE(p.type, ''), p.author_id,
+			   COALESCE(u.name, 'Unknown'), COALESCE(u.email, ''),
+			   COUNT(r.id), AVG(r.rating)::float8, p.created_at
+		FROM papers p
+		LEFT JOIN users u ON p.author_id = u.id
+		LEFT JOIN reviews r ON r.paper_id = p.id AND r.status != 'draft'
+		WHERE p.call_id = $1 AND p.deleted_at IS NULL
+	`
+	args := []interface{}{callID}
+
+	if status := c.Query("status"); status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(" AND p.status = $%d", len(args))
+	}
+	// Staff limited to units only see the submissions within them
+	scope, err := s.staffUnitScope(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+		return
+	}
+	if scope != nil {
+		args = append(args, scope)
+		query += " AND (p.unit_id IS NULL OR " + inUnitSubtree("p.unit_id", len(args)) + ")"
+	}
+
+	query += `
+		GROUP BY p.id, u.name, u.email
+		ORDER BY p.created_at ASC
+	`
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch submissions"})
+		return
+	}
+	defer rows.Close()
+
+	submissions := []models.CallSubmission{}
+	for rows.Next() {
+		var sub models.CallSubmission
+		err := rows.Scan(
+			&sub.ID, &sub.Title, &sub.Status, &sub.Type, &sub.AuthorID,
+			&sub.AuthorName, &sub.AuthorEmail, &sub.ReviewCount, &sub.AverageRating, &sub.CreatedAt,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan submission"})
+			return
+		}
+		submissions = append(submissions, sub)
+	}
+
+	c.JSON(http.StatusOK, submissions)
+}
+
+// GetCallStats returns submission and review statistics for a call
+func (s *Server) GetCallStats(c *gin.Context) {
+	callID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid call ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	call, err := s.getCall(ctx, callID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Call not found"})
+		return
+	}
+
+	stats := models.CallStats{
+		CallID:   call.ID,
+		State:    call.State(time.Now()),
+		ByStatus: map[string]int{},
+	}
+
+	// Optionally restrict to a unit and everything below it
+	unitFilter := ""
+	args := []interface{}{callID}
+	if raw := c.Query("unit_id"); raw != "" {
+		unitID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+			return
+		}
+		args = append(args, []uuid.UUID{unitID})
+		unitFilter = " AND " + inUnitSubtree("p.unit_id", len(args))
+	}
+	// Staff limited to units only count the submissions within them
+	scope, err := s.staffUnitScope(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+		return
+	}
+	if scope != nil {
+		args = append(args, scope)
+		unitFilter += " AND (p.unit_id IS NULL OR " + inUnitSubtree("p.unit_id", len(args)) + ")"
+	}
+
+	rows, err := s.db.Pool.Query(ctx, "SELECT p.status, COUNT(*) FROM papers p WHERE p.call_id = $1 AND p.deleted_at IS NULL"+unitFilter+" GROUP BY p.status", args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch call statistics"})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan call statistics"})
+			return
+		}
+		stats.ByStatus[status] = count
+		stats.Submissions += count
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT p.id) FILTER (WHERE r.id IS NOT NULL),
+			   COUNT(DISTINCT p.id) FILTER (WHERE r.id IS NULL),
+			   AVG(r.rating)::float8,
+			   COUNT(DISTINCT p.author_id)
+		FROM papers p
+		LEFT JOIN reviews r ON r.paper_id = p.id AND r.status != 'draft'
+		WHERE p.call_id = $1 AND p.deleted_at IS NULL`+unitFilter, args...).Scan(&stats.Reviewed, &stats.AwaitingReview, &stats.AverageRating, &stats.DistinctAuthors)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch call statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -10,6 +10,7 @@ import (
 	"rpms-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 func logDebug(format string, a ...interface{}) {
@@ -68,7 +69,8 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 			attachment_url, attachment_name, attachment_type, attachment_size,
 			reply_to_message_id, is_forwarded, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		SELECT $1, id, $3, $4, $5, $6, $7, $8, $9, $10
+		FROM users WHERE id = $2 AND tenant_id = $11
 		RETURNING id, sender_id, receiver_id, content, 
 			attachment_url, attachment_name, attachment_type, attachment_size,
 			reply_to_message_id, is_forwarded, is_read, created_at
@@ -88,12 +90,17 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 		req.ReplyToMessageID,
 		isForwarded,
 		time.Now(),
+		tenantID(c),
 	).Scan(
 		&msg.ID, &msg.SenderID, &msg.ReceiverID, &msg.Content,
 		&msg.AttachmentURL, &msg.AttachmentName, &msg.AttachmentType, &msg.AttachmentSize,
 		&msg.ReplyToMessageID, &msg.IsForwarded, &msg.IsRead, &msg.CreatedAt,
 	)
 
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Receiver not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
 		return
@@ -190,14 +197,14 @@ func (h *ChatHandler) GetContacts(c *gin.Context) {
 	query := `
 		SELECT id, name, email, role, avatar
 		FROM users
-		WHERE role IN ` + roleFilter + ` AND id != $1
+		WHERE role IN ` + roleFilter + ` AND id != $1 AND tenant_id = $2
 		ORDER BY name ASC
 	`
 
 	logDebug("Executing query: %s", query)
 	logDebug("With userID parameter: %s", userID)
 
-	rows, err := h.db.Query(c.Request.Context(), query, userID)
+	rows, err := h.db.Query(c.Request.Context(), query, userID, tenantID(c))
 	if err != nil {
 		logDebug("Query failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contacts"})
@@ -0,0 +1,439 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// discussionMembers selects the users who may take part in the discussion of the paper bound to $1:
+// the staff assigned to it and those of its tenant who make publication decisions. The parameters
+// at permissionArg and builtInArg take rbac.PaperPublish and rbac.BuiltInRolesWith(rbac.PaperPublish).
+func discussionMembers(permissionArg, builtInArg int) string {
+	return `
+	SELECT user_id FROM paper_staff WHERE paper_id = $1
+	UNION
+	SELECT u.id FROM users u
+	WHERE u.tenant_id = (SELECT tenant_id FROM papers WHERE id = $1) AND ` + rbac.HolderCondition(permissionArg, builtInArg)
+}
+
+// canAccessDiscussion reports whether the caller makes publication decisions or is assigned to the paper
+func (s *Server) canAccessDiscussion(c *gin.Context, paperID uuid.UUID) (bool, error) {
+	if s.can(c, rbac.PaperPublish) {
+		return true, nil
+	}
+	var assigned bool
+	err := s.db.Pool.QueryRow(c.Request.Context(),
+		"SELECT EXISTS (SELECT 1 FROM paper_staff WHERE paper_id = $1 AND user_id = $2)",
+		paperID, c.GetString("user_id")).Scan(&assigned)
+	return assigned, err
+}
+
+// requireDiscussionAccess responds with 403 unless the caller may see the paper's discussion
+func (s *Server) requireDiscussionAccess(c *gin.Context, paperID uuid.UUID) bool {
+	allowed, err := s.canAccessDiscussion(c, paperID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check paper assignment"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only editorial staff assigned to this paper can access its discussion"})
+		return false
+	}
+	return true
+}
+
+func (s *Server) paperStaff(ctx context.Context, paperID uuid.UUID) ([]models.PaperStaff, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT ps.paper_id, ps.user_id, COALESCE(u.name, 'Unknown'), COALESCE(u.email, ''), COALESCE(u.role, ''), ps.assigned_by, ps.created_at
+		FROM paper_staff ps
+		LEFT JOIN users u ON u.id = ps.user_id
+		WHERE ps.paper_id = $1
+		ORDER BY ps.created_at ASC
+	`, paperID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	staff := []models.PaperStaff{}
+	for rows.Next() {
+		var ps models.PaperStaff
+		if err := rows.Scan(&ps.PaperID, &ps.UserID, &ps.Name, &ps.Email, &ps.Role, &ps.AssignedBy, &ps.CreatedAt); err != nil {
+			return nil, err
+		}
+		staff = append(staff, ps)
+	}
+	return staff, rows.Err()
+}
+
+// assignPaperStaff adds a member of the paper's tenant who may take part in editorial
+// discussions to its staff, returning false when the user holds no role allowing that or
+// wrote or contributed to the paper, whose discussion is kept from its authors
+func (s *Server) assignPaperStaff(ctx context.Context, paperID, userID uuid.UUID, assignedBy *uuid.UUID) (bool, error) {
+	tag, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO paper_staff (paper_id, user_id, assigned_by)
+		SELECT $1, u.id, $3 FROM users u
+		WHERE u.id = $2 AND `+rbac.HolderCondition(4, 5)+`
+		  AND u.tenant_id = (SELECT tenant_id FROM papers WHERE id = $1)
+		  AND NOT EXISTS (SELECT 1 FROM (`+authorships+`) a WHERE a.paper_id = $1 AND a.user_id = u.id)
+		ON CONFLICT (paper_id, user_id) DO NOTHING
+	`, paperID, userID, assignedBy, rbac.PaperDiscuss, rbac.BuiltInRolesWith(rbac.PaperDiscuss))
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() > 0 {
+		return true, nil
+	}
+	var assigned bool
+	err = s.db.Pool.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM paper_staff WHERE paper_id = $1 AND user_id = $2)", paperID, userID).Scan(&assigned)
+	return assigned, err
+}
+
+// GetPaperStaff lists the editorial staff assigned to a paper
+func (s *Server) GetPaperStaff(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	staff, err := s.paperStaff(c.Request.Context(), paperID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper staff"})
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// AssignPaperStaff assigns an editor or coordinator to a paper, giving them access to its discussion
+func (s *Server) AssignPaperStaff(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.AssignPaperStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	assignedBy, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	assigned, err := s.assignPaperStaff(ctx, paperID, req.UserID, &assignedBy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign staff"})
+		return
+	}
+	if !assigned {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only editors and coordinators of this institution who are not among the paper's authors can be assigned"})
+		return
+	}
+
+	go func() {
+		var title string
+		s.db.Pool.QueryRow(context.Background(), "SELECT title FROM papers WHERE id = $1", paperID).Scan(&title)
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+			req.UserID, fmt.Sprintf("You have been assigned to the paper '%s'", title), paperID)
+	}()
+
+	staff, err := s.paperStaff(ctx, paperID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper staff"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, staff)
+}
+
+// RemovePaperStaff unassigns a staff member from a paper
+func (s *Server) RemovePaperStaff(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	tag, err := s.db.Pool.Exec(c.Request.Context(),
+		"DELETE FROM paper_staff WHERE paper_id = $1 AND user_id = $2", paperID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove staff"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff member not assigned to this paper"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Staff member removed"})
+}
+
+const discussionColumns = `m.id, m.paper_id, m.author_id, COALESCE(u.name, 'Unknown'), m.kind, m.body, m.mentions,
+	m.attachment_url, m.attachment_name, m.attachment_type, m.attachment_size, m.pinned, m.created_at`
+
+func scanDiscussionMessage(row pgx.Row, m *models.DiscussionMessage) error {
+	var authorID *uuid.UUID
+	err := row.Scan(&m.ID, &m.PaperID, &authorID, &m.AuthorName, &m.Kind, &m.Body, &m.Mentions,
+		&m.AttachmentURL, &m.AttachmentName, &m.AttachmentType, &m.AttachmentSize, &m.Pinned, &m.CreatedAt)
+	if authorID != nil {
+		m.AuthorID = *authorID
+	}
+	return err
+}
+
+// GetPaperDiscussion returns a paper's internal discussion thread, oldest first, with pinned messages
+// (including every decision) listed separately
+func (s *Server) GetPaperDiscussion(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+	if !s.requireDiscussionAccess(c, paperID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+discussionColumns+`
+		FROM paper_discussion_messages m
+		LEFT JOIN users u ON u.id = m.author_id
+		WHERE m.paper_id = $1
+		ORDER BY m.created_at ASC
+	`, paperID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch discussion"})
+		return
+	}
+	defer rows.Close()
+
+	discussion := models.PaperDiscussion{Pinned: []models.DiscussionMessage{}, Messages: []models.DiscussionMessage{}}
+	for rows.Next() {
+		var m models.DiscussionMessage
+		if err := scanDiscussionMessage(rows, &m); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan discussion message"})
+			return
+		}
+		if m.Pinned {
+			discussion.Pinned = append(discussion.Pinned, m)
+		}
+		discussion.Messages = append(discussion.Messages, m)
+	}
+	rows.Close()
+
+	discussion.Staff, err = s.paperStaff(ctx, paperID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper staff"})
+		return
+	}
+
+	c.JSON(http.StatusOK, discussion)
+}
+
+// PostDiscussionMessage adds a message to a paper's discussion and notifies anyone mentioned in it
+func (s *Server) PostDiscussionMessage(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.PostDiscussionMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" && req.AttachmentURL == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message must have a body or an attachment"})
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = models.DiscussionComment
+	}
+
+	authorID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if !s.requireDiscussionAccess(c, paperID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Only people who can read the thread can be mentioned in it
+	mentions := []uuid.UUID{}
+	if len(req.Mentions) > 0 {
+		rows, err := s.db.Pool.Query(ctx, `SELECT user_id FROM (`+discussionMembers(3, 4)+`) m WHERE user_id = ANY($2)`,
+			paperID, req.Mentions, rbac.PaperPublish, rbac.BuiltInRolesWith(rbac.PaperPublish))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve mentions"})
+			return
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err == nil {
+				mentions = append(mentions, id)
+			}
+		}
+		rows.Close()
+		if len(mentions) != len(uniqueIDs(req.Mentions)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only staff assigned to this paper can be mentioned"})
+			return
+		}
+	}
+
+	m, err := s.insertDiscussionMessage(ctx, paperID, authorID, req.Kind, req.Body, mentions, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post message"})
+		return
+	}
+
+	go func() {
+		var title string
+		s.db.Pool.QueryRow(context.Background(), "SELECT title FROM papers WHERE id = $1", paperID).Scan(&title)
+		message := fmt.Sprintf("%s mentioned you in the discussion of '%s'", m.AuthorName, title)
+		for _, userID := range mentions {
+			if userID == authorID {
+				continue
+			}
+			s.db.Pool.Exec(context.Background(),
+				"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+				userID, message, paperID)
+		}
+	}()
+
+	c.JSON(http.StatusCreated, m)
+}
+
+// insertDiscussionMessage stores a discussion message; decisions are always pinned
+func (s *Server) insertDiscussionMessage(ctx context.Context, paperID, authorID uuid.UUID, kind, body string, mentions []uuid.UUID, attachment *models.PostDiscussionMessageRequest) (*models.DiscussionMessage, error) {
+	if mentions == nil {
+		mentions = []uuid.UUID{}
+	}
+	if attachment == nil {
+		attachment = &models.PostDiscussionMessageRequest{}
+	}
+
+	var m models.DiscussionMessage
+	err := scanDiscussionMessage(s.db.Pool.QueryRow(ctx, `
+		WITH m AS (
+			INSERT INTO paper_discussion_messages (paper_id, author_id, kind, body, mentions,
+				attachment_url, attachment_name, attachment_type, attachment_size, pinned)
+			VALUES ($1, $2, $3::varchar, $4, $5, $6, $7, $8, $9, $3::varchar = 'decision')
+			RETURNING *
+		)
+		SELECT `+discussionColumns+`
+		FROM m
+		LEFT JOIN users u ON u.id = m.author_id
+	`, paperID, authorID, kind, body, mentions,
+		attachment.AttachmentURL, attachment.AttachmentName, attachment.AttachmentType, attachment.AttachmentSize), &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// postDecision records an editorial decision on a paper as a pinned message in its discussion
+func (s *Server) postDecision(ctx context.Context, paperID uuid.UUID, userID, body string) {
+	authorID, err := uuid.Parse(userID)
+	if err != nil {
+		return
+	}
+	s.insertDiscussionMessage(ctx, paperID, authorID, models.DiscussionDecision, body, nil, nil)
+}
+
+// PinDiscussionMessage pins or unpins a message in a paper's discussion
+func (s *Server) PinDiscussionMessage(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+	messageID, err := uuid.Parse(c.Param("messageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return
+	}
+
+	var req models.PinDiscussionMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !s.requireDiscussionAccess(c, paperID) {
+		return
+	}
+
+	var m models.DiscussionMessage
+	err = scanDiscussionMessage(s.db.Pool.QueryRow(c.Request.Context(), `
+		WITH m AS (
+			UPDATE paper_discussion_messages SET pinned = $1
+			WHERE id = $2 AND paper_id = $3
+			RETURNING *
+		)
+		SELECT `+discussionColumns+`
+		FROM m
+		LEFT JOIN users u ON u.id = m.author_id
+	`, req.Pinned, messageID, paperID), &m)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update message"})
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -2,18 +2,24 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"rpms-backend/internal/audit"
 	"rpms-backend/internal/auth"
 	"rpms-backend/internal/config"
 	"rpms-backend/internal/database"
 	"rpms-backend/internal/email"
 	"rpms-backend/internal/models"
+	"rpms-backend/internal/oidc"
+	"rpms-backend/internal/ratelimit"
+	"rpms-backend/internal/rbac"
 	"rpms-backend/internal/supabase"
+	"rpms-backend/internal/tenant"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -25,16 +31,52 @@ type Server struct {
 	jwtManager  *auth.JWTManager
 	config      *config.Config
 	emailSender *email.EmailSender
-	supabase    *supabase.Client
+	identity    auth.IdentityProvider
+	tokens      *auth.TokenStore
+	tenants     *tenant.DBResolver
+	permissions *rbac.DBResolver
+	// resetLimiter throttles password reset requests per email and per client IP
+	resetLimiter *ratelimit.Limiter
+	// mfaLimiter throttles second factor attempts per user
+	mfaLimiter    *ratelimit.Limiter
+	loginThrottle *auth.LoginThrottle
+	auditLog      *audit.Log
+	apiKeys       *auth.APIKeyStore
+	// sso signs staff in with the institution's OpenID provider, when one is configured
+	sso      *oidc.Client
+	ssoRoles oidc.RoleMapping
 }
 
 func NewServer(db *database.Database, cfg *config.Config) *Server {
+	sender := email.NewEmailSender(cfg)
+
+	var identity auth.IdentityProvider
+	switch cfg.Auth.Provider {
+	case "local":
+		identity = auth.NewLocalProvider(db, sender)
+	default:
+		identity = auth.NewSupabaseProvider(supabase.NewClient(cfg))
+	}
+
+	jwtManager := auth.NewJWTManager(cfg)
+	tenants := tenant.NewDBResolver(db)
 	return &Server{
 		db:          db,
-		jwtManager:  auth.NewJWTManager(cfg),
+		jwtManager:  jwtManager,
 		config:      cfg,
-		emailSender: email.NewEmailSender(cfg),
-		supabase:    supabase.NewClient(cfg),
+		emailSender: sender,
+		identity:    identity,
+		tokens:      auth.NewTokenStore(db, jwtManager),
+		tenants:     tenants,
+		permissions: rbac.NewDBResolver(db),
+
+		resetLimiter:  ratelimit.New(5, time.Hour),
+		mfaLimiter:    ratelimit.New(5, 5*time.Minute),
+		loginThrottle: auth.NewLoginThrottle(db),
+		auditLog:      audit.NewLog(db),
+		apiKeys:       auth.NewAPIKeyStore(db, tenants),
+		sso:           oidc.NewClient(ssoConfig(cfg), nil),
+		ssoRoles:      ssoRoleMapping(cfg),
 	}
 }
 
@@ -47,14 +89,7 @@ func (s *Server) Register(c *gin.Context) {
 		return
 	}
 
-	// Hash password (we might still want to store it locally later, or just rely on Supabase)
-	// hashedPassword, err := auth.HashPassword(req.Password)
-	// if err != nil {
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-	// 	return
-	// }
-
-	// Prepare user metadata for Supabase
+	// Profile fields are carried by the pending registration until the email is verified
 	metadata := map[string]interface{}{
 		"name":            req.Name,
 		"role":            "author",
@@ -66,19 +101,23 @@ func (s *Server) Register(c *gin.Context) {
 		"employment_type": req.EmploymentType,
 		"gender":          req.Gender,
 		"date_of_birth":   req.DateOfBirth,
+		"tenant_id":       tenantID(c).String(),
 	}
 
-	// Register with Supabase
-	_, err := s.supabase.SignUp(req.Email, req.Password, metadata)
+	err := s.identity.SignUp(c.Request.Context(), req.Email, req.Password, metadata)
+	if errors.Is(err, auth.ErrAlreadyRegistered) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to register with Supabase: %v", err)})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to register: %v", err)})
 		return
 	}
 
 	// We DO NOT insert into local DB yet. We wait for verification.
 
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Registration successful. Please check your email for the verification code from Supabase.",
+		"message": "Registration successful. Please check your email for the verification code.",
 		"email":   req.Email,
 	})
 }
@@ -90,31 +129,39 @@ func (s *Server) VerifyEmail(c *gin.Context) {
 		return
 	}
 
-	// Verify with Supabase
-	sbUser, err := s.supabase.Verify(req.Email, strings.TrimSpace(req.Code))
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("Supabase verification failed: %v", err)})
+	// Accounts an admin asked to confirm their email again are verified locally
+	if s.verifyExistingAccount(c, req.Email, req.Code) {
 		return
 	}
 
 	ctx := c.Request.Context()
+	ident, err := s.identity.Verify(ctx, req.Email, strings.TrimSpace(req.Code))
+	if errors.Is(err, auth.ErrInvalidCode) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired verification code"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Verification failed: %v", err)})
+		return
+	}
+
 	var user models.User
 
 	// Check if user exists in local DB
 	query := `
-		SELECT id, email, password_hash, name, role, avatar, bio, preferences, created_at, updated_at
+		SELECT id, email, password_hash, name, role, avatar, bio, preferences, created_at, updated_at, tenant_id
 		FROM users
 		WHERE email = $1
 	`
 
 	err = s.db.Pool.QueryRow(ctx, query, req.Email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt, &user.TenantID,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			// User not found, insert them now
-			meta := sbUser.UserMetadata
+			meta := ident.Metadata
 
 			// Helper to safely get string from metadata
 			getString := func(key string) string {
@@ -124,8 +171,8 @@ func (s *Server) VerifyEmail(c *gin.Context) {
 				return ""
 			}
 
-			user.ID = uuid.MustParse(sbUser.ID)
-			user.Email = sbUser.Email
+			user.ID = ident.ID
+			user.Email = ident.Email
 			user.Name = getString("name")
 			user.Role = getString("role")
 			if user.Role == "" {
@@ -136,6 +183,11 @@ func (s *Server) VerifyEmail(c *gin.Context) {
 			user.Preferences = map[string]interface{}{}
 			user.IsVerified = true
 			user.VerificationCode = ""
+			// The institution registered with, unless verifying on an institution's own hostname
+			user.TenantID = tenantID(c)
+			if id, err := uuid.Parse(getString("tenant_id")); err == nil && !c.GetBool("tenant_from_host") {
+				user.TenantID = id
+			}
 
 			// Extract other fields
 			user.AcademicYear = getString("academic_year")
@@ -150,20 +202,18 @@ func (s *Server) VerifyEmail(c *gin.Context) {
 			insertQuery := `
 				INSERT INTO users (
 					id, email, password_hash, name, role, avatar, bio, preferences, is_verified, verification_code,
-					academic_year, author_type, author_category, academic_rank, qualification, employment_type, gender, date_of_birth
+					academic_year, author_type, author_category, academic_rank, qualification, employment_type, gender, date_of_birth, tenant_id
 				)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 				RETURNING created_at, updated_at
 			`
 
-			// Note: password_hash is empty since we don't have it here.
-			// If we want to keep it, we'd need to pass it in metadata or just rely on Supabase.
-			// For now, we'll store an empty string or a placeholder.
-			user.PasswordHash = ""
+			// Empty for Supabase accounts until their first sign in, see Login
+			user.PasswordHash = ident.PasswordHash
 
 			err = s.db.Pool.QueryRow(ctx, insertQuery,
 				user.ID, user.Email, user.PasswordHash, user.Name, user.Role, user.Avatar, user.Bio, user.Preferences, user.IsVerified, user.VerificationCode,
-				user.AcademicYear, user.AuthorType, user.AuthorCategory, user.AcademicRank, user.Qualification, user.EmploymentType, user.Gender, user.DateOfBirth,
+				user.AcademicYear, user.AuthorType, user.AuthorCategory, user.AcademicRank, user.Qualification, user.EmploymentType, user.Gender, user.DateOfBirth, user.TenantID,
 			).Scan(&user.CreatedAt, &user.UpdatedAt)
 
 			if err != nil {
@@ -174,6 +224,9 @@ func (s *Server) VerifyEmail(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error checking user"})
 			return
 		}
+	} else if !accountInTenant(c, user.TenantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account belongs to another institution"})
+		return
 	} else {
 		// User exists, just update verification status
 		updateQuery := `
@@ -189,19 +242,7 @@ func (s *Server) VerifyEmail(c *gin.Context) {
 		user.IsVerified = true
 	}
 
-	// Generate JWT token
-	token, err := s.jwtManager.GenerateToken(&user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
-	}
-
-	response := models.LoginResponse{
-		User:  user,
-		Token: token,
-	}
-
-	c.JSON(http.StatusOK, response)
+	s.completeSignIn(c, &user)
 }
 
 func (s *Server) ResendVerificationCode(c *gin.Context) {
@@ -211,22 +252,29 @@ func (s *Server) ResendVerificationCode(c *gin.Context) {
 		return
 	}
 
-	// Resend with Supabase
-	err := s.supabase.Resend(req.Email)
+	if s.resendReverification(c, req.Email) {
+		return
+	}
+
+	err := s.identity.ResendVerification(c.Request.Context(), req.Email)
 	if err != nil {
+		if errors.Is(err, auth.ErrRateLimited) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait a few seconds before requesting another code."})
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidCode) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No pending registration for this email"})
+			return
+		}
 		if sbErr, ok := err.(*supabase.SupabaseError); ok {
-			if sbErr.StatusCode == 429 {
-				c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait a few seconds before requesting another code."})
-				return
-			}
 			c.JSON(sbErr.StatusCode, gin.H{"error": sbErr.Message})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to resend code via Supabase: %v", err)})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to resend code: %v", err)})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Verification code resent successfully via Supabase"})
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code resent successfully"})
 }
 
 func (s *Server) Login(c *gin.Context) {
@@ -236,28 +284,36 @@ func (s *Server) Login(c *gin.Context) {
 		return
 	}
 
-	// Authenticate with Supabase
-	fmt.Printf("Attempting Supabase SignIn for: %s\n", req.Email)
-	sbResp, err := s.supabase.SignIn(req.Email, req.Password)
-	if err != nil {
-		fmt.Printf("Supabase SignIn failed: %v\n", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials (Supabase)"})
+	if !s.allowLogin(c, req.Email) {
 		return
 	}
-	fmt.Printf("Supabase SignIn successful. User ID: %s\n", sbResp.User.ID)
 
 	ctx := c.Request.Context()
+	ident, err := s.identity.SignIn(ctx, req.Email, req.Password)
+	if errors.Is(err, auth.ErrInvalidCredentials) {
+		s.loginFailed(c, req.Email)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
 	var user models.User
+	var active, mustReverify bool
 
 	// Fetch user details from local DB
 	query := `
-		SELECT id, email, password_hash, name, role, avatar, bio, preferences, created_at, updated_at
+		SELECT id, email, password_hash, name, role, avatar, bio, preferences, created_at, updated_at, tenant_id,
+			   is_active, reverification_required
 		FROM users
-		WHERE email = $1
+		WHERE LOWER(email) = LOWER($1)
 	`
 
 	err = s.db.Pool.QueryRow(ctx, query, req.Email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt, &user.TenantID,
+		&active, &mustReverify,
 	)
 
 	if err != nil {
@@ -265,20 +321,29 @@ func (s *Server) Login(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found in local database"})
 		return
 	}
-
-	// Generate JWT token (local)
-	token, err := s.jwtManager.GenerateToken(&user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+	if !accountInTenant(c, user.TenantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account belongs to another institution"})
+		return
+	}
+	if !active {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been deactivated", "deactivated": true})
+		return
+	}
+	if mustReverify {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please confirm your email with the code we sent you", "verification_required": true})
 		return
 	}
 
-	response := models.LoginResponse{
-		User:  user,
-		Token: token,
+	// Accounts created through Supabase have no local hash, or a stale one after a reset there
+	if ident.PasswordHash != "" && ident.PasswordHash != user.PasswordHash &&
+		(user.PasswordHash == "" || !auth.CheckPassword(req.Password, user.PasswordHash)) {
+		if _, err := s.db.Pool.Exec(ctx, "UPDATE users SET password_hash = $1 WHERE id = $2", ident.PasswordHash, user.ID); err == nil {
+			user.PasswordHash = ident.PasswordHash
+		}
 	}
 
-	c.JSON(http.StatusOK, response)
+	s.loginSucceeded(c, &user)
+	s.completeSignIn(c, &user)
 }
 
 func (s *Server) GetProfile(c *gin.Context) {
@@ -288,13 +353,15 @@ func (s *Server) GetProfile(c *gin.Context) {
 	var user models.User
 
 	query := `
-		SELECT id, email, name, role, avatar, bio, preferences, created_at, updated_at
+		SELECT id, email, name, role, avatar, bio, preferences, created_at, updated_at, COALESCE(orcid, ''),
+			   expertise_keywords, expertise_fields
 		FROM users
 		WHERE id = $1
 	`
 
 	err := s.db.Pool.QueryRow(ctx, query, userID).Scan(
-		&user.ID, &user.Email, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Email, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt, &user.ORCID,
+		&user.ExpertiseKeywords, &user.ExpertiseFields,
 	)
 
 	if err != nil {
@@ -319,17 +386,29 @@ func (s *Server) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	req.ORCID = strings.ToUpper(strings.TrimSpace(req.ORCID))
+	if req.ORCID != "" && !models.ValidORCID(req.ORCID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ORCID iD, expected the form 0000-0000-0000-0000"})
+		return
+	}
+
 	ctx := c.Request.Context()
 	query := `
 		UPDATE users
-		SET name = $1, avatar = $2, bio = $3, preferences = $4, updated_at = NOW()
-		WHERE id = $5
-		RETURNING id, email, name, role, avatar, bio, preferences, created_at, updated_at
+		SET name = $1, avatar = $2, bio = $3, preferences = $4, orcid = NULLIF($5, ''),
+			expertise_keywords = COALESCE($6::text[], expertise_keywords),
+			expertise_fields = COALESCE($7::text[], expertise_fields),
+			updated_at = NOW()
+		WHERE id = $8
+		RETURNING id, email, name, role, avatar, bio, preferences, created_at, updated_at, COALESCE(orcid, ''),
+				  expertise_keywords, expertise_fields
 	`
 
 	var user models.User
-	err = s.db.Pool.QueryRow(ctx, query, req.Name, req.Avatar, req.Bio, req.Preferences, id).Scan(
-		&user.ID, &user.Email, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt,
+	err = s.db.Pool.QueryRow(ctx, query, req.Name, req.Avatar, req.Bio, req.Preferences, req.ORCID,
+		models.NormalizeKeywords(req.ExpertiseKeywords), req.ExpertiseFields, id).Scan(
+		&user.ID, &user.Email, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt, &user.ORCID,
+		&user.ExpertiseKeywords, &user.ExpertiseFields,
 	)
 
 	if err != nil {
@@ -357,19 +436,29 @@ func (s *Server) ChangePassword(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	// Get current password hash
-	var currentHash string
-	err = s.db.Pool.QueryRow(ctx, "SELECT password_hash FROM users WHERE id = $1", id).Scan(&currentHash)
+	var email, currentHash string
+	err = s.db.Pool.QueryRow(ctx, "SELECT email, COALESCE(password_hash, '') FROM users WHERE id = $1", id).Scan(&email, &currentHash)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	// Verify old password
-	if !auth.CheckPassword(req.OldPassword, currentHash) {
+	// Verify old password, asking the identity provider when we never stored a hash
+	if currentHash == "" {
+		if _, err := s.identity.SignIn(ctx, email, req.OldPassword); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid old password"})
+			return
+		}
+	} else if !auth.CheckPassword(req.OldPassword, currentHash) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid old password"})
 		return
 	}
 
+	if err := s.identity.UpdatePassword(ctx, id, req.NewPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
 	// Hash new password
 	newHash, err := auth.HashPassword(req.NewPassword)
 	if err != nil {
@@ -396,11 +485,32 @@ func (s *Server) DeleteAccount(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	_, err = s.db.Pool.Exec(ctx, "DELETE FROM users WHERE id = $1", id)
+	tx, err := s.db.BeginTx(ctx)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
 		return
 	}
+	defer tx.Rollback(ctx)
+
+	var address string
+	if err = tx.QueryRow(ctx, "DELETE FROM users WHERE id = $1 RETURNING email", id).Scan(&address); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	if _, err = tx.Exec(ctx, "DELETE FROM pending_registrations WHERE email = LOWER($1)", address); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	// The provider is told last, once the local delete can no longer fail
+	if err := s.identity.Delete(ctx, id); err != nil {
+		fmt.Printf("Failed to delete account from the identity provider: %v\n", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Account deleted, but failed to remove it from the identity provider"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Account deleted successfully"})
 }
@@ -410,7 +520,10 @@ func (s *Server) GetPapers(c *gin.Context) {
 	ctx := c.Request.Context()
 
 	query := `
-		SELECT p.id, p.title, COALESCE(p.abstract, ''), COALESCE(p.content, ''), COALESCE(p.file_url, ''), p.author_id, p.status, COALESCE(p.type, 'Research Paper'), p.created_at, p.updated_at,
+		SELECT p.id, p.title, COALESCE(p.abstract, ''), COALESCE(p.content, ''), COALESCE(p.file_url, ''), p.author_id, p.status, COALESCE(p.type, 'Research Paper'), p.created_at, p.updated_at, p.call_id,
+			   p.issue_id, COALESCE(p.page_start, 0), COALESCE(p.page_end, 0), COALESCE(p.issue_order, 0),
+			   p.withdrawn_at, COALESCE(p.withdrawal_reason, ''), p.retracted_at, COALESCE(p.retraction_notice, ''),
+			   p.publish_at, p.embargo_until, p.unit_id, p.keywords,
 			   COALESCE(p.institution_code, ''), COALESCE(p.publication_id, ''), COALESCE(p.publication_isced_band, ''), COALESCE(p.publication_title_amharic, ''),
 			   p.publication_date, COALESCE(p.publication_type, ''), COALESCE(p.journal_type, ''), COALESCE(p.journal_name, ''), COALESCE(p.indigenous_knowledge, false),
 			   COALESCE(p.fiscal_year, ''), COALESCE(p.allocated_budget, 0), COALESCE(p.external_budget, 0), COALESCE(p.nrf_fund, 0),
@@ -425,22 +538,54 @@ func (s *Server) GetPapers(c *gin.Context) {
 			   COALESCE(u.bio, ''), COALESCE(u.avatar, '')
 		FROM papers p
 		LEFT JOIN users u ON p.author_id = u.id
-		ORDER BY p.created_at DESC
+		WHERE p.deleted_at IS NULL AND p.tenant_id = $1
 	`
 
-	rows, err := s.db.Pool.Query(ctx, query)
+	// Authors only see their own papers and those already published; staff see everything within their units
+	args := []interface{}{tenantID(c)}
+	if !s.can(c, rbac.PaperReadAll) {
+		args = append(args, c.GetString("user_id"))
+		query += " AND (p.author_id = $2 OR p.status = 'published')"
+	}
+	scope, err := s.staffUnitScope(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+		return
+	}
+	if scope != nil {
+		args = append(args, scope)
+		query += " AND (p.unit_id IS NULL OR " + inUnitSubtree("p.unit_id", len(args)) + ")"
+	}
+	if unitID := c.Query("unit_id"); unitID != "" {
+		id, err := uuid.Parse(unitID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+			return
+		}
+		args = append(args, []uuid.UUID{id})
+		query += " AND " + inUnitSubtree("p.unit_id", len(args))
+	}
+	query += " ORDER BY p.created_at DESC"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch papers"})
 		return
 	}
 	defer rows.Close()
 
+	now := time.Now()
+	staff := s.can(c, rbac.PaperReadAll)
+
 	var papers []models.PaperWithAuthor
 	for rows.Next() {
 		var paper models.PaperWithAuthor
 		err := rows.Scan(
 			&paper.ID, &paper.Title, &paper.Abstract, &paper.Content, &paper.FileUrl, &paper.AuthorID,
-			&paper.Status, &paper.Type, &paper.CreatedAt, &paper.UpdatedAt,
+			&paper.Status, &paper.Type, &paper.CreatedAt, &paper.UpdatedAt, &paper.CallID,
+			&paper.IssueID, &paper.PageStart, &paper.PageEnd, &paper.IssueOrder,
+			&paper.WithdrawnAt, &paper.WithdrawalReason, &paper.RetractedAt, &paper.RetractionNotice,
+			&paper.PublishAt, &paper.EmbargoUntil, &paper.UnitID, &paper.Keywords,
 			&paper.InstitutionCode, &paper.PublicationID, &paper.PublicationISCEDBand, &paper.PublicationTitleAmharic,
 			&paper.PublicationDate, &paper.PublicationType, &paper.JournalType, &paper.JournalName, &paper.IndigenousKnowledge,
 			&paper.FiscalYear, &paper.AllocatedBudget, &paper.ExternalBudget, &paper.NRFFund,
@@ -457,6 +602,7 @@ func (s *Server) GetPapers(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan paper"})
 			return
 		}
+		paper.MaskEmbargoedManuscript(c.GetString("user_id"), staff, now)
 		papers = append(papers, paper)
 	}
 
@@ -490,6 +636,8 @@ func (s *Server) CreatePaper(c *gin.Context) {
 		PublicationType:         req.PublicationType,
 		JournalType:             req.JournalType,
 		JournalName:             req.JournalName,
+		Keywords:                models.NormalizeKeywords(req.Keywords),
+		UnitID:                  req.UnitID,
 	}
 
 	if paper.Type == "" {
@@ -497,116 +645,226 @@ func (s *Server) CreatePaper(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
+	if err := s.insertPaper(ctx, &paper); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create paper"})
+		return
+	}
+
+	// Create notifications for all editors
+	go s.notifyEditors("New paper submitted: "+paper.Title, paper.ID)
+
+	c.JSON(http.StatusCreated, paper)
+}
+
+// insertPaper stores a newly submitted paper and fills in the generated columns
+func (s *Server) insertPaper(ctx context.Context, paper *models.Paper) error {
 	query := `
 		INSERT INTO papers (
 			title, abstract, content, file_url, author_id, status, type,
 			publication_title_amharic, publication_isced_band, publication_type,
-			journal_type, journal_name
+			journal_type, journal_name, call_id, unit_id, keywords
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+				COALESCE($14, (SELECT unit_id FROM user_affiliations WHERE user_id = $5 AND is_primary)),
+				COALESCE($15::text[], '{}'))
 		RETURNING id, title, COALESCE(abstract, ''), COALESCE(content, ''), COALESCE(file_url, ''), author_id, status, type, created_at, updated_at,
 				  COALESCE(publication_title_amharic, ''), COALESCE(publication_isced_band, ''), COALESCE(publication_type, ''),
-				  COALESCE(journal_type, ''), COALESCE(journal_name, '')
+				  COALESCE(journal_type, ''), COALESCE(journal_name, ''), call_id, unit_id, keywords
 	`
 
-	err = s.db.Pool.QueryRow(ctx, query,
+	return s.db.Pool.QueryRow(ctx, query,
 		paper.Title, paper.Abstract, paper.Content, paper.FileUrl, paper.AuthorID, paper.Status, paper.Type,
 		paper.PublicationTitleAmharic, paper.PublicationISCEDBand, paper.PublicationType,
-		paper.JournalType, paper.JournalName,
+		paper.JournalType, paper.JournalName, paper.CallID, paper.UnitID, paper.Keywords,
 	).Scan(
 		&paper.ID, &paper.Title, &paper.Abstract, &paper.Content, &paper.FileUrl, &paper.AuthorID,
 		&paper.Status, &paper.Type, &paper.CreatedAt, &paper.UpdatedAt,
 		&paper.PublicationTitleAmharic, &paper.PublicationISCEDBand, &paper.PublicationType,
-		&paper.JournalType, &paper.JournalName,
+		&paper.JournalType, &paper.JournalName, &paper.CallID, &paper.UnitID, &paper.Keywords,
 	)
+}
 
+// notifyEditors sends a notification about a paper to every editor of its tenant
+func (s *Server) notifyEditors(message string, paperID uuid.UUID) {
+	rows, err := s.db.Pool.Query(context.Background(),
+		"SELECT id FROM users WHERE role = 'editor' AND tenant_id = (SELECT tenant_id FROM papers WHERE id = $1)", paperID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create paper"})
 		return
 	}
-
-	// Create notifications for all editors
-	go func() {
-		// Find all editors
-		rows, err := s.db.Pool.Query(context.Background(), "SELECT id FROM users WHERE role = 'editor'")
-		if err == nil {
-			defer rows.Close()
-			for rows.Next() {
-				var editorID uuid.UUID
-				if err := rows.Scan(&editorID); err == nil {
-					// Create notification
-					s.db.Pool.Exec(context.Background(),
-						"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
-						editorID, "New paper submitted: "+paper.Title, paper.ID)
-				}
-			}
+	defer rows.Close()
+	for rows.Next() {
+		var editorID uuid.UUID
+		if err := rows.Scan(&editorID); err == nil {
+			s.db.Pool.Exec(context.Background(),
+				"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+				editorID, message, paperID)
 		}
-	}()
-
-	c.JSON(http.StatusCreated, paper)
+	}
 }
 
+// UpdatePaper lets an author edit their own paper and move it between draft and submitted.
+// Decisions go through DecidePaper, withdrawal and retraction through their own endpoints.
 func (s *Server) UpdatePaper(c *gin.Context) {
 	paperID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
 		return
 	}
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
 
 	var req models.UpdatePaperRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Status != "draft" && req.Status != "submitted" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authors can only save a paper as draft or submit it"})
+		return
+	}
 
 	ctx := c.Request.Context()
+
+	// Papers belonging to a call cannot be (re)submitted once the call has closed
+	if req.Status == "submitted" {
+		var currentStatus string
+		var closesAt time.Time
+		err := s.db.Pool.QueryRow(ctx,
+			"SELECT p.status, c.closes_at FROM papers p JOIN calls c ON c.id = p.call_id WHERE p.id = $1 AND p.deleted_at IS NULL",
+			paperID).Scan(&currentStatus, &closesAt)
+		if err != nil && err != pgx.ErrNoRows {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+			return
+		}
+		if err == nil && currentStatus != "submitted" && !time.Now().Before(closesAt) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "The call for this paper has closed"})
+			return
+		}
+	}
+
+	// Only drafts and submissions are open to their author; a paper under review, decided,
+	// withdrawn or retracted is left alone
 	query := `
 		UPDATE papers
-		SET title = $1, abstract = $2, content = $3, file_url = $4, status = $5, updated_at = NOW()
-		WHERE id = $6
-		RETURNING id, title, COALESCE(abstract, ''), COALESCE(content, ''), COALESCE(file_url, ''), author_id, status, created_at, updated_at
+		SET title = $1, abstract = $2, content = $3, file_url = $4, status = $5,
+			keywords = COALESCE($6::text[], keywords), updated_at = NOW()
+		WHERE id = $7 AND author_id = $8 AND status IN ('draft', 'submitted') AND deleted_at IS NULL
+		RETURNING id, title, COALESCE(abstract, ''), COALESCE(content, ''), COALESCE(file_url, ''), author_id, status, created_at, updated_at, keywords
 	`
 
 	var paper models.Paper
-	err = s.db.Pool.QueryRow(ctx, query, req.Title, req.Abstract, req.Content, req.FileUrl, req.Status, paperID).Scan(
+	err = s.db.Pool.QueryRow(ctx, query, req.Title, req.Abstract, req.Content, req.FileUrl, req.Status,
+		models.NormalizeKeywords(req.Keywords), paperID, userID).Scan(
 		&paper.ID, &paper.Title, &paper.Abstract, &paper.Content, &paper.FileUrl, &paper.AuthorID,
-		&paper.Status, &paper.CreatedAt, &paper.UpdatedAt,
+		&paper.Status, &paper.CreatedAt, &paper.UpdatedAt, &paper.Keywords,
 	)
-
+	if err == pgx.ErrNoRows {
+		s.paperNotEditable(c, paperID, userID)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update paper"})
 		return
 	}
 
-	// If admin is publishing, approving or rejecting a recommended paper, notify the editor and author
-	if req.Status == "published" || req.Status == "rejected" || req.Status == "approved" {
-		go func() {
-			statusText := req.Status
-
-			// Notify all reviewers (editors)
-			rows, err := s.db.Pool.Query(context.Background(),
-				"SELECT reviewer_id FROM reviews WHERE paper_id = $1",
-				paper.ID)
-			if err == nil {
-				defer rows.Close()
-				for rows.Next() {
-					var reviewerID uuid.UUID
-					if err := rows.Scan(&reviewerID); err == nil {
-						message := fmt.Sprintf("Admin decision: Paper '%s' has been %s", paper.Title, statusText)
-						s.db.Pool.Exec(context.Background(),
-							"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
-							reviewerID, message, paper.ID)
-					}
+	c.JSON(http.StatusOK, paper)
+}
+
+// paperNotEditable explains why UpdatePaper matched no row
+func (s *Server) paperNotEditable(c *gin.Context, paperID, userID uuid.UUID) {
+	var current models.Paper
+	err := s.db.Pool.QueryRow(c.Request.Context(),
+		"SELECT author_id, status FROM papers WHERE id = $1 AND deleted_at IS NULL", paperID).Scan(&current.AuthorID, &current.Status)
+	switch {
+	case err == pgx.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+	case current.AuthorID != userID:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit this paper"})
+	case current.IsRetracted():
+		c.JSON(http.StatusConflict, gin.H{"error": "Retracted papers cannot be modified"})
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A paper that is %s can no longer be edited", current.Status)})
+	}
+}
+
+// DecidePaper records an approval, rejection or publication. Each is only allowed from the
+// statuses listed in models.DecisionFrom, checked in the UPDATE itself.
+func (s *Server) DecidePaper(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.PaperDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	// Publishing now replaces any pending schedule
+	var paper models.Paper
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE papers
+		SET status = $1, updated_at = NOW(),
+			publish_at = CASE WHEN $1 = 'published' THEN NULL ELSE publish_at END,
+			publication_date = CASE WHEN $1 = 'published' THEN COALESCE(publication_date, NOW()) ELSE publication_date END
+		WHERE id = $2 AND status = ANY($3) AND deleted_at IS NULL
+		RETURNING id, title, COALESCE(abstract, ''), COALESCE(content, ''), COALESCE(file_url, ''), author_id, status, created_at, updated_at
+	`, req.Status, paperID, models.DecisionFrom[req.Status]).Scan(
+		&paper.ID, &paper.Title, &paper.Abstract, &paper.Content, &paper.FileUrl, &paper.AuthorID,
+		&paper.Status, &paper.CreatedAt, &paper.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("This paper cannot be %s from its current status", req.Status)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record decision"})
+		return
+	}
+
+	// Reviews are frozen once the paper has a final decision
+	if err := s.db.LockReviews(ctx, paper.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock reviews"})
+		return
+	}
+
+	// Notify the reviewers and the author
+	deciderID := c.GetString("user_id")
+	go func() {
+		statusText := req.Status
+		s.postDecision(context.Background(), paper.ID, deciderID, fmt.Sprintf("Paper %s", statusText))
+
+		rows, err := s.db.Pool.Query(context.Background(),
+			"SELECT reviewer_id FROM reviews WHERE paper_id = $1",
+			paper.ID)
+		if err == nil {
+			defer rows.Close()
+			for rows.Next() {
+				var reviewerID uuid.UUID
+				if err := rows.Scan(&reviewerID); err == nil {
+					message := fmt.Sprintf("Admin decision: Paper '%s' has been %s", paper.Title, statusText)
+					s.db.Pool.Exec(context.Background(),
+						"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+						reviewerID, message, paper.ID)
 				}
 			}
+		}
 
-			// Also notify the author
-			message := fmt.Sprintf("Your paper '%s' has been %s", paper.Title, statusText)
-			s.db.Pool.Exec(context.Background(),
-				"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
-				paper.AuthorID, message, paper.ID)
-		}()
-	}
+		message := fmt.Sprintf("Your paper '%s' has been %s", paper.Title, statusText)
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+			paper.AuthorID, message, paper.ID)
+	}()
 
 	c.JSON(http.StatusOK, paper)
 }
@@ -618,29 +876,40 @@ func (s *Server) RecommendPaperForPublication(c *gin.Context) {
 		return
 	}
 
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
 	ctx := c.Request.Context()
 	// Update paper status to recommended_for_publication
 	query := `
 		UPDATE papers
 		SET status = 'recommended_for_publication', updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND status = ANY($2) AND deleted_at IS NULL
 		RETURNING id, title, COALESCE(abstract, ''), COALESCE(content, ''), COALESCE(file_url, ''), author_id, status, created_at, updated_at
 	`
 
 	var paper models.Paper
-	err = s.db.Pool.QueryRow(ctx, query, paperID).Scan(
+	err = s.db.Pool.QueryRow(ctx, query, paperID, models.RecommendFrom).Scan(
 		&paper.ID, &paper.Title, &paper.Abstract, &paper.Content, &paper.FileUrl, &paper.AuthorID,
 		&paper.Status, &paper.CreatedAt, &paper.UpdatedAt,
 	)
-
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only submitted papers or papers under review can be recommended for publication"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recommend paper"})
 		return
 	}
 
 	// Notify all admins and the author
+	editorID := c.GetString("user_id")
 	go func() {
-		rows, err := s.db.Pool.Query(context.Background(), "SELECT id FROM users WHERE role = 'admin'")
+		s.postDecision(context.Background(), paper.ID, editorID, "Recommended for publication")
+
+		rows, err := s.db.Pool.Query(context.Background(),
+			"SELECT id FROM users WHERE role = 'admin' AND tenant_id = (SELECT tenant_id FROM papers WHERE id = $1)", paper.ID)
 		if err == nil {
 			defer rows.Close()
 			for rows.Next() {
@@ -685,12 +954,16 @@ func (s *Server) UpdatePaperDetails(c *gin.Context) {
 		return
 	}
 
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
 	ctx := c.Request.Context()
 
 	// Generate Publication ID if not provided and status is being set to something that implies publication or if it's just missing
 	// For now, we'll generate it if it's empty.
 	if req.PublicationID == "" {
-		req.PublicationID, err = s.generatePublicationID(ctx)
+		req.PublicationID, err = s.generatePublicationID(ctx, currentTenant(c))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate Publication ID"})
 			return
@@ -708,7 +981,7 @@ func (s *Server) UpdatePaperDetails(c *gin.Context) {
 			ethical_clearance = $21, pi_name = $22, pi_gender = $23, co_investigators = $24,
 			produced_prototype = $25, hetril_collaboration = $26, submitted_to_incubator = $27,
 			updated_at = NOW()
-		WHERE id = $28
+		WHERE id = $28 AND deleted_at IS NULL
 		RETURNING id, title, COALESCE(abstract, ''), COALESCE(content, ''), COALESCE(file_url, ''), author_id, status, created_at, updated_at,
 				  COALESCE(institution_code, ''), COALESCE(publication_id, ''), COALESCE(publication_isced_band, ''), COALESCE(publication_title_amharic, ''),
 				  publication_date, COALESCE(publication_type, ''), COALESCE(journal_type, ''), COALESCE(journal_name, ''), COALESCE(indigenous_knowledge, false),
@@ -751,7 +1024,8 @@ func (s *Server) UpdatePaperDetails(c *gin.Context) {
 	// Notify Admin, Coordinator, and Author
 	go func() {
 		// Notify Admins
-		rows, err := s.db.Pool.Query(context.Background(), "SELECT id FROM users WHERE role = 'admin'")
+		rows, err := s.db.Pool.Query(context.Background(),
+			"SELECT id FROM users WHERE role = 'admin' AND tenant_id = (SELECT tenant_id FROM papers WHERE id = $1)", paper.ID)
 		if err == nil {
 			defer rows.Close()
 			for rows.Next() {
@@ -766,7 +1040,8 @@ func (s *Server) UpdatePaperDetails(c *gin.Context) {
 		}
 
 		// Notify Coordinators
-		rowsCoord, err := s.db.Pool.Query(context.Background(), "SELECT id FROM users WHERE role = 'coordinator'")
+		rowsCoord, err := s.db.Pool.Query(context.Background(),
+			"SELECT id FROM users WHERE role = 'coordinator' AND tenant_id = (SELECT tenant_id FROM papers WHERE id = $1)", paper.ID)
 		if err == nil {
 			defer rowsCoord.Close()
 			for rowsCoord.Next() {
@@ -790,44 +1065,22 @@ func (s *Server) UpdatePaperDetails(c *gin.Context) {
 	c.JSON(http.StatusOK, paper)
 }
 
-func (s *Server) generatePublicationID(ctx context.Context) (string, error) {
-	// Format: SMU_P201817001
-	// We need to find the last ID and increment it.
-	// Assuming the format is fixed and numeric part is at the end.
+// generatePublicationID returns the next publication ID in the tenant's series, e.g. SMU_P201817001
+func (s *Server) generatePublicationID(ctx context.Context, t *tenant.Tenant) (string, error) {
+	prefix := t.PublicationIDPrefix()
 
 	var lastID string
-	err := s.db.Pool.QueryRow(ctx, "SELECT publication_id FROM papers WHERE publication_id LIKE 'SMU_P%' ORDER BY publication_id DESC LIMIT 1").Scan(&lastID)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return "SMU_P201817001", nil
-		}
-		// If it's NULL (which it might be for existing papers), we might get here or Scan might fail depending on driver.
-		// pgx Scan returns error if NULL is scanned into string without NullString? No, Scan handles it if we use *string or NullString.
-		// But here we scan into string. If NULL, it errors.
-		// Let's handle it safely.
-		return "SMU_P201817001", nil // Default start if error or no rows
-	}
-
-	if lastID == "" {
-		return "SMU_P201817001", nil
-	}
-
-	// Parse the number
-	// SMU_P201817001 -> 201817001
-	if len(lastID) < 6 {
-		return "SMU_P201817001", nil
-	}
-
-	prefix := "SMU_P"
-	numStr := lastID[len(prefix):]
-	var num int
-	_, err = fmt.Sscanf(numStr, "%d", &num)
-	if err != nil {
-		return "SMU_P201817001", nil // Fallback
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT publication_id FROM papers
+		WHERE tenant_id = $1 AND LEFT(publication_id, LENGTH($2)) = $2
+		ORDER BY LENGTH(publication_id) DESC, publication_id DESC
+		LIMIT 1
+	`, t.ID, prefix).Scan(&lastID)
+	if err != nil && err != pgx.ErrNoRows {
+		return "", err
 	}
 
-	newNum := num + 1
-	return fmt.Sprintf("%s%d", prefix, newNum), nil
+	return t.NextPublicationID(lastID), nil
 }
 
 func (s *Server) DeletePaper(c *gin.Context) {
@@ -837,59 +1090,198 @@ func (s *Server) DeletePaper(c *gin.Context) {
 		return
 	}
 
+	// Authors delete their own papers; staff editing papers may delete any in their units
+	userID := c.GetString("user_id")
+	staff := s.can(c, rbac.PaperEdit)
+	if staff && !s.requirePaperScope(c, paperID) {
+		return
+	}
+
 	ctx := c.Request.Context()
-	query := `DELETE FROM papers WHERE id = $1`
+	// Papers are soft deleted so their reviews, notifications and history are kept
+	query := `
+		UPDATE papers SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = ANY($2) AND (author_id::text = $3 OR $4) AND deleted_at IS NULL
+	`
 
-	_, err = s.db.Pool.Exec(ctx, query, paperID)
+	result, err := s.db.Pool.Exec(ctx, query, paperID, models.DeletableStatuses, userID, staff)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete paper"})
 		return
 	}
+	if result.RowsAffected() == 0 {
+		s.paperNotDeletable(c, paperID, userID, staff)
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Paper deleted successfully"})
 }
 
+// paperNotDeletable explains why DeletePaper matched no row
+func (s *Server) paperNotDeletable(c *gin.Context, paperID uuid.UUID, userID string, staff bool) {
+	var current models.Paper
+	err := s.db.Pool.QueryRow(c.Request.Context(),
+		"SELECT author_id, status FROM papers WHERE id = $1 AND deleted_at IS NULL", paperID).Scan(&current.AuthorID, &current.Status)
+	switch {
+	case err == pgx.ErrNoRows:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+	case current.AuthorID.String() != userID && !staff:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can delete this paper"})
+	case current.IsPublished():
+		c.JSON(http.StatusConflict, gin.H{"error": "Published papers cannot be deleted; retract the paper instead"})
+	case current.IsRetracted():
+		c.JSON(http.StatusConflict, gin.H{"error": "Retracted papers stay on record and cannot be deleted"})
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A paper that is %s cannot be deleted; withdraw it first", current.Status)})
+	}
+}
+
 // Review Handlers
+
+// reviewColumns are the columns scanned by scanReview, in order
+const reviewColumns = `r.id, r.paper_id, r.reviewer_id, COALESCE(r.rating, 0),
+	COALESCE(r.problem_statement, 0), COALESCE(r.literature_review, 0),
+	COALESCE(r.methodology, 0), COALESCE(r.results, 0), COALESCE(r.conclusion, 0),
+	COALESCE(r.originality, 0), COALESCE(r.clarity_organization, 0),
+	COALESCE(r.contribution_knowledge, 0), COALESCE(r.technical_quality, 0),
+	COALESCE(r.comments, ''), COALESCE(r.confidential_comments, ''), COALESCE(r.recommendation, ''), r.status, r.submitted_at, r.locked_at,
+	r.created_at, r.updated_at`
+
+func scanReview(row pgx.Row, review *models.Review, extra ...interface{}) error {
+	dest := []interface{}{
+		&review.ID, &review.PaperID, &review.ReviewerID, &review.Rating,
+		&review.ProblemStatement, &review.LiteratureReview, &review.Methodology,
+		&review.Results, &review.Conclusion, &review.Originality, &review.ClarityOrg,
+		&review.Contribution, &review.TechnicalQuality,
+		&review.Comments, &review.ConfidentialComments, &review.Recommendation, &review.Status, &review.SubmittedAt, &review.LockedAt,
+		&review.CreatedAt, &review.UpdatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// canReadConfidential reports whether the caller may see the editor-only parts of a review:
+// its own reviewer, or editors and admins who are not the paper's author
+func (s *Server) canReadConfidential(c *gin.Context, review *models.Review, paperAuthorID *uuid.UUID) bool {
+	userID := c.GetString("user_id")
+	if review.ReviewerID.String() == userID {
+		return true
+	}
+	if paperAuthorID != nil && paperAuthorID.String() == userID {
+		return false
+	}
+	return s.can(c, rbac.ReviewReadConfidential)
+}
+
+// loadReviewAttachments fills in the attachments of the given reviews
+func (s *Server) loadReviewAttachments(ctx context.Context, reviews []*models.Review) error {
+	if len(reviews) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*models.Review, len(reviews))
+	ids := make([]uuid.UUID, 0, len(reviews))
+	for _, r := range reviews {
+		byID[r.ID] = r
+		ids = append(ids, r.ID)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, review_id, file_url, file_name, COALESCE(file_type, ''), COALESCE(file_size, 0),
+			   COALESCE(note, ''), confidential, created_at
+		FROM review_attachments
+		WHERE review_id = ANY($1)
+		ORDER BY created_at
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.ReviewAttachment
+		if err := rows.Scan(&a.ID, &a.ReviewID, &a.FileURL, &a.FileName, &a.FileType, &a.FileSize,
+			&a.Note, &a.Confidential, &a.CreatedAt); err != nil {
+			return err
+		}
+		if r, ok := byID[a.ReviewID]; ok {
+			r.Attachments = append(r.Attachments, a)
+		}
+	}
+	return rows.Err()
+}
+
+// replaceReviewAttachments swaps the attachments of a review for the given ones
+func replaceReviewAttachments(ctx context.Context, tx pgx.Tx, review *models.Review, inputs []models.ReviewAttachmentInput) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM review_attachments WHERE review_id = $1", review.ID); err != nil {
+		return err
+	}
+	review.Attachments = nil
+	for _, in := range inputs {
+		a := models.ReviewAttachment{
+			ReviewID:     review.ID,
+			FileURL:      in.FileURL,
+			FileName:     in.FileName,
+			FileType:     in.FileType,
+			FileSize:     in.FileSize,
+			Note:         in.Note,
+			Confidential: in.Confidential,
+		}
+		err := tx.QueryRow(ctx, `
+			INSERT INTO review_attachments (review_id, file_url, file_name, file_type, file_size, note, confidential)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at
+		`, a.ReviewID, a.FileURL, a.FileName, a.FileType, a.FileSize, a.Note, a.Confidential).Scan(&a.ID, &a.CreatedAt)
+		if err != nil {
+			return err
+		}
+		review.Attachments = append(review.Attachments, a)
+	}
+	return nil
+}
+
+// GetReviews lists the reviews in the tenant. Drafts are only returned to their own reviewer,
+// authors only see the reviews of their own papers and those they wrote, and confidential comments and attachments
+// are removed for anyone who is not entitled to them.
 func (s *Server) GetReviews(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	paperID := c.Query("paper_id")
-	var query string
-	var args []interface{}
-
-	if paperID != "" {
-		query = `
-			SELECT r.id, r.paper_id, r.reviewer_id, r.rating, 
-				   COALESCE(r.problem_statement, 0), COALESCE(r.literature_review, 0), 
-				   COALESCE(r.methodology, 0), COALESCE(r.results, 0), COALESCE(r.conclusion, 0),
-				   COALESCE(r.originality, 0), COALESCE(r.clarity_organization, 0),
-				   COALESCE(r.contribution_knowledge, 0), COALESCE(r.technical_quality, 0),
-				   COALESCE(r.comments, ''), r.recommendation, r.created_at, r.updated_at,
-				   COALESCE(reviewer.name, 'Unknown'), COALESCE(reviewer.email, ''),
-				   COALESCE(p.title, 'Unknown Paper')
-			FROM reviews r
-			LEFT JOIN users reviewer ON r.reviewer_id = reviewer.id
-			LEFT JOIN papers p ON r.paper_id = p.id
-			WHERE r.paper_id = $1
-			ORDER BY r.created_at DESC
-		`
-		args = append(args, paperID)
+	userID := c.GetString("user_id")
+	query := `
+		SELECT ` + reviewColumns + `,
+			   COALESCE(reviewer.name, 'Unknown'), COALESCE(reviewer.email, ''),
+			   COALESCE(p.title, 'Unknown Paper'), p.author_id
+		FROM reviews r
+		LEFT JOIN users reviewer ON r.reviewer_id = reviewer.id
+		LEFT JOIN papers p ON r.paper_id = p.id
+		WHERE r.tenant_id = $1 AND (r.status != 'draft' OR r.reviewer_id = $2)
+	`
+	args := []interface{}{tenantID(c), userID}
+
+	if !s.can(c, rbac.ReviewReadAll) {
+		query += " AND (p.author_id = $2 OR r.reviewer_id = $2)"
 	} else {
-		query = `
-			SELECT r.id, r.paper_id, r.reviewer_id, r.rating, 
-				   COALESCE(r.problem_statement, 0), COALESCE(r.literature_review, 0), 
-				   COALESCE(r.methodology, 0), COALESCE(r.results, 0), COALESCE(r.conclusion, 0),
-				   COALESCE(r.originality, 0), COALESCE(r.clarity_organization, 0),
-				   COALESCE(r.contribution_knowledge, 0), COALESCE(r.technical_quality, 0),
-				   COALESCE(r.comments, ''), r.recommendation, r.created_at, r.updated_at,
-				   COALESCE(reviewer.name, 'Unknown'), COALESCE(reviewer.email, ''),
-				   COALESCE(p.title, 'Unknown Paper')
-			FROM reviews r
-			LEFT JOIN users reviewer ON r.reviewer_id = reviewer.id
-			LEFT JOIN papers p ON r.paper_id = p.id
-			ORDER BY r.created_at DESC
-		`
+		// Staff limited to their units still see the reviews they wrote and those of their own papers
+		scope, err := s.staffUnitScope(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+			return
+		}
+		if scope != nil {
+			args = append(args, scope)
+			query += " AND (p.unit_id IS NULL OR " + inUnitSubtree("p.unit_id", len(args)) + " OR p.author_id = $2 OR r.reviewer_id = $2)"
+		}
 	}
+	if raw := c.Query("paper_id"); raw != "" {
+		paperID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+			return
+		}
+		args = append(args, paperID)
+		query += fmt.Sprintf(" AND r.paper_id = $%d", len(args))
+	}
+	query += " ORDER BY r.created_at DESC"
 
 	rows, err := s.db.Pool.Query(ctx, query, args...)
 	if err != nil {
@@ -899,26 +1291,94 @@ func (s *Server) GetReviews(c *gin.Context) {
 	defer rows.Close()
 
 	var reviews []models.ReviewWithReviewer
+	var authors []*uuid.UUID
 	for rows.Next() {
 		var review models.ReviewWithReviewer
-		err := rows.Scan(
-			&review.ID, &review.PaperID, &review.ReviewerID, &review.Rating,
-			&review.ProblemStatement, &review.LiteratureReview, &review.Methodology,
-			&review.Results, &review.Conclusion, &review.Originality, &review.ClarityOrg,
-			&review.Contribution, &review.TechnicalQuality,
-			&review.Comments, &review.Recommendation, &review.CreatedAt, &review.UpdatedAt,
-			&review.ReviewerName, &review.ReviewerEmail, &review.PaperTitle,
-		)
+		var authorID *uuid.UUID
+		err := scanReview(rows, &review.Review, &review.ReviewerName, &review.ReviewerEmail, &review.PaperTitle, &authorID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan review"})
 			return
 		}
 		reviews = append(reviews, review)
+		authors = append(authors, authorID)
+	}
+	rows.Close()
+
+	refs := make([]*models.Review, len(reviews))
+	for i := range reviews {
+		refs[i] = &reviews[i].Review
+	}
+	if err := s.loadReviewAttachments(ctx, refs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review attachments"})
+		return
+	}
+	for i, review := range refs {
+		if !s.canReadConfidential(c, review, authors[i]) {
+			review.RedactForAuthor()
+		}
 	}
 
 	c.JSON(http.StatusOK, reviews)
 }
 
+// reviewStatus validates the status a review is being saved with, defaulting to submitted
+func reviewStatus(c *gin.Context, review *models.Review, requested string) bool {
+	if requested == "" {
+		requested = models.ReviewSubmitted
+	}
+	review.Status = requested
+	if review.IsDraft() {
+		return true
+	}
+	if missing := review.MissingForSubmission(); len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The review is incomplete", "missing": missing})
+		return false
+	}
+	return true
+}
+
+// paperAcceptsReviews responds with 409 when the paper already has a final decision
+func (s *Server) paperAcceptsReviews(c *gin.Context, paperID uuid.UUID) bool {
+	paper, err := s.getLivePaper(c.Request.Context(), paperID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return false
+	}
+	if paper.HasFinalDecision() {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Reviews are closed because the paper is %s", paper.Status)})
+		return false
+	}
+	return true
+}
+
+// notifyReviewSubmitted tells the paper's author that a review has been submitted or corrected
+func (s *Server) notifyReviewSubmitted(review models.Review, corrected bool) {
+	var authorID uuid.UUID
+	var paperTitle string
+	err := s.db.Pool.QueryRow(context.Background(),
+		"SELECT author_id, title FROM papers WHERE id = $1",
+		review.PaperID).Scan(&authorID, &paperTitle)
+	if err != nil {
+		return
+	}
+
+	message := fmt.Sprintf("Your paper '%s' has been reviewed. Rating: %d/5, Recommendation: %s",
+		paperTitle, review.Rating, review.Recommendation)
+	if corrected {
+		message = fmt.Sprintf("A review of your paper '%s' has been updated. Rating: %d/5, Recommendation: %s",
+			paperTitle, review.Rating, review.Recommendation)
+	}
+
+	s.db.Pool.Exec(context.Background(),
+		"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+		authorID, message, review.PaperID)
+}
+
 func (s *Server) CreateReview(c *gin.Context) {
 	var req models.CreateReviewRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -933,86 +1393,248 @@ func (s *Server) CreateReview(c *gin.Context) {
 		return
 	}
 
-	review := models.Review{
-		PaperID:          req.PaperID,
-		ReviewerID:       reviewerID,
-		Rating:           req.Rating,
-		ProblemStatement: req.ProblemStatement,
-		LiteratureReview: req.LiteratureReview,
-		Methodology:      req.Methodology,
-		Results:          req.Results,
-		Conclusion:       req.Conclusion,
-		Originality:      req.Originality,
-		ClarityOrg:       req.ClarityOrg,
-		Contribution:     req.Contribution,
-		TechnicalQuality: req.TechnicalQuality,
-		Comments:         req.Comments,
-		Recommendation:   req.Recommendation,
+	if !s.requirePaperScope(c, req.PaperID) {
+		return
 	}
 
 	ctx := c.Request.Context()
+
+	// Nobody reviews a paper they wrote or contributed to, whatever their role
+	var isAuthor, accepted bool
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM (`+authorships+`) a WHERE a.paper_id = $1 AND a.user_id = $2),
+			   EXISTS (SELECT 1 FROM review_assignments WHERE paper_id = $1 AND reviewer_id = $2 AND status = 'accepted')
+	`, req.PaperID, reviewerID).Scan(&isAuthor, &accepted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check review assignment"})
+		return
+	}
+	if isAuthor {
+		c.JSON(http.StatusConflict, gin.H{"error": "Authors cannot review their own paper"})
+		return
+	}
+
+	// Editors and admins may review any paper; anyone else needs an accepted invitation
+	if !s.can(c, rbac.PaperReview) && !accepted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You need an accepted invitation to review this paper"})
+		return
+	}
+
+	review := models.Review{
+		PaperID:              req.PaperID,
+		ReviewerID:           reviewerID,
+		Rating:               req.Rating,
+		ProblemStatement:     req.ProblemStatement,
+		LiteratureReview:     req.LiteratureReview,
+		Methodology:          req.Methodology,
+		Results:              req.Results,
+		Conclusion:           req.Conclusion,
+		Originality:          req.Originality,
+		ClarityOrg:           req.ClarityOrg,
+		Contribution:         req.Contribution,
+		TechnicalQuality:     req.TechnicalQuality,
+		Comments:             req.Comments,
+		ConfidentialComments: req.ConfidentialComments,
+		Recommendation:       req.Recommendation,
+	}
+	if !reviewStatus(c, &review, req.Status) {
+		return
+	}
+	if !s.paperAcceptsReviews(c, req.PaperID) {
+		return
+	}
+
 	query := `
-		INSERT INTO reviews (paper_id, reviewer_id, rating, problem_statement, literature_review, methodology, results, conclusion, originality, clarity_organization, contribution_knowledge, technical_quality, comments, recommendation)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-		RETURNING id, paper_id, reviewer_id, rating, problem_statement, literature_review, methodology, results, conclusion, originality, clarity_organization, contribution_knowledge, technical_quality, comments, recommendation, created_at, updated_at
-	`
+		INSERT INTO reviews AS r (paper_id, reviewer_id, rating, problem_statement, literature_review, methodology, results, conclusion, originality, clarity_organization, contribution_knowledge, technical_quality, comments, confidential_comments, recommendation, status, submitted_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''), NULLIF($15, ''), $16::varchar, CASE WHEN $16::varchar = 'submitted' THEN NOW() END)
+		RETURNING ` + reviewColumns
 
-	err = s.db.Pool.QueryRow(ctx, query,
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	err = scanReview(tx.QueryRow(ctx, query,
 		review.PaperID, review.ReviewerID, review.Rating,
 		review.ProblemStatement, review.LiteratureReview, review.Methodology,
 		review.Results, review.Conclusion, review.Originality, review.ClarityOrg,
 		review.Contribution, review.TechnicalQuality,
-		review.Comments, review.Recommendation).Scan(
-		&review.ID, &review.PaperID, &review.ReviewerID, &review.Rating,
-		&review.ProblemStatement, &review.LiteratureReview, &review.Methodology,
-		&review.Results, &review.Conclusion, &review.Originality, &review.ClarityOrg,
-		&review.Contribution, &review.TechnicalQuality,
-		&review.Comments, &review.Recommendation, &review.CreatedAt, &review.UpdatedAt,
-	)
-
+		review.Comments, review.ConfidentialComments, review.Recommendation, review.Status), &review)
+	if isUniqueViolation(err) {
+		var existingID uuid.UUID
+		s.db.Pool.QueryRow(ctx, "SELECT id FROM reviews WHERE paper_id = $1 AND reviewer_id = $2", review.PaperID, review.ReviewerID).Scan(&existingID)
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reviewed this paper; update your existing review instead", "review_id": existingID})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
 		return
 	}
+	if err := replaceReviewAttachments(ctx, tx, &review, req.Attachments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review attachments"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
+		return
+	}
 
-	// Send notification to paper author
-	go func() {
-		// Get paper details to find author
-		var authorID uuid.UUID
-		var paperTitle string
-		err := s.db.Pool.QueryRow(context.Background(),
-			"SELECT author_id, title FROM papers WHERE id = $1",
-			review.PaperID).Scan(&authorID, &paperTitle)
-
-		if err == nil {
-			// Create notification message with review details
-			message := fmt.Sprintf("Your paper '%s' has been reviewed. Rating: %d/5, Recommendation: %s",
-				paperTitle, review.Rating, review.Recommendation)
+	// Editorial staff reviewing a paper become part of its discussion
+	if s.can(c, rbac.PaperDiscuss) {
+		s.assignPaperStaff(ctx, review.PaperID, reviewerID, nil)
+	}
 
-			s.db.Pool.Exec(context.Background(),
-				"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
-				authorID, message, review.PaperID)
-		}
-	}()
+	// Authors only hear about reviews once they are submitted
+	if !review.IsDraft() {
+		s.completeAssignment(ctx, review.PaperID, review.ReviewerID)
+		go s.notifyReviewSubmitted(review, false)
+	}
 
 	c.JSON(http.StatusCreated, review)
 }
 
+// UpdateReview lets a reviewer save their draft, submit it, or correct a submitted review
+// until the paper has a final decision
+func (s *Server) UpdateReview(c *gin.Context) {
+	reviewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var req models.UpdateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var current models.Review
+	err = scanReview(s.db.Pool.QueryRow(ctx, "SELECT "+reviewColumns+" FROM reviews r WHERE r.id = $1", reviewID), &current)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review"})
+		return
+	}
+
+	if current.ReviewerID.String() != c.GetString("user_id") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the reviewer can update this review"})
+		return
+	}
+	if current.IsLocked() {
+		c.JSON(http.StatusConflict, gin.H{"error": "This review is locked because a decision has been made on the paper"})
+		return
+	}
+
+	requested := req.Status
+	if requested == "" {
+		requested = current.Status
+	}
+	if requested == models.ReviewDraft && !current.IsDraft() {
+		c.JSON(http.StatusConflict, gin.H{"error": "A submitted review cannot be turned back into a draft"})
+		return
+	}
+
+	review := models.Review{
+		ID:                   current.ID,
+		PaperID:              current.PaperID,
+		ReviewerID:           current.ReviewerID,
+		Rating:               req.Rating,
+		ProblemStatement:     req.ProblemStatement,
+		LiteratureReview:     req.LiteratureReview,
+		Methodology:          req.Methodology,
+		Results:              req.Results,
+		Conclusion:           req.Conclusion,
+		Originality:          req.Originality,
+		ClarityOrg:           req.ClarityOrg,
+		Contribution:         req.Contribution,
+		TechnicalQuality:     req.TechnicalQuality,
+		Comments:             req.Comments,
+		ConfidentialComments: req.ConfidentialComments,
+		Recommendation:       req.Recommendation,
+	}
+	if !reviewStatus(c, &review, requested) {
+		return
+	}
+	if !s.paperAcceptsReviews(c, review.PaperID) {
+		return
+	}
+
+	query := `
+		UPDATE reviews AS r
+		SET rating = $1, problem_statement = $2, literature_review = $3, methodology = $4, results = $5,
+			conclusion = $6, originality = $7, clarity_organization = $8, contribution_knowledge = $9,
+			technical_quality = $10, comments = $11, confidential_comments = NULLIF($12, ''),
+			recommendation = NULLIF($13, ''), status = $14::varchar,
+			submitted_at = CASE WHEN $14::varchar = 'submitted' THEN COALESCE(submitted_at, NOW()) END,
+			updated_at = NOW()
+		WHERE id = $15 AND status != 'locked'
+		RETURNING ` + reviewColumns
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	err = scanReview(tx.QueryRow(ctx, query,
+		review.Rating, review.ProblemStatement, review.LiteratureReview, review.Methodology,
+		review.Results, review.Conclusion, review.Originality, review.ClarityOrg,
+		review.Contribution, review.TechnicalQuality,
+		review.Comments, review.ConfidentialComments, review.Recommendation, review.Status, review.ID), &review)
+	if err == pgx.ErrNoRows {
+		// Locked between the read and the write
+		c.JSON(http.StatusConflict, gin.H{"error": "This review is locked because a decision has been made on the paper"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+		return
+	}
+	if req.Attachments != nil {
+		err = replaceReviewAttachments(ctx, tx, &review, req.Attachments)
+	} else {
+		err = s.loadReviewAttachments(ctx, []*models.Review{&review})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review attachments"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review"})
+		return
+	}
+
+	if !review.IsDraft() {
+		s.completeAssignment(ctx, review.PaperID, review.ReviewerID)
+		go s.notifyReviewSubmitted(review, !current.IsDraft())
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
 // Event Handlers
 func (s *Server) GetEvents(c *gin.Context) {
 	ctx := c.Request.Context()
 	status := c.Query("status")
 
 	query := `
-		SELECT e.id, e.title, e.description, e.category, e.status, COALESCE(e.image_url, ''), COALESCE(e.video_url, ''), e.date, e.location, e.coordinator_id, e.created_at, e.updated_at,
+		SELECT e.id, e.title, e.description, e.category, e.status, COALESCE(e.image_url, ''), COALESCE(e.video_url, ''), e.date, e.location, e.coordinator_id, e.created_at, e.updated_at, e.publish_at,
 			   c.name as coordinator_name, c.email as coordinator_email
 		FROM events e
 		LEFT JOIN users c ON e.coordinator_id = c.id
+		WHERE e.tenant_id = $1
 	`
 
-	var args []interface{}
+	args := []interface{}{tenantID(c)}
 	if status != "" {
-		query += " WHERE e.status = $1"
+		query += " AND e.status = $2"
 		args = append(args, status)
 	}
 
@@ -1030,7 +1652,7 @@ func (s *Server) GetEvents(c *gin.Context) {
 		var event models.EventWithCoordinator
 		err := rows.Scan(
 			&event.ID, &event.Title, &event.Description, &event.Category, &event.Status, &event.ImageURL, &event.VideoURL, &event.Date, &event.Location, &event.CoordinatorID,
-			&event.CreatedAt, &event.UpdatedAt, &event.CoordinatorName, &event.CoordinatorEmail,
+			&event.CreatedAt, &event.UpdatedAt, &event.PublishAt, &event.CoordinatorName, &event.CoordinatorEmail,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan event"})
@@ -1052,15 +1674,15 @@ func (s *Server) PublishEvent(c *gin.Context) {
 	ctx := c.Request.Context()
 	query := `
 		UPDATE events
-		SET status = 'published', updated_at = NOW()
+		SET status = 'published', publish_at = NOW(), updated_at = NOW()
 		WHERE id = $1
-		RETURNING id, title, description, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), date, location, coordinator_id, created_at, updated_at
+		RETURNING id, title, description, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), date, location, coordinator_id, created_at, updated_at, publish_at
 	`
 
 	var event models.Event
 	err = s.db.Pool.QueryRow(ctx, query, eventID).Scan(
 		&event.ID, &event.Title, &event.Description, &event.Category, &event.Status, &event.ImageURL, &event.VideoURL, &event.Date, &event.Location,
-		&event.CoordinatorID, &event.CreatedAt, &event.UpdatedAt,
+		&event.CoordinatorID, &event.CreatedAt, &event.UpdatedAt, &event.PublishAt,
 	)
 
 	if err != nil {
@@ -1101,12 +1723,12 @@ func (s *Server) CreateEvent(c *gin.Context) {
 	query := `
 		INSERT INTO events (title, description, category, status, image_url, video_url, date, location, coordinator_id)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, title, description, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), date, location, coordinator_id, created_at, updated_at
+		RETURNING id, title, description, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), date, location, coordinator_id, created_at, updated_at, publish_at
 	`
 
 	err = s.db.Pool.QueryRow(ctx, query, event.Title, event.Description, event.Category, event.Status, event.ImageURL, event.VideoURL, event.Date, event.Location, event.CoordinatorID).Scan(
 		&event.ID, &event.Title, &event.Description, &event.Category, &event.Status, &event.ImageURL, &event.VideoURL, &event.Date, &event.Location,
-		&event.CoordinatorID, &event.CreatedAt, &event.UpdatedAt,
+		&event.CoordinatorID, &event.CreatedAt, &event.UpdatedAt, &event.PublishAt,
 	)
 
 	if err != nil {
@@ -1135,13 +1757,13 @@ func (s *Server) UpdateEvent(c *gin.Context) {
 		UPDATE events
 		SET title = $1, description = $2, category = $3, date = $4, location = $5, image_url = $6, video_url = $7, updated_at = NOW()
 		WHERE id = $8
-		RETURNING id, title, description, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), date, location, coordinator_id, created_at, updated_at
+		RETURNING id, title, description, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), date, location, coordinator_id, created_at, updated_at, publish_at
 	`
 
 	var event models.Event
 	err = s.db.Pool.QueryRow(ctx, query, req.Title, req.Description, req.Category, req.Date, req.Location, req.ImageURL, req.VideoURL, eventID).Scan(
 		&event.ID, &event.Title, &event.Description, &event.Category, &event.Status, &event.ImageURL, &event.VideoURL, &event.Date, &event.Location,
-		&event.CoordinatorID, &event.CreatedAt, &event.UpdatedAt,
+		&event.CoordinatorID, &event.CreatedAt, &event.UpdatedAt, &event.PublishAt,
 	)
 
 	if err != nil {
@@ -1178,7 +1800,7 @@ func (s *Server) GetAdminUsers(c *gin.Context) {
 	query := `
 		SELECT id, email, name, role
 		FROM users
-		WHERE role = 'admin'
+		WHERE role = 'admin' AND tenant_id = $1
 		LIMIT 1
 	`
 
@@ -1189,7 +1811,7 @@ func (s *Server) GetAdminUsers(c *gin.Context) {
 		Role  string `json:"role"`
 	}
 
-	err := s.db.Pool.QueryRow(ctx, query).Scan(
+	err := s.db.Pool.QueryRow(ctx, query, tenantID(c)).Scan(
 		&adminUser.ID, &adminUser.Email, &adminUser.Name, &adminUser.Role,
 	)
 
@@ -1217,16 +1839,20 @@ func (s *Server) CreateNotification(c *gin.Context) {
 	ctx := c.Request.Context()
 	query := `
 		INSERT INTO notifications (user_id, message, paper_id)
-		VALUES ($1, $2, $3)
+		SELECT id, $2, $3 FROM users WHERE id = $1 AND tenant_id = $4
 		RETURNING id, user_id, message, is_read, created_at, paper_id
 	`
 
 	var notification models.Notification
-	err := s.db.Pool.QueryRow(ctx, query, req.UserID, req.Message, req.PaperID).Scan(
+	err := s.db.Pool.QueryRow(ctx, query, req.UserID, req.Message, req.PaperID, tenantID(c)).Scan(
 		&notification.ID, &notification.UserID, &notification.Message,
 		&notification.IsRead, &notification.CreatedAt, &notification.PaperID,
 	)
 
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create notification"})
 		return
@@ -1300,12 +1926,12 @@ func (s *Server) MarkNotificationRead(c *gin.Context) {
 	query := `
 		UPDATE notifications
 		SET is_read = true
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 		RETURNING id, user_id, message, paper_id, is_read, created_at
 	`
 
 	var notification models.Notification
-	err = s.db.Pool.QueryRow(ctx, query, id).Scan(
+	err = s.db.Pool.QueryRow(ctx, query, id, tenantID(c)).Scan(
 		&notification.ID, &notification.UserID, &notification.Message,
 		&notification.PaperID, &notification.IsRead, &notification.CreatedAt,
 	)
@@ -1335,40 +1961,42 @@ func (s *Server) AdminCreateUser(c *gin.Context) {
 		return
 	}
 
-	// Create user in Supabase (confirmed)
+	// Create the account already confirmed
 	metadata := map[string]interface{}{
 		"name": req.Name,
 		"role": req.Role,
 	}
 
-	sbUser, err := s.supabase.AdminCreateUser(req.Email, req.Password, metadata)
+	ctx := c.Request.Context()
+	ident, err := s.identity.CreateConfirmed(ctx, req.Email, req.Password, metadata)
+	if errors.Is(err, auth.ErrAlreadyRegistered) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create user in Supabase: %v", err)})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create user: %v", err)})
 		return
 	}
 
-	ctx := c.Request.Context()
 	user := models.User{
-		ID:          uuid.MustParse(sbUser.ID),
-		Email:       sbUser.Email,
+		ID:          ident.ID,
+		Email:       ident.Email,
 		Name:        req.Name,
 		Role:        req.Role,
 		IsVerified:  true,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 		Preferences: map[string]interface{}{},
+		TenantID:    tenantID(c),
 	}
 
 	// Insert into local DB
 	query := `
-		INSERT INTO users (id, email, password_hash, name, role, is_verified, created_at, updated_at, preferences)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (id, email, password_hash, name, role, is_verified, created_at, updated_at, preferences, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
-	// We assume simple password hash storage isn't needed locally if we rely on Supabase,
-	// but we can store a placeholder or hash it if we want local fallback.
-	// For now, empty string.
 	_, err = s.db.Pool.Exec(ctx, query,
-		user.ID, user.Email, "", user.Name, user.Role, user.IsVerified, user.CreatedAt, user.UpdatedAt, user.Preferences,
+		user.ID, user.Email, ident.PasswordHash, user.Name, user.Role, user.IsVerified, user.CreatedAt, user.UpdatedAt, user.Preferences, user.TenantID,
 	)
 
 	if err != nil {
@@ -1386,11 +2014,11 @@ func (s *Server) GetAdminStaff(c *gin.Context) {
 	query := `
 		SELECT id, email, name, role, created_at, is_verified
 		FROM users
-		WHERE role IN ('editor', 'coordinator')
+		WHERE role IN ('editor', 'coordinator') AND tenant_id = $1
 		ORDER BY created_at DESC
 	`
 
-	rows, err := s.db.Pool.Query(ctx, query)
+	rows, err := s.db.Pool.Query(ctx, query, tenantID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staff"})
 		return
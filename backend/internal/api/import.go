@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"rpms-backend/internal/importer"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportLegacyCSV ingests a ministry-format CSV of papers or research projects.
+// With dry_run=true it only returns the per-row preview.
+func (s *Server) ImportLegacyCSV(c *gin.Context) {
+	kind := c.Param("kind")
+	if kind != importer.KindPapers && kind != importer.KindProjects {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Import kind must be papers or projects"})
+		return
+	}
+
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		return
+	}
+	defer file.Close()
+
+	dryRun := c.Query("dry_run") == "true"
+
+	report, err := importer.New(s.db, tenantID(c)).Import(c.Request.Context(), file, kind, dryRun)
+	var fileErr *importer.FileError
+	if errors.As(err, &fileErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fileErr.Error()})
+		return
+	}
+	if err != nil {
+		fmt.Printf("Legacy import failed: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import file"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -96,11 +96,11 @@ func (s *Server) GetPostLikes(c *gin.Context) {
 		SELECT l.id, l.user_id, l.post_type, l.post_id, l.created_at, u.name, u.avatar
 		FROM likes l
 		JOIN users u ON l.user_id = u.id
-		WHERE l.post_type = $1 AND l.post_id = $2
+		WHERE l.post_type = $1 AND l.post_id = $2 AND l.tenant_id = $3
 		ORDER BY l.created_at DESC
 	`
 
-	rows, err := s.db.Pool.Query(ctx, query, postType, postUUID)
+	rows, err := s.db.Pool.Query(ctx, query, postType, postUUID, tenantID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch likes"})
 		return
@@ -204,11 +204,11 @@ func (s *Server) GetComments(c *gin.Context) {
 		SELECT c.id, c.user_id, c.post_type, c.post_id, c.content, c.created_at, c.updated_at, u.name, u.avatar
 		FROM comments c
 		JOIN users u ON c.user_id = u.id
-		WHERE c.post_type = $1 AND c.post_id = $2
+		WHERE c.post_type = $1 AND c.post_id = $2 AND c.tenant_id = $3
 		ORDER BY c.created_at DESC
 	`
 
-	rows, err := s.db.Pool.Query(ctx, query, postType, postUUID)
+	rows, err := s.db.Pool.Query(ctx, query, postType, postUUID, tenantID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
 		return
@@ -265,13 +265,17 @@ func (s *Server) ShareToMessage(c *gin.Context) {
 	messageID := uuid.New()
 	insertMessageQuery := `
 		INSERT INTO messages (id, sender_id, receiver_id, content, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		SELECT $1, $2, id, $4, $5 FROM users WHERE id = $3 AND tenant_id = $6
 	`
-	_, err = s.db.Pool.Exec(ctx, insertMessageQuery, messageID, userID, req.RecipientID, messageContent, time.Now())
+	result, err := s.db.Pool.Exec(ctx, insertMessageQuery, messageID, userID, req.RecipientID, messageContent, time.Now(), tenantID(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share"})
 		return
 	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipient not found"})
+		return
+	}
 
 	// Record share
 	share := models.Share{
@@ -321,13 +325,13 @@ func (s *Server) GetEngagementStats(c *gin.Context) {
 	}
 
 	// Get likes count
-	s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM likes WHERE post_type = $1 AND post_id = $2", postType, postUUID).Scan(&stats.LikesCount)
+	s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM likes WHERE post_type = $1 AND post_id = $2 AND tenant_id = $3", postType, postUUID, tenantID(c)).Scan(&stats.LikesCount)
 
 	// Get comments count
-	s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM comments WHERE post_type = $1 AND post_id = $2", postType, postUUID).Scan(&stats.CommentsCount)
+	s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM comments WHERE post_type = $1 AND post_id = $2 AND tenant_id = $3", postType, postUUID, tenantID(c)).Scan(&stats.CommentsCount)
 
 	// Get shares count
-	s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM shares WHERE post_type = $1 AND post_id = $2", postType, postUUID).Scan(&stats.SharesCount)
+	s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM shares WHERE post_type = $1 AND post_id = $2 AND tenant_id = $3", postType, postUUID, tenantID(c)).Scan(&stats.SharesCount)
 
 	// Check if current user liked
 	if userID != nil {
@@ -349,9 +353,11 @@ func (s *Server) GetEngagementStats(c *gin.Context) {
 func (s *Server) createEngagementNotification(postType string, postID uuid.UUID, userID uuid.UUID, action string) {
 	ctx := context.Background()
 
-	// Get coordinator user ID
+	// Get a coordinator of the user's institution
 	var coordinatorID uuid.UUID
-	err := s.db.Pool.QueryRow(ctx, "SELECT id FROM users WHERE role = 'coordinator' LIMIT 1").Scan(&coordinatorID)
+	err := s.db.Pool.QueryRow(ctx,
+		"SELECT id FROM users WHERE role = 'coordinator' AND tenant_id = (SELECT tenant_id FROM users WHERE id = $1) LIMIT 1",
+		userID).Scan(&coordinatorID)
 	if err != nil {
 		return // No coordinator found
 	}
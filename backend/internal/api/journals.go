@@ -0,0 +1,571 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *Server) GetJournals(c *gin.Context) {
+	ctx := c.Request.Context()
+	query := `
+		SELECT id, name, COALESCE(issn, ''), COALESCE(publisher, ''), COALESCE(description, ''), created_at, updated_at
+		FROM journals
+		WHERE tenant_id = $1
+		ORDER BY name ASC
+	`
+
+	rows, err := s.db.Pool.Query(ctx, query, tenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch journals"})
+		return
+	}
+	defer rows.Close()
+
+	journals := []models.Journal{}
+	for rows.Next() {
+		var j models.Journal
+		if err := rows.Scan(&j.ID, &j.Name, &j.ISSN, &j.Publisher, &j.Description, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan journal"})
+			return
+		}
+		journals = append(journals, j)
+	}
+
+	c.JSON(http.StatusOK, journals)
+}
+
+func (s *Server) CreateJournal(c *gin.Context) {
+	var req models.CreateJournalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		INSERT INTO journals (name, issn, publisher, description, tenant_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, name, COALESCE(issn, ''), COALESCE(publisher, ''), COALESCE(description, ''), created_at, updated_at
+	`
+
+	var j models.Journal
+	err := s.db.Pool.QueryRow(ctx, query, req.Name, req.ISSN, req.Publisher, req.Description, tenantID(c)).Scan(
+		&j.ID, &j.Name, &j.ISSN, &j.Publisher, &j.Description, &j.CreatedAt, &j.UpdatedAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create journal"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, j)
+}
+
+func (s *Server) UpdateJournal(c *gin.Context) {
+	journalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journal ID"})
+		return
+	}
+
+	var req models.CreateJournalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		UPDATE journals
+		SET name = $1, issn = $2, publisher = $3, description = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING id, name, COALESCE(issn, ''), COALESCE(publisher, ''), COALESCE(description, ''), created_at, updated_at
+	`
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update journal"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var j models.Journal
+	err = tx.QueryRow(ctx, query, req.Name, req.ISSN, req.Publisher, req.Description, journalID).Scan(
+		&j.ID, &j.Name, &j.ISSN, &j.Publisher, &j.Description, &j.CreatedAt, &j.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journal not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update journal"})
+		return
+	}
+
+	// Keep the journal name on already placed papers in sync
+	if _, err := tx.Exec(ctx, `
+		UPDATE papers SET journal_name = $1, updated_at = NOW()
+		WHERE issue_id IN (
+			SELECT i.id FROM journal_issues i JOIN journal_volumes v ON i.volume_id = v.id WHERE v.journal_id = $2
+		)
+	`, j.Name, j.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update papers of the journal"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update journal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, j)
+}
+
+func (s *Server) DeleteJournal(c *gin.Context) {
+	journalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journal ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	result, err := s.db.Pool.Exec(ctx, "DELETE FROM journals WHERE id = $1", journalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete journal"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journal not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Journal deleted successfully"})
+}
+
+func (s *Server) GetVolumes(c *gin.Context) {
+	journalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journal ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		SELECT id, journal_id, number, year, COALESCE(title, ''), created_at
+		FROM journal_volumes
+		WHERE journal_id = $1
+		ORDER BY number DESC
+	`
+
+	rows, err := s.db.Pool.Query(ctx, query, journalID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch volumes"})
+		return
+	}
+	defer rows.Close()
+
+	volumes := []models.JournalVolume{}
+	for rows.Next() {
+		var v models.JournalVolume
+		if err := rows.Scan(&v.ID, &v.JournalID, &v.Number, &v.Year, &v.Title, &v.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan volume"})
+			return
+		}
+		volumes = append(volumes, v)
+	}
+
+	c.JSON(http.StatusOK, volumes)
+}
+
+func (s *Server) CreateVolume(c *gin.Context) {
+	journalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid journal ID"})
+		return
+	}
+
+	var req models.CreateVolumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		INSERT INTO journal_volumes (journal_id, number, year, title)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, journal_id, number, year, COALESCE(title, ''), created_at
+	`
+
+	var v models.JournalVolume
+	err = s.db.Pool.QueryRow(ctx, query, journalID, req.Number, req.Year, req.Title).Scan(
+		&v.ID, &v.JournalID, &v.Number, &v.Year, &v.Title, &v.CreatedAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create volume"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, v)
+}
+
+func (s *Server) GetIssues(c *gin.Context) {
+	volumeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid volume ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		SELECT id, volume_id, number, COALESCE(title, ''), publication_date, created_at, updated_at
+		FROM journal_issues
+		WHERE volume_id = $1
+		ORDER BY number ASC
+	`
+
+	rows, err := s.db.Pool.Query(ctx, query, volumeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch issues"})
+		return
+	}
+	defer rows.Close()
+
+	issues := []models.JournalIssue{}
+	for rows.Next() {
+		var i models.JournalIssue
+		if err := rows.Scan(&i.ID, &i.VolumeID, &i.Number, &i.Title, &i.PublicationDate, &i.CreatedAt, &i.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan issue"})
+			return
+		}
+		issues = append(issues, i)
+	}
+
+	c.JSON(http.StatusOK, issues)
+}
+
+func (s *Server) CreateIssue(c *gin.Context) {
+	volumeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid volume ID"})
+		return
+	}
+
+	var req models.CreateIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		INSERT INTO journal_issues (volume_id, number, title, publication_date)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, volume_id, number, COALESCE(title, ''), publication_date, created_at, updated_at
+	`
+
+	var i models.JournalIssue
+	err = s.db.Pool.QueryRow(ctx, query, volumeID, req.Number, req.Title, req.PublicationDate).Scan(
+		&i.ID, &i.VolumeID, &i.Number, &i.Title, &i.PublicationDate, &i.CreatedAt, &i.UpdatedAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create issue"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, i)
+}
+
+// UpdateIssue updates an issue and propagates its publication date to the papers it contains
+func (s *Server) UpdateIssue(c *gin.Context) {
+	issueID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid issue ID"})
+		return
+	}
+
+	var req models.CreateIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update issue"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE journal_issues
+		SET number = $1, title = $2, publication_date = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING id, volume_id, number, COALESCE(title, ''), publication_date, created_at, updated_at
+	`
+
+	var i models.JournalIssue
+	err = tx.QueryRow(ctx, query, req.Number, req.Title, req.PublicationDate, issueID).Scan(
+		&i.ID, &i.VolumeID, &i.Number, &i.Title, &i.PublicationDate, &i.CreatedAt, &i.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Issue not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update issue"})
+		return
+	}
+
+	if i.PublicationDate != nil {
+		_, err = tx.Exec(ctx,
+			"UPDATE papers SET publication_date = $1, updated_at = NOW() WHERE issue_id = $2",
+			i.PublicationDate, i.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update paper publication dates"})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, i)
+}
+
+func (s *Server) DeleteIssue(c *gin.Context) {
+	issueID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid issue ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	result, err := s.db.Pool.Exec(ctx, "DELETE FROM journal_issues WHERE id = $1", issueID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete issue"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Issue not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Issue deleted successfully"})
+}
+
+// AssignPaperToIssue places a published paper in an issue with its page range and position
+func (s *Server) AssignPaperToIssue(c *gin.Context) {
+	issueID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid issue ID"})
+		return
+	}
+
+	var req models.AssignIssuePaperRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.PageEnd < req.PageStart {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "page_end must not be before page_start"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign paper to issue"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx,
+		"SELECT status FROM papers WHERE id = $1 AND deleted_at IS NULL AND tenant_id = $2 FOR UPDATE",
+		req.PaperID, tenantID(c)).Scan(&status)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+	if status != "published" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only published papers can be assigned to an issue"})
+		return
+	}
+
+	// Locking the issue queues concurrent assignments to it, so each one checks the pages
+	// against papers the previous one placed
+	var journalName string
+	var publicationDate *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT j.name, i.publication_date
+		FROM journal_issues i
+		JOIN journal_volumes v ON i.volume_id = v.id
+		JOIN journals j ON v.journal_id = j.id
+		WHERE i.id = $1
+		FOR UPDATE OF i
+	`, issueID).Scan(&journalName, &publicationDate)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Issue not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch issue"})
+		return
+	}
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM papers WHERE issue_id = $1 FOR UPDATE", issueID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock issue papers"})
+		return
+	}
+
+	// Reject page ranges overlapping another paper in the same issue
+	var overlapping int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM papers
+		WHERE issue_id = $1 AND id != $2 AND page_start <= $4 AND page_end >= $3
+	`, issueID, req.PaperID, req.PageStart, req.PageEnd).Scan(&overlapping)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check page ranges"})
+		return
+	}
+	if overlapping > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Page range overlaps another paper in this issue"})
+		return
+	}
+
+	order := req.Order
+	if order <= 0 {
+		err = tx.QueryRow(ctx,
+			"SELECT COALESCE(MAX(issue_order), 0) + 1 FROM papers WHERE issue_id = $1 AND id != $2",
+			issueID, req.PaperID).Scan(&order)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to order paper in issue"})
+			return
+		}
+	}
+
+	query := `
+		UPDATE papers
+		SET issue_id = $1, page_start = $2, page_end = $3, issue_order = $4, journal_name = $5,
+			publication_date = COALESCE($6, publication_date), updated_at = NOW()
+		WHERE id = $7
+	`
+	_, err = tx.Exec(ctx, query, issueID, req.PageStart, req.PageEnd, order, journalName, publicationDate, req.PaperID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign paper to issue"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign paper to issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Paper assigned to issue", "paper_id": req.PaperID, "order": order})
+}
+
+func (s *Server) RemovePaperFromIssue(c *gin.Context) {
+	issueID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid issue ID"})
+		return
+	}
+
+	paperID, err := uuid.Parse(c.Param("paperId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	query := `
+		UPDATE papers
+		SET issue_id = NULL, page_start = NULL, page_end = NULL, issue_order = NULL, updated_at = NOW()
+		WHERE id = $1 AND issue_id = $2
+	`
+	_, err = s.db.Pool.Exec(ctx, query, paperID, issueID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove paper from issue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Paper removed from issue"})
+}
+
+// GetIssueTableOfContents generates the table of contents of an issue
+func (s *Server) GetIssueTableOfContents(c *gin.Context) {
+	issueID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid issue ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	toc, err := s.buildTableOfContents(ctx, issueID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Issue not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build table of contents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toc)
+}
+
+func (s *Server) buildTableOfContents(ctx context.Context, issueID uuid.UUID) (*models.TableOfContents, error) {
+	var toc models.TableOfContents
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT j.id, j.name, COALESCE(j.issn, ''), COALESCE(j.publisher, ''), COALESCE(j.description, ''), j.created_at, j.updated_at,
+			   v.id, v.journal_id, v.number, v.year, COALESCE(v.title, ''), v.created_at,
+			   i.id, i.volume_id, i.number, COALESCE(i.title, ''), i.publication_date, i.created_at, i.updated_at
+		FROM journal_issues i
+		JOIN journal_volumes v ON i.volume_id = v.id
+		JOIN journals j ON v.journal_id = j.id
+		WHERE i.id = $1
+	`, issueID).Scan(
+		&toc.Journal.ID, &toc.Journal.Name, &toc.Journal.ISSN, &toc.Journal.Publisher, &toc.Journal.Description, &toc.Journal.CreatedAt, &toc.Journal.UpdatedAt,
+		&toc.Volume.ID, &toc.Volume.JournalID, &toc.Volume.Number, &toc.Volume.Year, &toc.Volume.Title, &toc.Volume.CreatedAt,
+		&toc.Issue.ID, &toc.Issue.VolumeID, &toc.Issue.Number, &toc.Issue.Title, &toc.Issue.PublicationDate, &toc.Issue.CreatedAt, &toc.Issue.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT COALESCE(p.issue_order, 0), p.id, p.title, COALESCE(u.name, 'Unknown'), COALESCE(p.publication_id, ''),
+			   COALESCE(p.page_start, 0), COALESCE(p.page_end, 0)
+		FROM papers p
+		LEFT JOIN users u ON p.author_id = u.id
+		WHERE p.issue_id = $1 AND p.status = 'published' AND p.deleted_at IS NULL
+		ORDER BY p.issue_order ASC, p.page_start ASC
+	`, issueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	toc.Entries = []models.TableOfContentsEntry{}
+	for rows.Next() {
+		var e models.TableOfContentsEntry
+		if err := rows.Scan(&e.Order, &e.PaperID, &e.Title, &e.AuthorName, &e.PublicationID, &e.PageStart, &e.PageEnd); err != nil {
+			return nil, err
+		}
+		toc.Entries = append(toc.Entries, e)
+	}
+
+	return &toc, rows.Err()
+}
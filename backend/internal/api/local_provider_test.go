@@ -0,0 +1,149 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/config"
+	"rpms-backend/internal/database"
+	"rpms-backend/internal/email"
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// insertLocalUser registers a user with the local provider and confirms the registration
+// with a code of the test's choosing, since the emailed one is never seen
+func insertLocalUser(t *testing.T, router *gin.Engine, db *database.Database, address, password, role string) string {
+	t.Helper()
+	w := postJSON(router, "/api/v1/auth/register", models.CreateUserRequest{
+		Name: "Local " + role, Email: address, Password: password, Role: "author",
+		AcademicYear: "2024", AuthorType: "Academic Staff", AuthorCategory: "Researcher",
+		AcademicRank: "Lecturer", Qualification: "PhD", EmploymentType: "Full Time", Gender: "Female",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register %s: %d %s", address, w.Code, w.Body.String())
+	}
+
+	ctx := context.Background()
+	if _, err := db.Pool.Exec(ctx, "UPDATE pending_registrations SET code_hash = $1 WHERE email = $2", auth.HashCode("424242"), address); err != nil {
+		t.Fatal(err)
+	}
+	w = postJSON(router, "/api/v1/auth/verify", models.VerifyEmailRequest{Email: address, Code: "424242"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify %s: %d %s", address, w.Code, w.Body.String())
+	}
+
+	var userID string
+	if err := db.Pool.QueryRow(ctx, "SELECT id FROM users WHERE email = $1", address).Scan(&userID); err != nil {
+		t.Fatalf("find %s: %v", address, err)
+	}
+	if role != "author" {
+		if _, err := db.Pool.Exec(ctx, "UPDATE users SET role = $1 WHERE id = $2", role, userID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return userID
+}
+
+func TestLocalProviderSignIn(t *testing.T) {
+	t.Setenv("AUTH_PROVIDER", "local")
+	router, db := setupTestServer(t)
+	defer db.Close()
+	provider := auth.NewLocalProvider(db, email.NewEmailSender(config.New()))
+	ctx := context.Background()
+
+	address := fmt.Sprintf("local_%d@test.com", time.Now().UnixNano())
+	userID := insertLocalUser(t, router, db, address, "password123", "author")
+	defer db.Pool.Exec(ctx, "DELETE FROM users WHERE email = $1", address)
+
+	// An account created through single sign-on has no password to check
+	ssoAddress := "sso_" + address
+	ssoUserID := insertLocalUser(t, router, db, ssoAddress, "password123", "author")
+	if _, err := db.Pool.Exec(ctx, "UPDATE users SET password_hash = '' WHERE id = $1", ssoUserID); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Pool.Exec(ctx, "DELETE FROM users WHERE email = $1", ssoAddress)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{"correct password", address, "password123", nil},
+		{"email in another case with spaces", "  " + strings.ToUpper(address) + " ", "password123", nil},
+		{"password mismatch", address, "password124", auth.ErrInvalidCredentials},
+		{"empty password", address, "", auth.ErrInvalidCredentials},
+		{"unknown email", "nobody_" + address, "password123", auth.ErrInvalidCredentials},
+		{"account without password", ssoAddress, "password123", auth.ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := provider.SignIn(ctx, tt.email, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SignIn error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (identity.ID.String() != userID || identity.Email != address) {
+				t.Errorf("signed in as %s %s, want %s %s", identity.ID, identity.Email, userID, address)
+			}
+		})
+	}
+}
+
+func TestLocalProviderAccountLifecycle(t *testing.T) {
+	t.Setenv("AUTH_PROVIDER", "local")
+	router, db := setupTestServer(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	adminEmail := fmt.Sprintf("local_admin_%d@test.com", suffix)
+	insertLocalUser(t, router, db, adminEmail, "password123", "admin")
+	defer db.Pool.Exec(ctx, "DELETE FROM users WHERE email = $1", adminEmail)
+	admin := login(t, router, adminEmail, "password123")
+
+	address := fmt.Sprintf("local_user_%d@test.com", suffix)
+	userID := insertLocalUser(t, router, db, address, "password123", "author")
+	defer db.Pool.Exec(ctx, "DELETE FROM users WHERE email = $1", address)
+
+	adminRequest := func(method, path string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+admin.Token)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	signIn := func() int {
+		return postJSON(router, "/api/v1/auth/login", map[string]string{"email": address, "password": "password123"}).Code
+	}
+
+	steps := []struct {
+		name       string
+		method     string
+		path       string
+		wantSignIn int
+	}{
+		{"active", "", "", http.StatusOK},
+		{"disabled", "POST", "/api/v1/admin/users/" + userID + "/deactivate", http.StatusForbidden},
+		{"enabled again", "POST", "/api/v1/admin/users/" + userID + "/reactivate", http.StatusOK},
+		{"deleted", "DELETE", "/api/v1/admin/users/" + userID, http.StatusUnauthorized},
+	}
+	for _, step := range steps {
+		if step.method != "" {
+			if code := adminRequest(step.method, step.path); code != http.StatusOK {
+				t.Fatalf("%s: %s %s returned %d", step.name, step.method, step.path, code)
+			}
+		}
+		if code := signIn(); code != step.wantSignIn {
+			t.Errorf("%s: sign in returned %d, want %d", step.name, code, step.wantSignIn)
+		}
+	}
+}
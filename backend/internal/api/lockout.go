@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// recordLoginAttempt writes the audit entry for a sign in attempt. It runs in the
+// background and never fails the request.
+func (s *Server) recordLoginAttempt(c *gin.Context, email string, userID *uuid.UUID, outcome string) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	ip := c.ClientIP()
+	userAgent := c.Request.UserAgent()
+	go func() {
+		ctx := context.Background()
+		if userID == nil {
+			var id uuid.UUID
+			if err := s.db.Pool.QueryRow(ctx, "SELECT id FROM users WHERE LOWER(email) = $1 LIMIT 1", email).Scan(&id); err == nil {
+				userID = &id
+			}
+		}
+		if _, err := s.db.Pool.Exec(ctx, `
+			INSERT INTO login_attempts (email, user_id, ip_address, user_agent, outcome)
+			VALUES ($1, $2, $3, $4, $5)
+		`, email, userID, ip, userAgent, outcome); err != nil {
+			fmt.Printf("Failed to record login attempt for %s: %v\n", email, err)
+		}
+	}()
+}
+
+// allowLogin refuses a sign in attempt while the account or the client address is backing
+// off or locked out. It writes the response when refusing.
+func (s *Server) allowLogin(c *gin.Context, email string) bool {
+	ctx := c.Request.Context()
+	now := time.Now()
+
+	account, err := s.loginThrottle.State(ctx, accountThrottleKey(email))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check sign in attempts"})
+		return false
+	}
+	ip, err := s.loginThrottle.State(ctx, ipThrottleKey(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check sign in attempts"})
+		return false
+	}
+
+	wait := auth.AccountLockout.RetryAfter(account, now)
+	if ipWait := auth.IPLockout.RetryAfter(ip, now); ipWait > wait {
+		wait = ipWait
+	}
+	if wait == 0 {
+		return true
+	}
+
+	locked := now.Before(account.LockedUntil) || now.Before(ip.LockedUntil)
+	outcome := models.LoginThrottled
+	message := fmt.Sprintf("Too many failed sign in attempts. Try again in %d seconds.", int(wait.Seconds())+1)
+	if locked {
+		outcome = models.LoginLocked
+		message = fmt.Sprintf("Sign in is temporarily locked after repeated failures. Try again in %d minutes.", int(wait.Minutes())+1)
+	}
+	s.recordLoginAttempt(c, email, nil, outcome)
+
+	c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": message, "locked": locked})
+	return false
+}
+
+// loginFailed counts a failed sign in against the account and the client address, and
+// tells the account owner when it gets locked
+func (s *Server) loginFailed(c *gin.Context, email string) {
+	ctx := c.Request.Context()
+	s.recordLoginAttempt(c, email, nil, models.LoginInvalidCredentials)
+
+	if _, _, err := s.loginThrottle.Fail(ctx, ipThrottleKey(c), auth.IPLockout); err != nil {
+		fmt.Printf("Failed to record failed sign in from %s: %v\n", c.ClientIP(), err)
+	}
+	st, locked, err := s.loginThrottle.Fail(ctx, accountThrottleKey(email), auth.AccountLockout)
+	if err != nil {
+		fmt.Printf("Failed to record failed sign in for %s: %v\n", email, err)
+		return
+	}
+	if !locked {
+		return
+	}
+
+	ip := c.ClientIP()
+	go func() {
+		message := fmt.Sprintf(
+			"Sign in to your account was locked until %s after %d failed attempts (last from %s). If this was not you, consider changing your password.",
+			st.LockedUntil.Format("2006-01-02 15:04 MST"), st.Failures, ip)
+		s.db.Pool.Exec(context.Background(), `
+			INSERT INTO notifications (user_id, message)
+			SELECT id, $1 FROM users WHERE LOWER(email) = LOWER($2)
+		`, message, email)
+	}()
+}
+
+// loginSucceeded clears the account's failure history
+func (s *Server) loginSucceeded(c *gin.Context, user *models.User) {
+	s.recordLoginAttempt(c, user.Email, &user.ID, models.LoginSucceeded)
+	if err := s.loginThrottle.Reset(c.Request.Context(), accountThrottleKey(user.Email)); err != nil {
+		fmt.Printf("Failed to reset sign in failures for %s: %v\n", user.Email, err)
+	}
+}
+
+// userEmail returns the email of a user of the admin's tenant, writing the response if missing
+func (s *Server) userEmail(c *gin.Context) (uuid.UUID, string, bool) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, "", false
+	}
+	var email string
+	err = s.db.Pool.QueryRow(c.Request.Context(),
+		"SELECT email FROM users WHERE id = $1 AND tenant_id = $2", userID, tenantID(c)).Scan(&email)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return uuid.Nil, "", false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return uuid.Nil, "", false
+	}
+	return userID, email, true
+}
+
+// GetUserLoginAttempts returns a user's lockout state and recent sign in attempts
+func (s *Server) GetUserLoginAttempts(c *gin.Context) {
+	_, email, ok := s.userEmail(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	st, err := s.loginThrottle.State(ctx, accountThrottleKey(email))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockout state"})
+		return
+	}
+	status := models.LoginLockStatus{FailedAttempts: st.Failures, Attempts: []models.LoginAttempt{}}
+	if time.Now().Before(st.LockedUntil) {
+		status.Locked = true
+		status.LockedUntil = &st.LockedUntil
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, email, user_id, COALESCE(ip_address, ''), COALESCE(user_agent, ''), outcome, created_at
+		FROM login_attempts
+		WHERE email = LOWER($1)
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, email, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch login attempts"})
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a models.LoginAttempt
+		if err := rows.Scan(&a.ID, &a.Email, &a.UserID, &a.IPAddress, &a.UserAgent, &a.Outcome, &a.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan login attempt"})
+			return
+		}
+		status.Attempts = append(status.Attempts, a)
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// UnlockUser lifts a sign in lockout and clears the account's failure history
+func (s *Server) UnlockUser(c *gin.Context) {
+	userID, email, ok := s.userEmail(c)
+	if !ok {
+		return
+	}
+
+	if err := s.loginThrottle.Reset(c.Request.Context(), accountThrottleKey(email)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+		return
+	}
+
+	go func() {
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message) VALUES ($1, $2)",
+			userID, "Your account was unlocked by an administrator. You can sign in again.")
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
+}
@@ -0,0 +1,457 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// userTenant returns the tenant an account belongs to, which is not necessarily the one
+// serving the request
+func (s *Server) userTenant(c *gin.Context, id uuid.UUID) *tenant.Tenant {
+	if s.tenants != nil {
+		if t, err := s.tenants.ByID(c.Request.Context(), id); err == nil {
+			return t
+		}
+	}
+	return currentTenant(c)
+}
+
+// completeSignIn answers a successful password or email check: with a session, or with a
+// pre-auth token when the account has 2FA or its role requires it
+func (s *Server) completeSignIn(c *gin.Context, user *models.User) {
+	ctx := c.Request.Context()
+
+	var enabled bool
+	if err := s.db.Pool.QueryRow(ctx, "SELECT totp_enabled FROM users WHERE id = $1", user.ID).Scan(&enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check two-factor authentication"})
+		return
+	}
+
+	scope := ""
+	if enabled {
+		scope = auth.ScopeMFAVerify
+	} else if s.userTenant(c, user.TenantID).Config.RequiresMFA(user.Role) {
+		scope = auth.ScopeMFAEnroll
+	}
+	if scope != "" {
+		token, err := s.jwtManager.GeneratePreAuthToken(user, scope)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(http.StatusOK, models.MFAChallenge{
+			MFARequired:        true,
+			EnrollmentRequired: scope == auth.ScopeMFAEnroll,
+			MFAToken:           token,
+			ExpiresIn:          int(auth.PreAuthExpiry.Seconds()),
+		})
+		return
+	}
+
+	response, err := s.signIn(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// checkTOTP validates an authenticator code for the user and records its time step so
+// the same code cannot be used twice
+func (s *Server) checkTOTP(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	var secret string
+	var lastStep int64
+	err := s.db.Pool.QueryRow(ctx,
+		"SELECT COALESCE(totp_secret, ''), totp_last_step FROM users WHERE id = $1", userID).Scan(&secret, &lastStep)
+	if err != nil {
+		return false, err
+	}
+	if secret == "" {
+		return false, nil
+	}
+
+	step, ok := auth.ValidateTOTP(secret, code, time.Now(), lastStep)
+	if !ok {
+		return false, nil
+	}
+	// Another request may have used the same code in the meantime
+	tag, err := s.db.Pool.Exec(ctx,
+		"UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1", step, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// useRecoveryCode spends one of the user's recovery codes
+func (s *Server) useRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	tag, err := s.db.Pool.Exec(ctx, `
+		UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, auth.HashCode(auth.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// checkSecondFactor accepts an authenticator code or a recovery code, rate limited per user
+func (s *Server) checkSecondFactor(c *gin.Context, userID uuid.UUID, code, recoveryCode string) bool {
+	key := "mfa:" + userID.String()
+	if !s.mfaLimiter.Allow(key) {
+		rejectRateLimited(c, s.mfaLimiter.RetryAfter(key))
+		return false
+	}
+
+	var ok bool
+	var err error
+	switch {
+	case strings.TrimSpace(code) != "":
+		ok, err = s.checkTOTP(c.Request.Context(), userID, code)
+	case strings.TrimSpace(recoveryCode) != "":
+		ok, err = s.useRecoveryCode(c.Request.Context(), userID, recoveryCode)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A code or a recovery code is required"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check code"})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return false
+	}
+	return true
+}
+
+// replaceRecoveryCodes issues a fresh set of recovery codes, invalidating the old ones
+func (s *Server) replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID) ([]string, error) {
+	codes, err := auth.NewRecoveryCodes(models.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if _, err := tx.Exec(ctx,
+			"INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, auth.HashCode(code)); err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+func contextUserID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// VerifyMFA finishes a sign in with the second factor
+func (s *Server) VerifyMFA(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+	if !s.checkSecondFactor(c, userID, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := s.sessionUser(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	response, err := s.signIn(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMFAStatus reports whether the current user has 2FA and how many recovery codes remain
+func (s *Server) GetMFAStatus(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	var status models.MFAStatus
+	var tenantID uuid.UUID
+	err := s.db.Pool.QueryRow(c.Request.Context(), `
+		SELECT u.totp_enabled, u.totp_enabled_at, u.tenant_id,
+			(SELECT COUNT(*) FROM mfa_recovery_codes r WHERE r.user_id = u.id AND r.used_at IS NULL)
+		FROM users u WHERE u.id = $1
+	`, userID).Scan(&status.Enabled, &status.EnabledAt, &tenantID, &status.RecoveryCodesRemaining)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor status"})
+		return
+	}
+	status.Required = s.userTenant(c, tenantID).Config.RequiresMFA(c.GetString("role"))
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SetupMFA generates a new authenticator secret. It takes effect once EnableMFA confirms a code.
+func (s *Server) SetupMFA(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var email string
+	var enabled bool
+	var tenantID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx,
+		"SELECT email, totp_enabled, tenant_id FROM users WHERE id = $1", userID).Scan(&email, &enabled, &tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	if _, err := s.db.Pool.Exec(ctx,
+		"UPDATE users SET totp_secret = $1, totp_last_step = 0 WHERE id = $2", secret, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save secret"})
+		return
+	}
+
+	issuer := "RPMS " + s.userTenant(c, tenantID).DisplayName()
+	c.JSON(http.StatusOK, models.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(issuer, email, secret),
+	})
+}
+
+// EnableMFA turns 2FA on once the user proves their authenticator works, and returns the
+// recovery codes. When called with an enrollment pre-auth token it also completes the sign in.
+func (s *Server) EnableMFA(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var enabled bool
+	if err := s.db.Pool.QueryRow(ctx, "SELECT totp_enabled FROM users WHERE id = $1", userID).Scan(&enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if !s.checkSecondFactor(c, userID, req.Code, "") {
+		return
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE users SET totp_enabled = TRUE, totp_enabled_at = NOW() WHERE id = $1", userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+	codes, err := s.replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recovery codes"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	response := models.MFAEnableResponse{RecoveryCodes: codes}
+	if c.GetString("token_scope") == auth.ScopeMFAEnroll {
+		user, err := s.sessionUser(ctx, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return
+		}
+		if response.Session, err = s.signIn(c, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// clearMFA removes a user's authenticator secret and recovery codes
+func (s *Server) clearMFA(ctx context.Context, userID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_enabled_at = NULL, totp_last_step = 0
+		WHERE id = $1
+	`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DisableMFA turns 2FA off after checking a current code, unless the user's role requires it
+func (s *Server) DisableMFA(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+	if currentTenant(c).Config.RequiresMFA(c.GetString("role")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role"})
+		return
+	}
+	if !s.checkSecondFactor(c, userID, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	if err := s.clearMFA(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a current code
+func (s *Server) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	var enabled bool
+	if err := s.db.Pool.QueryRow(ctx, "SELECT totp_enabled FROM users WHERE id = $1", userID).Scan(&enabled); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	if !enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if !s.checkSecondFactor(c, userID, req.Code, "") {
+		return
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	codes, err := s.replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recovery codes"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MFAEnableResponse{RecoveryCodes: codes})
+}
+
+// AdminResetMFA removes a user's 2FA, e.g. after they lost their device and recovery codes,
+// and signs them out everywhere
+func (s *Server) AdminResetMFA(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var enabled bool
+	err = s.db.Pool.QueryRow(ctx, "SELECT totp_enabled FROM users WHERE id = $1", userID).Scan(&enabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	if err := s.clearMFA(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication"})
+		return
+	}
+	if err := s.tokens.RevokeUser(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end the user's sessions"})
+		return
+	}
+
+	go func() {
+		message := "Your two-factor authentication was reset by an administrator. Please set it up again."
+		if !enabled {
+			message = "Your pending two-factor authentication setup was cleared by an administrator."
+		}
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message) VALUES ($1, $2)", userID, message)
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Two-factor authentication reset for user %s", userID)})
+}
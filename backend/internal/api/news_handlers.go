@@ -6,30 +6,23 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
 )
 
 func (s *Server) GetNews(c *gin.Context) {
 	status := c.Query("status")
 
 	ctx := c.Request.Context()
-	query := `SELECT id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at FROM news`
+	query := `SELECT id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at, publish_at FROM news WHERE tenant_id = $1`
+	args := []interface{}{tenantID(c)}
 
 	if status != "" {
-		query += ` WHERE status = $1`
+		query += ` AND status = $2`
+		args = append(args, status)
 	}
 
 	query += ` ORDER BY created_at DESC`
 
-	var rows pgx.Rows
-	var err error
-
-	if status != "" {
-		rows, err = s.db.Pool.Query(ctx, query, status)
-	} else {
-		rows, err = s.db.Pool.Query(ctx, query)
-	}
-
+	rows, err := s.db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch news"})
 		return
@@ -41,7 +34,7 @@ func (s *Server) GetNews(c *gin.Context) {
 		var news models.News
 		err := rows.Scan(
 			&news.ID, &news.Title, &news.Summary, &news.Content, &news.Category, &news.Status,
-			&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt,
+			&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt, &news.PublishAt,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan news"})
@@ -82,12 +75,12 @@ func (s *Server) CreateNews(c *gin.Context) {
 	query := `
 		INSERT INTO news (title, summary, content, category, status, image_url, video_url, editor_id)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at
+		RETURNING id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at, publish_at
 	`
 
 	err = s.db.Pool.QueryRow(ctx, query, news.Title, news.Summary, news.Content, news.Category, news.Status, news.ImageURL, news.VideoURL, news.EditorID).Scan(
 		&news.ID, &news.Title, &news.Summary, &news.Content, &news.Category, &news.Status,
-		&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt,
+		&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt, &news.PublishAt,
 	)
 
 	if err != nil {
@@ -116,13 +109,13 @@ func (s *Server) UpdateNews(c *gin.Context) {
 		UPDATE news
 		SET title = $1, summary = $2, content = $3, category = $4, image_url = $5, video_url = $6, updated_at = NOW()
 		WHERE id = $7
-		RETURNING id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at
+		RETURNING id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at, publish_at
 	`
 
 	var news models.News
 	err = s.db.Pool.QueryRow(ctx, query, req.Title, req.Summary, req.Content, req.Category, req.ImageURL, req.VideoURL, newsID).Scan(
 		&news.ID, &news.Title, &news.Summary, &news.Content, &news.Category, &news.Status,
-		&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt,
+		&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt, &news.PublishAt,
 	)
 
 	if err != nil {
@@ -162,15 +155,15 @@ func (s *Server) PublishNews(c *gin.Context) {
 	ctx := c.Request.Context()
 	query := `
 		UPDATE news
-		SET status = 'published', updated_at = NOW()
+		SET status = 'published', publish_at = NOW(), updated_at = NOW()
 		WHERE id = $1
-		RETURNING id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at
+		RETURNING id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at, publish_at
 	`
 
 	var news models.News
 	err = s.db.Pool.QueryRow(ctx, query, newsID).Scan(
 		&news.ID, &news.Title, &news.Summary, &news.Content, &news.Category, &news.Status,
-		&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt,
+		&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt, &news.PublishAt,
 	)
 
 	if err != nil {
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// rejectRateLimited answers 429 with a Retry-After header
+func rejectRateLimited(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+}
+
+// ForgotPassword emails a password reset token. It answers the same way whether or not the
+// email is registered, so it cannot be used to discover accounts.
+func (s *Server) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ipKey := "ip:" + c.ClientIP()
+	emailKey := "email:" + strings.ToLower(strings.TrimSpace(req.Email))
+	if !s.resetLimiter.Allow(ipKey) {
+		rejectRateLimited(c, s.resetLimiter.RetryAfter(ipKey))
+		return
+	}
+	if !s.resetLimiter.Allow(emailKey) {
+		rejectRateLimited(c, s.resetLimiter.RetryAfter(emailKey))
+		return
+	}
+
+	// Looking the account up and emailing happen in the background so the response time
+	// does not reveal whether the account exists
+	tenant := tenantID(c)
+	fromHost := c.GetBool("tenant_from_host")
+	ip := c.ClientIP()
+	go func() {
+		ctx := context.Background()
+
+		var userID, userTenant uuid.UUID
+		var email string
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT id, email, tenant_id FROM users
+			WHERE LOWER(email) = LOWER($1)
+			ORDER BY (tenant_id = $2) DESC
+			LIMIT 1
+		`, req.Email, tenant).Scan(&userID, &email, &userTenant)
+		if err != nil || (fromHost && userTenant != tenant) {
+			return
+		}
+
+		token, err := s.tokens.IssuePasswordReset(ctx, userID, ip)
+		if err != nil {
+			fmt.Printf("Failed to issue password reset for %s: %v\n", email, err)
+			return
+		}
+		if err := s.emailSender.SendPasswordResetEmail(email, token); err != nil {
+			fmt.Printf("Failed to send password reset email to %s: %v\n", email, err)
+		}
+	}()
+
+	c.JSON(http.StatusOK, gin.H{"message": "If an account exists for this email, a password reset link has been sent."})
+}
+
+// ResetPassword sets a new password with a reset token and signs the user out everywhere
+func (s *Server) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ipKey := "ip:" + c.ClientIP()
+	if !s.resetLimiter.Allow(ipKey) {
+		rejectRateLimited(c, s.resetLimiter.RetryAfter(ipKey))
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	userID, err := s.tokens.ConsumePasswordReset(ctx, tx, req.Token)
+	if errors.Is(err, auth.ErrInvalidResetToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check reset token"})
+		return
+	}
+
+	newHash, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash new password"})
+		return
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2", newHash, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+	// The identity provider goes last so a failure there leaves the token usable for a retry
+	if err := s.identity.UpdatePassword(ctx, userID, req.NewPassword); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	if err := s.tokens.RevokeUser(ctx, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password updated, but failed to end existing sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset. Please sign in with your new password."})
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// publicPaperSelect selects only the fields of a paper that may be shown publicly
+const publicPaperSelect = `
+	SELECT p.id, p.title, COALESCE(p.publication_title_amharic, ''), COALESCE(p.abstract, ''), COALESCE(p.type, 'Research Paper'),
+		   COALESCE(u.name, 'Unknown'), COALESCE(p.co_investigators, ''), COALESCE(p.publication_id, ''), p.publication_date,
+		   EXTRACT(YEAR FROM COALESCE(p.publication_date, p.created_at))::int,
+		   COALESCE(p.publication_isced_band, ''), COALESCE(p.publication_type, ''), COALESCE(p.journal_name, ''),
+		   p.issue_id, COALESCE(p.page_start, 0), COALESCE(p.page_end, 0), p.embargo_until, COALESCE(p.file_url, '')
+	FROM papers p
+	LEFT JOIN users u ON p.author_id = u.id
+`
+
+const publicPaperColumns = publicPaperSelect + `
+	WHERE p.status = 'published' AND p.deleted_at IS NULL
+`
+
+func scanPublicPaper(row pgx.Row, paper *models.PublicPaper, fileURL *string) error {
+	err := row.Scan(
+		&paper.ID, &paper.Title, &paper.TitleAmharic, &paper.Abstract, &paper.Type,
+		&paper.AuthorName, &paper.CoInvestigators, &paper.PublicationID, &paper.PublicationDate,
+		&paper.Year, &paper.ISCEDBand, &paper.PublicationType, &paper.JournalName,
+		&paper.IssueID, &paper.PageStart, &paper.PageEnd, &paper.EmbargoUntil, fileURL,
+	)
+	if err != nil {
+		return err
+	}
+
+	paper.Slug = models.PaperSlug(paper.ID, paper.Title)
+	paper.Embargoed = paper.EmbargoUntil != nil && time.Now().Before(*paper.EmbargoUntil)
+	if *fileURL != "" && !paper.Embargoed {
+		paper.DownloadURL = fmt.Sprintf("/api/v1/public/papers/%s/download", paper.Slug)
+	}
+	return nil
+}
+
+// catalogFilters turns the browse query parameters into SQL conditions, always limited to the request's tenant
+func catalogFilters(c *gin.Context) (string, []interface{}, error) {
+	var where strings.Builder
+	args := []interface{}{tenantID(c)}
+	where.WriteString(" AND p.tenant_id = $1")
+
+	if year := c.Query("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid year")
+		}
+		args = append(args, y)
+		fmt.Fprintf(&where, " AND EXTRACT(YEAR FROM COALESCE(p.publication_date, p.created_at)) = $%d", len(args))
+	}
+	if band := c.Query("isced_band"); band != "" {
+		args = append(args, band)
+		fmt.Fprintf(&where, " AND p.publication_isced_band = $%d", len(args))
+	}
+	if journal := c.Query("journal"); journal != "" {
+		args = append(args, journal)
+		fmt.Fprintf(&where, " AND LOWER(p.journal_name) = LOWER($%d)", len(args))
+	}
+	if paperType := c.Query("type"); paperType != "" {
+		args = append(args, paperType)
+		fmt.Fprintf(&where, " AND p.type = $%d", len(args))
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		args = append(args, "%"+q+"%")
+		fmt.Fprintf(&where, " AND (p.title ILIKE $%d OR p.abstract ILIKE $%d OR u.name ILIKE $%d)", len(args), len(args), len(args))
+	}
+
+	return where.String(), args, nil
+}
+
+// GetPublicPapers lists published papers for the public catalog
+func (s *Server) GetPublicPapers(c *gin.Context) {
+	filters, args, err := catalogFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ctx := c.Request.Context()
+	result := models.PublicCatalogPage{Papers: []models.PublicPaper{}, Page: page, Limit: limit}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM papers p
+		LEFT JOIN users u ON p.author_id = u.id
+		WHERE p.status = 'published' AND p.deleted_at IS NULL`+filters, args...).Scan(&result.Total)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch papers"})
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	query := publicPaperColumns + filters + fmt.Sprintf(
+		" ORDER BY COALESCE(p.publication_date, p.created_at) DESC, p.title ASC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch papers"})
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var paper models.PublicPaper
+		var fileURL string
+		if err := scanPublicPaper(rows, &paper, &fileURL); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan paper"})
+			return
+		}
+		result.Papers = append(result.Papers, paper)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// findPublicPaper resolves a slug (or a bare paper ID) to a published paper
+func (s *Server) findPublicPaper(c *gin.Context) (*models.PublicPaper, string, error) {
+	slug := c.Param("slug")
+	ctx := c.Request.Context()
+
+	var paper models.PublicPaper
+	var fileURL string
+
+	if id, err := uuid.Parse(slug); err == nil {
+		err = scanPublicPaper(s.db.Pool.QueryRow(ctx, publicPaperColumns+" AND p.id = $1 AND p.tenant_id = $2", id, tenantID(c)), &paper, &fileURL)
+		return &paper, fileURL, err
+	}
+
+	prefix := models.SlugIDPrefix(slug)
+	if len(prefix) != 8 || strings.Trim(strings.ToLower(prefix), "0123456789abcdef") != "" {
+		return nil, "", pgx.ErrNoRows
+	}
+
+	rows, err := s.db.Pool.Query(ctx, publicPaperColumns+" AND p.id::text LIKE $1 AND p.tenant_id = $2", strings.ToLower(prefix)+"%", tenantID(c))
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var candidate models.PublicPaper
+		var candidateURL string
+		if err := scanPublicPaper(rows, &candidate, &candidateURL); err != nil {
+			return nil, "", err
+		}
+		// Prefer an exact slug match if the short ID prefix is ever shared
+		if !found || candidate.Slug == slug {
+			paper, fileURL, found = candidate, candidateURL, true
+		}
+	}
+	if !found {
+		return nil, "", pgx.ErrNoRows
+	}
+	return &paper, fileURL, nil
+}
+
+// GetPublicPaper returns a single published paper by its slug
+func (s *Server) GetPublicPaper(c *gin.Context) {
+	paper, _, err := s.findPublicPaper(c)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+
+	c.JSON(http.StatusOK, paper)
+}
+
+// DownloadPublicPaper redirects to the manuscript of a published, non-embargoed paper
+func (s *Server) DownloadPublicPaper(c *gin.Context) {
+	paper, fileURL, err := s.findPublicPaper(c)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+	if paper.Embargoed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "The manuscript is under embargo", "embargo_until": paper.EmbargoUntil})
+		return
+	}
+	if fileURL == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No manuscript is available for this paper"})
+		return
+	}
+
+	c.Redirect(http.StatusFound, fileURL)
+}
+
+// GetPublicCatalogFacets returns the years, ISCED bands and journals to browse the catalog by
+func (s *Server) GetPublicCatalogFacets(c *gin.Context) {
+	ctx := c.Request.Context()
+	facets := models.CatalogFacets{}
+
+	queries := []struct {
+		expr   string
+		order  string
+		target *[]models.CatalogFacet
+	}{
+		{"EXTRACT(YEAR FROM COALESCE(p.publication_date, p.created_at))::int::text", "1 DESC", &facets.Years},
+		{"p.publication_isced_band", "1 ASC", &facets.ISCEDBands},
+		{"p.journal_name", "2 DESC, 1 ASC", &facets.Journals},
+	}
+
+	for _, q := range queries {
+		rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+			SELECT %s, COUNT(*) FROM papers p
+			WHERE p.status = 'published' AND p.deleted_at IS NULL AND p.tenant_id = $1 AND COALESCE(%s, '') != ''
+			GROUP BY 1 ORDER BY %s
+		`, q.expr, q.expr, q.order), tenantID(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch catalog facets"})
+			return
+		}
+
+		*q.target = []models.CatalogFacet{}
+		for rows.Next() {
+			var facet models.CatalogFacet
+			if err := rows.Scan(&facet.Value, &facet.Count); err != nil {
+				rows.Close()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan catalog facets"})
+				return
+			}
+			*q.target = append(*q.target, facet)
+		}
+		rows.Close()
+	}
+
+	c.JSON(http.StatusOK, facets)
+}
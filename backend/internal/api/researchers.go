@@ -0,0 +1,315 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// researcherWorks selects the papers a researcher authored or contributed to
+const researcherWorks = publicPaperSelect + `
+	WHERE p.deleted_at IS NULL
+	  AND (p.author_id = $1 OR EXISTS (SELECT 1 FROM paper_contributors pc WHERE pc.paper_id = p.id AND pc.user_id = $1))
+`
+
+// GetResearcherProfile returns a researcher's public profile, honouring their visibility settings
+func (s *Server) GetResearcherProfile(c *gin.Context) {
+	researcherID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid researcher ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var profile models.ResearcherProfile
+	var preferences map[string]interface{}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT id, name, COALESCE(academic_rank, ''), COALESCE(qualification, ''), COALESCE(bio, ''),
+			   COALESCE(avatar, ''), COALESCE(orcid, ''), preferences
+		FROM users
+		WHERE id = $1 AND tenant_id = $2
+	`, researcherID, tenantID(c)).Scan(
+		&profile.ID, &profile.Name, &profile.AcademicRank, &profile.Qualification, &profile.Bio,
+		&profile.Avatar, &profile.ORCID, &preferences,
+	)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Researcher not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch researcher"})
+		return
+	}
+
+	visibility := models.ProfileVisibilityFrom(preferences)
+	if !visibility.Public {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Researcher not found"})
+		return
+	}
+	if !visibility.ShowBio {
+		profile.Bio = ""
+	}
+	if !visibility.ShowORCID {
+		profile.ORCID = ""
+	}
+
+	if visibility.ShowPapers {
+		profile.Papers, err = s.researcherWorks(ctx, researcherID,
+			" AND p.status = 'published' AND p.type NOT ILIKE '%project%'")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch researcher papers"})
+			return
+		}
+	}
+
+	// Projects are approved rather than published, which is how the importer stores them too
+	if visibility.ShowProjects {
+		profile.Projects, err = s.researcherWorks(ctx, researcherID,
+			" AND p.status IN ('approved', 'published') AND p.type ILIKE '%project%'")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch researcher projects"})
+			return
+		}
+	}
+
+	if visibility.ShowMetrics {
+		profile.Metrics, err = s.researcherMetrics(ctx, researcherID, visibility.ShowFunding)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute researcher metrics"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (s *Server) researcherWorks(ctx context.Context, researcherID uuid.UUID, filter string) ([]models.PublicPaper, error) {
+	rows, err := s.db.Pool.Query(ctx, researcherWorks+filter+" ORDER BY COALESCE(p.publication_date, p.created_at) DESC", researcherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	works := []models.PublicPaper{}
+	for rows.Next() {
+		var paper models.PublicPaper
+		var fileURL string
+		if err := scanPublicPaper(rows, &paper, &fileURL); err != nil {
+			return nil, err
+		}
+		works = append(works, paper)
+	}
+	return works, rows.Err()
+}
+
+func (s *Server) researcherMetrics(ctx context.Context, researcherID uuid.UUID, withFunding bool) (*models.ResearcherMetrics, error) {
+	metrics := &models.ResearcherMetrics{PublicationsByYear: []models.YearCount{}}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT EXTRACT(YEAR FROM COALESCE(p.publication_date, p.created_at))::int, COUNT(*)
+		FROM papers p
+		WHERE p.deleted_at IS NULL AND p.status = 'published' AND p.type NOT ILIKE '%project%'
+		  AND (p.author_id = $1 OR EXISTS (SELECT 1 FROM paper_contributors pc WHERE pc.paper_id = p.id AND pc.user_id = $1))
+		GROUP BY 1
+		ORDER BY 1 ASC
+	`, researcherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var yc models.YearCount
+		if err := rows.Scan(&yc.Year, &yc.Count); err != nil {
+			return nil, err
+		}
+		metrics.PublicationsByYear = append(metrics.PublicationsByYear, yc)
+		metrics.TotalPublications += yc.Count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Projects led are the approved ones the researcher submitted as principal investigator
+	var funding float64
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(COALESCE(allocated_budget, 0) + COALESCE(external_budget, 0) + COALESCE(nrf_fund, 0)), 0)::float8
+		FROM papers
+		WHERE author_id = $1 AND deleted_at IS NULL AND status IN ('approved', 'published') AND type ILIKE '%project%'
+	`, researcherID).Scan(&metrics.ProjectsLed, &funding)
+	if err != nil {
+		return nil, err
+	}
+	if withFunding {
+		metrics.FundingAttracted = &funding
+	}
+
+	return metrics, nil
+}
+
+// GetPaperContributors lists the researchers credited on a paper besides its author
+func (s *Server) GetPaperContributors(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	// Visible to whoever may see the paper in GetPapers: its author, staff within their units,
+	// and everyone once it is published
+	ctx := c.Request.Context()
+	if s.can(c, rbac.PaperReadAll) {
+		if !s.requirePaperScope(c, paperID) {
+			return
+		}
+	} else {
+		var visible bool
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT p.author_id::text = $2 OR p.status = 'published'
+			FROM papers p WHERE p.id = $1 AND p.tenant_id = $3 AND p.deleted_at IS NULL
+		`, paperID, c.GetString("user_id"), tenantID(c)).Scan(&visible)
+		if err == pgx.ErrNoRows || (err == nil && !visible) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+			return
+		}
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT pc.paper_id, pc.user_id, COALESCE(u.name, 'Unknown'), pc.role, pc.created_at
+		FROM paper_contributors pc
+		LEFT JOIN users u ON pc.user_id = u.id
+		WHERE pc.paper_id = $1
+		ORDER BY pc.created_at ASC
+	`, paperID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch contributors"})
+		return
+	}
+	defer rows.Close()
+
+	contributors := []models.PaperContributor{}
+	for rows.Next() {
+		var pc models.PaperContributor
+		if err := rows.Scan(&pc.PaperID, &pc.UserID, &pc.Name, &pc.Role, &pc.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan contributor"})
+			return
+		}
+		contributors = append(contributors, pc)
+	}
+
+	c.JSON(http.StatusOK, contributors)
+}
+
+// canManageContributors reports whether the caller is the paper's author or staff
+func (s *Server) canManageContributors(c *gin.Context, paperID uuid.UUID) (bool, error) {
+	if s.can(c, rbac.PaperEdit) {
+		return true, nil
+	}
+	var authorID uuid.UUID
+	err := s.db.Pool.QueryRow(c.Request.Context(),
+		"SELECT author_id FROM papers WHERE id = $1 AND deleted_at IS NULL", paperID).Scan(&authorID)
+	if err != nil {
+		return false, err
+	}
+	return authorID.String() == c.GetString("user_id"), nil
+}
+
+// AddPaperContributor credits another researcher on a paper
+func (s *Server) AddPaperContributor(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.AddContributorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Role = strings.TrimSpace(req.Role)
+	if req.Role == "" {
+		req.Role = "co-author"
+	}
+
+	allowed, err := s.canManageContributors(c, paperID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or staff can manage contributors"})
+		return
+	}
+
+	pc := models.PaperContributor{PaperID: paperID, UserID: req.UserID, Role: req.Role}
+	err = s.db.Pool.QueryRow(c.Request.Context(), `
+		INSERT INTO paper_contributors (paper_id, user_id, role)
+		SELECT $1, id, $3 FROM users WHERE id = $2 AND tenant_id = $4
+		ON CONFLICT (paper_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING created_at, (SELECT name FROM users WHERE id = $2)
+	`, paperID, req.UserID, req.Role, tenantID(c)).Scan(&pc.CreatedAt, &pc.Name)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add contributor"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, pc)
+}
+
+// RemovePaperContributor removes a researcher's credit from a paper
+func (s *Server) RemovePaperContributor(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	allowed, err := s.canManageContributors(c, paperID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or staff can manage contributors"})
+		return
+	}
+
+	_, err = s.db.Pool.Exec(c.Request.Context(),
+		"DELETE FROM paper_contributors WHERE paper_id = $1 AND user_id = $2", paperID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove contributor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contributor removed"})
+}
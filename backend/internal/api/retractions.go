@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// getLivePaper loads a paper that has not been soft deleted
+func (s *Server) getLivePaper(ctx context.Context, paperID uuid.UUID) (*models.Paper, error) {
+	var paper models.Paper
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, title, author_id, status, COALESCE(publication_id, ''), COALESCE(journal_name, '')
+		FROM papers
+		WHERE id = $1 AND deleted_at IS NULL
+	`, paperID).Scan(&paper.ID, &paper.Title, &paper.AuthorID, &paper.Status, &paper.PublicationID, &paper.JournalName)
+	if err != nil {
+		return nil, err
+	}
+	return &paper, nil
+}
+
+// WithdrawPaper lets an author pull their own submission before a decision is made
+func (s *Server) WithdrawPaper(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.WithdrawPaperRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	uid, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	paper, err := s.getLivePaper(ctx, paperID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+
+	if paper.AuthorID != uid && !s.can(c, rbac.PaperPublish) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can withdraw this paper"})
+		return
+	}
+	if !paper.CanWithdraw() {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A paper that is %s can no longer be withdrawn", paper.Status)})
+		return
+	}
+
+	// The status is checked again here in case a decision was made since the paper was read
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE papers
+		SET status = 'withdrawn', withdrawn_at = NOW(), withdrawal_reason = $1, updated_at = NOW()
+		WHERE id = $2 AND status = ANY($3) AND deleted_at IS NULL
+		RETURNING status, withdrawn_at, COALESCE(withdrawal_reason, '')
+	`, strings.TrimSpace(req.Reason), paperID, models.WithdrawableStatuses).Scan(&paper.Status, &paper.WithdrawnAt, &paper.WithdrawalReason)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "A decision has been made on this paper, it can no longer be withdrawn"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw paper"})
+		return
+	}
+	if err := s.db.LockReviews(ctx, paper.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lock reviews"})
+		return
+	}
+
+	// Let the editors and anyone who already reviewed the paper know
+	go func() {
+		message := fmt.Sprintf("Paper '%s' has been withdrawn by its author", paper.Title)
+		s.notifyEditors(message, paper.ID)
+
+		rows, err := s.db.Pool.Query(context.Background(),
+			"SELECT DISTINCT r.reviewer_id FROM reviews r JOIN users u ON u.id = r.reviewer_id WHERE r.paper_id = $1 AND u.role != 'editor'",
+			paper.ID)
+		if err != nil {
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var reviewerID uuid.UUID
+			if err := rows.Scan(&reviewerID); err == nil {
+				s.db.Pool.Exec(context.Background(),
+					"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+					reviewerID, message, paper.ID)
+			}
+		}
+	}()
+
+	c.JSON(http.StatusOK, paper)
+}
+
+// RetractPaper marks a published paper as retracted while keeping it on record with a public notice
+func (s *Server) RetractPaper(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.RetractPaperRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Notice = strings.TrimSpace(req.Notice)
+	if req.Notice == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A retraction notice is required"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	adminID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	paper, err := s.getLivePaper(ctx, paperID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+	if !paper.CanRetract() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only published papers can be retracted"})
+		return
+	}
+
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE papers
+		SET status = 'retracted', retracted_at = NOW(), retracted_by = $1, retraction_notice = $2, updated_at = NOW()
+		WHERE id = $3 AND status = 'published' AND deleted_at IS NULL
+		RETURNING status, retracted_at, retraction_notice
+	`, adminID, req.Notice, paperID).Scan(&paper.Status, &paper.RetractedAt, &paper.RetractionNotice)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only published papers can be retracted"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retract paper"})
+		return
+	}
+
+	go func() {
+		message := fmt.Sprintf("Your paper '%s' has been retracted: %s", paper.Title, paper.RetractionNotice)
+		s.db.Pool.Exec(context.Background(),
+			"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+			paper.AuthorID, message, paper.ID)
+	}()
+
+	c.JSON(http.StatusOK, paper)
+}
+
+const retractionColumns = `
+	SELECT p.id, p.title, COALESCE(u.name, 'Unknown'), COALESCE(p.publication_id, ''), COALESCE(p.journal_name, ''),
+		   p.retracted_at, COALESCE(p.retraction_notice, '')
+	FROM papers p
+	LEFT JOIN users u ON p.author_id = u.id
+	WHERE p.status = 'retracted' AND p.deleted_at IS NULL
+`
+
+func scanRetraction(row pgx.Row, r *models.Retraction) error {
+	return row.Scan(&r.PaperID, &r.Title, &r.AuthorName, &r.PublicationID, &r.JournalName, &r.RetractedAt, &r.Notice)
+}
+
+// GetRetractions lists the public retraction notices, newest first
+func (s *Server) GetRetractions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	rows, err := s.db.Pool.Query(ctx, retractionColumns+" AND p.tenant_id = $1 ORDER BY p.retracted_at DESC", tenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch retractions"})
+		return
+	}
+	defer rows.Close()
+
+	retractions := []models.Retraction{}
+	for rows.Next() {
+		var r models.Retraction
+		if err := scanRetraction(rows, &r); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan retraction"})
+			return
+		}
+		retractions = append(retractions, r)
+	}
+
+	c.JSON(http.StatusOK, retractions)
+}
+
+// GetRetraction returns the public retraction notice for a single paper
+func (s *Server) GetRetraction(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var r models.Retraction
+	err = scanRetraction(s.db.Pool.QueryRow(c.Request.Context(), retractionColumns+" AND p.id = $1 AND p.tenant_id = $2", paperID, tenantID(c)), &r)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No retraction notice for this paper"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch retraction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, r)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/recommend"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// authorships lists every (paper_id, user_id) pair of a live paper and one of its authors or contributors
+const authorships = `
+	SELECT id AS paper_id, author_id AS user_id FROM papers WHERE deleted_at IS NULL
+	UNION
+	SELECT pc.paper_id, pc.user_id FROM paper_contributors pc JOIN papers p ON p.id = pc.paper_id WHERE p.deleted_at IS NULL`
+
+// GetSuggestedReviewers ranks the institution's researchers as reviewers for a paper by how
+// close their past papers and declared expertise are to it, their open reviews and conflicts of interest
+func (s *Server) GetSuggestedReviewers(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	opts := recommend.DefaultOptions
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 && limit <= 50 {
+		opts.Limit = limit
+	}
+	opts.IncludeConflicts = c.Query("include_conflicts") == "true"
+
+	ctx := c.Request.Context()
+	var paper recommend.Paper
+	err = s.db.Pool.QueryRow(ctx,
+		"SELECT title, COALESCE(abstract, ''), keywords FROM papers WHERE id = $1 AND deleted_at IS NULL",
+		paperID).Scan(&paper.Title, &paper.Abstract, &paper.Keywords)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+
+	candidates, err := s.reviewerCandidates(ctx, tenantID(c), paperID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch candidate reviewers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommend.Rank(paper, candidates, opts))
+}
+
+// reviewerCandidates loads the active editors and researchers of the tenant who have not already been
+// invited to or reviewed the paper, with their past papers, expertise, open load and conflicts.
+// Open load counts open invitations plus draft reviews not covered by one.
+func (s *Server) reviewerCandidates(ctx context.Context, tenantID, paperID uuid.UUID) ([]recommend.Candidate, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT u.id, u.name, u.email, u.expertise_keywords, u.expertise_fields,
+			   (SELECT COUNT(*) FROM review_assignments ra
+				WHERE ra.reviewer_id = u.id AND ra.status IN ('invited', 'accepted')) +
+			   (SELECT COUNT(*) FROM reviews r JOIN papers rp ON rp.id = r.paper_id
+				WHERE r.reviewer_id = u.id AND r.status = 'draft' AND rp.deleted_at IS NULL
+				  AND rp.status IN ('submitted', 'under_review', 'recommended_for_publication')
+				  AND NOT EXISTS (SELECT 1 FROM review_assignments ra
+								  WHERE ra.paper_id = r.paper_id AND ra.reviewer_id = u.id AND ra.status IN ('invited', 'accepted')))
+		FROM users u
+		WHERE u.tenant_id = $1 AND u.role IN ('editor', 'author') AND u.is_active
+		  AND NOT EXISTS (SELECT 1 FROM reviews r WHERE r.paper_id = $2 AND r.reviewer_id = u.id)
+		  AND NOT EXISTS (SELECT 1 FROM review_assignments ra WHERE ra.paper_id = $2 AND ra.reviewer_id = u.id AND ra.status != 'cancelled')
+	`, tenantID, paperID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []recommend.Candidate
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var cand recommend.Candidate
+		var fields []string
+		if err := rows.Scan(&cand.UserID, &cand.Name, &cand.Email, &cand.Expertise, &fields, &cand.OpenReviews); err != nil {
+			return nil, err
+		}
+		for _, code := range fields {
+			if name, ok := models.ISCEDFields[code]; ok {
+				cand.Expertise = append(cand.Expertise, name)
+			}
+		}
+		index[cand.UserID] = len(candidates)
+		candidates = append(candidates, cand)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Past papers, excluding drafts and the paper itself
+	rows, err = s.db.Pool.Query(ctx, `
+		SELECT a.user_id, p.title || ' ' || COALESCE(p.abstract, '') || ' ' || array_to_string(p.keywords, ' ')
+		FROM (`+authorships+`) a
+		JOIN papers p ON p.id = a.paper_id
+		WHERE p.tenant_id = $1 AND p.id != $2 AND p.status NOT IN ('draft', 'withdrawn')
+	`, tenantID, paperID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID uuid.UUID
+		var text string
+		if err := rows.Scan(&userID, &text); err != nil {
+			return nil, err
+		}
+		if i, ok := index[userID]; ok {
+			candidates[i].Papers = append(candidates[i].Papers, text)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Conflicts of interest: the paper's own authors, their co-authors and colleagues in the same department
+	rows, err = s.db.Pool.Query(ctx, `
+		WITH authorships AS (`+authorships+`),
+		authors AS (SELECT user_id FROM authorships WHERE paper_id = $1)
+		SELECT user_id, 'author of the paper', 1 FROM authors
+		UNION ALL
+		SELECT DISTINCT co.user_id, 'co-author of one of the paper''s authors', 2
+		FROM authorships mine
+		JOIN authorships co ON co.paper_id = mine.paper_id
+		WHERE mine.user_id IN (SELECT user_id FROM authors) AND mine.paper_id != $1
+		  AND co.user_id NOT IN (SELECT user_id FROM authors)
+		UNION ALL
+		SELECT ua.user_id, 'same department as the author', 3
+		FROM papers p
+		JOIN org_units ou ON ou.id = p.unit_id AND ou.type = 'department'
+		JOIN user_affiliations ua ON ua.unit_id = ou.id
+		WHERE p.id = $1 AND ua.user_id NOT IN (SELECT user_id FROM authors)
+		ORDER BY 3
+	`, paperID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var userID uuid.UUID
+		var reason string
+		var strength int
+		if err := rows.Scan(&userID, &reason, &strength); err != nil {
+			return nil, err
+		}
+		// Rows come strongest reason first
+		if i, ok := index[userID]; ok && candidates[i].Conflict == "" {
+			candidates[i].Conflict = reason
+		}
+	}
+	return candidates, rows.Err()
+}
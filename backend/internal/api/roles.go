@@ -0,0 +1,372 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rpms-backend/internal/middleware"
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// can reports whether the caller's roles grant permission. Lookup failures deny.
+func (s *Server) can(c *gin.Context, permission string) bool {
+	set, err := middleware.Permissions(c, s.permissions)
+	return err == nil && set.Has(permission)
+}
+
+// invalidPermissions returns the names in permissions that are not permissions
+func invalidPermissions(permissions []string) []string {
+	var invalid []string
+	for _, p := range permissions {
+		if !rbac.Valid(p) {
+			invalid = append(invalid, p)
+		}
+	}
+	return invalid
+}
+
+// GetPermissions lists every permission roles can grant
+func (s *Server) GetPermissions(c *gin.Context) {
+	permissions := []models.PermissionInfo{}
+	for _, name := range rbac.All() {
+		permissions = append(permissions, models.PermissionInfo{Name: name, Description: rbac.Descriptions[name]})
+	}
+	c.JSON(http.StatusOK, permissions)
+}
+
+// GetRoles lists the tenant's roles, including built-in roles that were never stored
+func (s *Server) GetRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT r.id, r.name, r.description, r.permissions, r.built_in, r.created_at, r.updated_at,
+			(SELECT COUNT(*) FROM users u
+			 WHERE u.tenant_id = r.tenant_id
+			   AND (u.role = r.name OR EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id AND ur.role_id = r.id)))
+		FROM roles r
+		WHERE r.tenant_id = $1
+		ORDER BY r.built_in DESC, r.name
+	`, tenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+	defer rows.Close()
+
+	roles := []models.Role{}
+	stored := map[string]bool{}
+	for rows.Next() {
+		var r models.Role
+		if err := rows.Scan(&r.ID, &r.Name, &r.Description, &r.Permissions, &r.BuiltIn, &r.CreatedAt, &r.UpdatedAt, &r.UserCount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan role"})
+			return
+		}
+		stored[r.Name] = true
+		roles = append(roles, r)
+	}
+
+	var missing []string
+	for name := range rbac.DefaultRoles {
+		if !stored[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		roles = append(roles, models.Role{Name: name, Permissions: rbac.DefaultRoles[name], BuiltIn: true})
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// CreateRole adds a custom role to the tenant
+func (s *Server) CreateRole(c *gin.Context) {
+	var req models.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Name = strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role names use lowercase letters, digits, '-' and '_'"})
+		return
+	}
+	if rbac.IsBuiltIn(req.Name) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A built-in role with this name exists"})
+		return
+	}
+	if invalid := invalidPermissions(req.Permissions); len(invalid) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permissions", "permissions": invalid})
+		return
+	}
+
+	role := models.Role{Name: req.Name, Description: strings.TrimSpace(req.Description), Permissions: req.Permissions}
+	err := s.db.Pool.QueryRow(c.Request.Context(), `
+		INSERT INTO roles (tenant_id, name, description, permissions)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`, tenantID(c), role.Name, role.Description, role.Permissions).Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A role with this name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole changes a role's description or permissions. The admin role always keeps
+// every permission so that the institution cannot lock itself out.
+func (s *Server) UpdateRole(c *gin.Context) {
+	roleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if invalid := invalidPermissions(req.Permissions); len(invalid) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown permissions", "permissions": invalid})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var name string
+	err = s.db.Pool.QueryRow(ctx, "SELECT name FROM roles WHERE id = $1", roleID).Scan(&name)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role"})
+		return
+	}
+	if name == rbac.RoleAdmin && req.Permissions != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "The admin role always has every permission"})
+		return
+	}
+
+	role := models.Role{ID: &roleID}
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE roles
+		SET description = COALESCE($1, description), permissions = COALESCE($2::text[], permissions), updated_at = NOW()
+		WHERE id = $3
+		RETURNING name, description, permissions, built_in, created_at, updated_at
+	`, req.Description, req.Permissions, roleID).Scan(&role.Name, &role.Description, &role.Permissions, &role.BuiltIn, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+	s.permissions.Invalidate()
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole removes a custom role from the tenant and from every user holding it
+func (s *Server) DeleteRole(c *gin.Context) {
+	roleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var builtIn bool
+	err = s.db.Pool.QueryRow(ctx, "SELECT built_in FROM roles WHERE id = $1", roleID).Scan(&builtIn)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role"})
+		return
+	}
+	if builtIn {
+		c.JSON(http.StatusConflict, gin.H{"error": "Built-in roles cannot be deleted"})
+		return
+	}
+
+	if _, err := s.db.Pool.Exec(ctx, "DELETE FROM roles WHERE id = $1", roleID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
+		return
+	}
+	s.permissions.Invalidate()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// userRoles describes the roles and permissions of a user
+func (s *Server) userRoles(ctx context.Context, userID uuid.UUID) (*models.UserRoles, error) {
+	result := &models.UserRoles{UserID: userID, Roles: []string{}}
+	if err := s.db.Pool.QueryRow(ctx, "SELECT role FROM users WHERE id = $1", userID).Scan(&result.PrimaryRole); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT r.name FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1 ORDER BY r.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		result.Roles = append(result.Roles, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	set, err := s.permissions.Permissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result.Permissions = set.List()
+	return result, nil
+}
+
+// GetMyPermissions returns the caller's roles and permissions, for the frontend to adapt to
+func (s *Server) GetMyPermissions(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+	roles, err := s.userRoles(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions"})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetUserRoles returns a user's roles and permissions
+func (s *Server) GetUserRoles(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	roles, err := s.userRoles(c.Request.Context(), userID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// SetUserRoles replaces the roles a user holds in addition to their primary role
+func (s *Server) SetUserRoles(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.SetUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var primary string
+	var userTenant uuid.UUID
+	err = s.db.Pool.QueryRow(ctx, "SELECT role, tenant_id FROM users WHERE id = $1", userID).Scan(&primary, &userTenant)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var roleIDs []uuid.UUID
+	seen := map[string]bool{primary: true}
+	for _, name := range req.Roles {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		// Built-in roles of tenants that predate roles are stored on first use
+		if permissions, ok := rbac.DefaultRoles[name]; ok {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO roles (tenant_id, name, permissions, built_in) VALUES ($1, $2, $3, TRUE)
+				ON CONFLICT (tenant_id, name) DO NOTHING
+			`, userTenant, name, permissions); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role"})
+				return
+			}
+		}
+
+		var roleID uuid.UUID
+		err := tx.QueryRow(ctx, "SELECT id FROM roles WHERE tenant_id = $1 AND name = $2", userTenant, name).Scan(&roleID)
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role: " + name})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch role"})
+			return
+		}
+		roleIDs = append(roleIDs, roleID)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM user_roles WHERE user_id = $1", userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update roles"})
+		return
+	}
+	grantedBy, _ := uuid.Parse(c.GetString("user_id"))
+	for _, roleID := range roleIDs {
+		if _, err := tx.Exec(ctx,
+			"INSERT INTO user_roles (user_id, role_id, granted_by) VALUES ($1, $2, $3)", userID, roleID, grantedBy); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update roles"})
+			return
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update roles"})
+		return
+	}
+	s.permissions.Invalidate()
+
+	roles, err := s.userRoles(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
@@ -5,6 +5,7 @@ import (
 	"rpms-backend/internal/config"
 	"rpms-backend/internal/database"
 	"rpms-backend/internal/middleware"
+	"rpms-backend/internal/rbac"
 	"rpms-backend/internal/storage"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,10 @@ func SetupRoutes(router *gin.Engine, db *database.Database, cfg *config.Config)
 	server := NewServer(db, cfg)
 	chatHandler := NewChatHandler(db)
 	jwtManager := auth.NewJWTManager(cfg)
+	// can guards a route with a permission of the caller's roles
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(server.permissions, permission)
+	}
 
 	// Initialize Supabase Storage
 	supabaseStorage := storage.NewSupabaseStorage(
@@ -55,6 +60,7 @@ func SetupRoutes(router *gin.Engine, db *database.Database, cfg *config.Config)
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
+	v1.Use(middleware.TenantMiddleware(server.tenants, jwtManager), middleware.Audit(server.auditLog))
 	{
 		// Auth routes (no authentication required)
 		auth := v1.Group("/auth")
@@ -63,59 +69,181 @@ func SetupRoutes(router *gin.Engine, db *database.Database, cfg *config.Config)
 			auth.POST("/login", server.Login)
 			auth.POST("/verify", server.VerifyEmail)
 			auth.POST("/resend-code", server.ResendVerificationCode)
+			auth.POST("/refresh", server.RefreshToken)
+			auth.POST("/logout", server.Logout)
+			auth.POST("/forgot-password", server.ForgotPassword)
+			auth.POST("/reset-password", server.ResetPassword)
+			auth.GET("/oidc/login", server.StartSSO)
+			auth.POST("/oidc/callback", server.FinishSSO)
+
+			// Second step of sign in for accounts with two-factor authentication
+			auth.POST("/mfa/verify", middleware.MFAVerifyOnly(jwtManager), server.VerifyMFA)
+			enroll := auth.Group("/mfa/enroll", middleware.MFAEnrollOnly(jwtManager))
+			{
+				enroll.POST("/setup", server.SetupMFA)
+				enroll.POST("/enable", server.EnableMFA)
+			}
 		}
 
 		// Public routes
+		v1.GET("/tenant", server.GetTenant)
 		v1.GET("/events", server.GetEvents)
 		v1.GET("/news", server.GetNews)
+		v1.GET("/issues/:id/toc", server.TenantScoped("journal_issues"), server.GetIssueTableOfContents)
+		v1.GET("/retractions", server.GetRetractions)
+		v1.GET("/retractions/:id", server.GetRetraction)
+
+		// Public catalog of published research
+		public := v1.Group("/public")
+		{
+			public.GET("/papers", server.GetPublicPapers)
+			public.GET("/papers/:slug", server.GetPublicPaper)
+			public.GET("/papers/:slug/download", server.DownloadPublicPaper)
+			public.GET("/browse", server.GetPublicCatalogFacets)
+		}
+		v1.GET("/researchers/:id", server.GetResearcherProfile)
 
 		// Protected routes (authentication required)
 		protected := v1.Group("/")
-		protected.Use(middleware.AuthMiddleware(jwtManager))
+		protected.Use(middleware.AuthMiddleware(jwtManager, server.tokens, server.apiKeys))
 		{
 			// User routes
 			protected.GET("/profile", server.GetProfile)
 			protected.PUT("/profile", server.UpdateProfile)
 			protected.PUT("/auth/password", server.ChangePassword)
 			protected.DELETE("/auth/account", server.DeleteAccount)
+			protected.GET("/auth/sessions", server.GetSessions)
+			protected.DELETE("/auth/sessions", server.RevokeOtherSessions)
+			protected.DELETE("/auth/sessions/:id", server.RevokeSession)
+			protected.GET("/auth/mfa", server.GetMFAStatus)
+			protected.POST("/auth/mfa/setup", server.SetupMFA)
+			protected.POST("/auth/mfa/enable", server.EnableMFA)
+			protected.POST("/auth/mfa/disable", server.DisableMFA)
+			protected.POST("/auth/mfa/recovery-codes", server.RegenerateRecoveryCodes)
 			protected.GET("/notifications", server.GetNotifications)
 			protected.PUT("/notifications/:id/read", server.MarkNotificationRead)
 			protected.POST("/notifications", server.CreateNotification)
 			protected.GET("/users/admin", server.GetAdminUsers)
+			protected.GET("/profile/affiliations", server.GetMyAffiliations)
+			protected.GET("/profile/permissions", server.GetMyPermissions)
+
+			// Organizational units (institution -> college -> department)
+			units := protected.Group("/units")
+			units.Use(server.TenantScoped("org_units"))
+			{
+				units.GET("", server.GetUnits)
+				units.GET("/:id", server.GetUnit)
+				units.POST("", can(rbac.UnitManage), server.CreateUnit)
+				units.PUT("/:id", can(rbac.UnitManage), server.UpdateUnit)
+				units.DELETE("/:id", can(rbac.UnitManage), server.DeleteUnit)
+				units.GET("/:id/members", can(rbac.UnitView), server.GetUnitMembers)
+				units.GET("/:id/stats", can(rbac.UnitView), server.GetUnitStats)
+			}
 
 			papers := protected.Group("/papers")
+			papers.Use(server.TenantScoped("papers"))
 			{
 				papers.GET("", server.GetPapers)
-				papers.POST("", middleware.AuthorOrAdmin(), server.CreatePaper)
-				papers.PUT("/:id", middleware.AuthorOrAdmin(), server.UpdatePaper)
-				papers.DELETE("/:id", middleware.AuthorOrAdmin(), server.DeletePaper)
-				papers.POST("/:id/recommend", middleware.EditorOrAdmin(), server.RecommendPaperForPublication)
-				papers.PUT("/:id/details", middleware.EditorOrCoordinatorOrAdmin(), server.UpdatePaperDetails)
+				papers.POST("", can(rbac.PaperSubmit), server.CreatePaper)
+				papers.PUT("/:id", can(rbac.PaperSubmit), server.UpdatePaper)
+				papers.DELETE("/:id", can(rbac.PaperSubmit), server.DeletePaper)
+				papers.POST("/:id/recommend", can(rbac.PaperRecommend), server.RecommendPaperForPublication)
+				papers.PUT("/:id/decision", can(rbac.PaperPublish), server.DecidePaper)
+				papers.PUT("/:id/details", can(rbac.PaperEdit), server.UpdatePaperDetails)
+				papers.POST("/:id/withdraw", can(rbac.PaperSubmit), server.WithdrawPaper)
+				papers.POST("/:id/retract", can(rbac.PaperPublish), server.RetractPaper)
+				papers.PUT("/:id/schedule", can(rbac.PaperPublish), server.SchedulePaperPublication)
+				papers.DELETE("/:id/schedule", can(rbac.PaperPublish), server.UnschedulePaperPublication)
+				papers.PUT("/:id/embargo", can(rbac.PaperPublish), server.SetPaperEmbargo)
+				papers.GET("/:id/suggested-reviewers", can(rbac.PaperAssign), server.GetSuggestedReviewers)
+				papers.GET("/:id/review-assignments", can(rbac.PaperAssign), server.GetPaperReviewAssignments)
+				papers.POST("/:id/review-assignments", can(rbac.PaperAssign), server.CreateReviewAssignment)
+				papers.GET("/:id/staff", can(rbac.PaperDiscuss), server.GetPaperStaff)
+				papers.POST("/:id/staff", can(rbac.PaperAssign), server.AssignPaperStaff)
+				papers.DELETE("/:id/staff/:userId", can(rbac.PaperAssign), server.RemovePaperStaff)
+				papers.GET("/:id/discussion", can(rbac.PaperDiscuss), server.GetPaperDiscussion)
+				papers.POST("/:id/discussion", can(rbac.PaperDiscuss), server.PostDiscussionMessage)
+				papers.PUT("/:id/discussion/:messageId/pin", can(rbac.PaperDiscuss), server.PinDiscussionMessage)
+				papers.GET("/:id/contributors", server.GetPaperContributors)
+				papers.POST("/:id/contributors", server.AddPaperContributor)
+				papers.DELETE("/:id/contributors/:userId", server.RemovePaperContributor)
+				papers.PUT("/:id/unit", can(rbac.PaperEdit), server.AssignPaperUnit)
+			}
+
+			// Call routes (calls for papers and grant calls)
+			calls := protected.Group("/calls")
+			calls.Use(server.TenantScoped("calls"))
+			{
+				calls.GET("", server.GetCalls)
+				calls.GET("/:id", server.GetCall)
+				calls.POST("", can(rbac.CallManage), server.CreateCall)
+				calls.PUT("/:id", can(rbac.CallManage), server.UpdateCall)
+				calls.DELETE("/:id", can(rbac.CallManage), server.DeleteCall)
+				calls.POST("/:id/submissions", can(rbac.PaperSubmit), server.SubmitToCall)
+				calls.GET("/:id/submissions", can(rbac.CallViewSubmissions), server.GetCallSubmissions)
+				calls.GET("/:id/stats", can(rbac.CallViewSubmissions), server.GetCallStats)
+			}
+
+			// Journal routes (our own journals, volumes and issues)
+			journals := protected.Group("/journals")
+			journals.Use(server.TenantScoped("journals"))
+			{
+				journals.GET("", server.GetJournals)
+				journals.POST("", can(rbac.JournalManage), server.CreateJournal)
+				journals.PUT("/:id", can(rbac.JournalManage), server.UpdateJournal)
+				journals.DELETE("/:id", can(rbac.JournalManage), server.DeleteJournal)
+				journals.GET("/:id/volumes", server.GetVolumes)
+				journals.POST("/:id/volumes", can(rbac.JournalManage), server.CreateVolume)
+			}
+			protected.GET("/volumes/:id/issues", server.TenantScoped("journal_volumes"), server.GetIssues)
+			protected.POST("/volumes/:id/issues", server.TenantScoped("journal_volumes"), can(rbac.JournalManage), server.CreateIssue)
+			issues := protected.Group("/issues")
+			issues.Use(server.TenantScoped("journal_issues"))
+			{
+				issues.PUT("/:id", can(rbac.JournalManage), server.UpdateIssue)
+				issues.DELETE("/:id", can(rbac.JournalManage), server.DeleteIssue)
+				issues.POST("/:id/papers", can(rbac.JournalManage), server.AssignPaperToIssue)
+				issues.DELETE("/:id/papers/:paperId", can(rbac.JournalManage), server.RemovePaperFromIssue)
 			}
 
 			// Review routes
 			reviews := protected.Group("/reviews")
+			reviews.Use(server.TenantScoped("reviews"))
 			{
 				reviews.GET("", server.GetReviews)
-				reviews.POST("", middleware.EditorOrAdmin(), server.CreateReview)
+				reviews.POST("", server.CreateReview)
+				reviews.PUT("/:id", server.UpdateReview)
+			}
+
+			// Review invitations, answered by the invited reviewer
+			assignments := protected.Group("/review-assignments")
+			{
+				assignments.GET("", server.GetMyReviewAssignments)
+				assignments.PUT("/:id/accept", server.AcceptReviewAssignment)
+				assignments.PUT("/:id/decline", server.DeclineReviewAssignment)
+				assignments.DELETE("/:id", can(rbac.PaperAssign), server.CancelReviewAssignment)
 			}
 
 			// Event routes
 			events := protected.Group("/events")
+			events.Use(server.TenantScoped("events"))
 			{
-				events.POST("", middleware.CoordinatorOrAdmin(), server.CreateEvent)
-				events.PUT("/:id", middleware.CoordinatorOrAdmin(), server.UpdateEvent)
-				events.PUT("/:id/publish", middleware.CoordinatorOrAdmin(), server.PublishEvent)
-				events.DELETE("/:id", middleware.CoordinatorOrAdmin(), server.DeleteEvent)
+				events.POST("", can(rbac.EventPublish), server.CreateEvent)
+				events.PUT("/:id", can(rbac.EventPublish), server.UpdateEvent)
+				events.PUT("/:id/publish", can(rbac.EventPublish), server.PublishEvent)
+				events.PUT("/:id/schedule", can(rbac.EventPublish), server.ScheduleEvent)
+				events.DELETE("/:id", can(rbac.EventPublish), server.DeleteEvent)
 			}
 
 			// News routes
 			news := protected.Group("/news")
+			news.Use(server.TenantScoped("news"))
 			{
-				news.POST("", middleware.CoordinatorOrAdmin(), server.CreateNews)
-				news.PUT("/:id", middleware.CoordinatorOrAdmin(), server.UpdateNews)
-				news.PUT("/:id/publish", middleware.CoordinatorOrAdmin(), server.PublishNews)
-				news.DELETE("/:id", middleware.CoordinatorOrAdmin(), server.DeleteNews)
+				news.POST("", can(rbac.NewsPublish), server.CreateNews)
+				news.PUT("/:id", can(rbac.NewsPublish), server.UpdateNews)
+				news.PUT("/:id/publish", can(rbac.NewsPublish), server.PublishNews)
+				news.PUT("/:id/schedule", can(rbac.NewsPublish), server.ScheduleNews)
+				news.DELETE("/:id", can(rbac.NewsPublish), server.DeleteNews)
 			}
 
 			// Chat routes
@@ -139,16 +267,49 @@ func SetupRoutes(router *gin.Engine, db *database.Database, cfg *config.Config)
 				interactions.GET("/stats/:postType/:postId", server.GetEngagementStats)
 			}
 
-			// Admin only routes
+			// Administration routes, each guarded by its own permission
 			admin := protected.Group("/admin")
-			admin.Use(middleware.AdminOnly())
 			{
-				admin.GET("/stats", func(c *gin.Context) {
-					// TODO: Implement admin statistics
-					c.JSON(200, gin.H{"message": "Admin statistics endpoint"})
-				})
-				admin.POST("/users", server.AdminCreateUser)
-				admin.GET("/staff", server.GetAdminStaff)
+				admin.GET("/stats", can(rbac.ReportView), server.GetAdminStats)
+				admin.POST("/users", can(rbac.UserManage), server.AdminCreateUser)
+				admin.GET("/staff", can(rbac.UserManage), server.GetAdminStaff)
+				admin.GET("/users", can(rbac.UserManage), server.ListUsers)
+				admin.GET("/users/:id", can(rbac.UserManage), server.TenantScoped("users"), server.GetUserDetails)
+				admin.PUT("/users/:id/role", can(rbac.UserManage), server.TenantScoped("users"), server.ChangeUserRole)
+				admin.POST("/users/:id/deactivate", can(rbac.UserManage), server.TenantScoped("users"), server.DeactivateUser)
+				admin.POST("/users/:id/reactivate", can(rbac.UserManage), server.TenantScoped("users"), server.ReactivateUser)
+				admin.POST("/users/:id/reverify", can(rbac.UserManage), server.TenantScoped("users"), server.ForceReverification)
+				admin.POST("/users/:id/reset-password", can(rbac.UserManage), server.TenantScoped("users"), server.AdminResetPassword)
+				admin.DELETE("/users/:id", can(rbac.UserManage), server.TenantScoped("users"), server.DeleteUser)
+				admin.GET("/reviewers/stats", can(rbac.ReportView), server.GetReviewerStats)
+				admin.POST("/import/:kind", can(rbac.DataImport), server.ImportLegacyCSV)
+				admin.GET("/users/:id/affiliations", can(rbac.UserManage), server.TenantScoped("users"), server.GetUserAffiliations)
+				admin.POST("/users/:id/affiliations", can(rbac.UserManage), server.TenantScoped("users"), server.AddUserAffiliation)
+				admin.DELETE("/users/:id/affiliations/:unitId", can(rbac.UserManage), server.TenantScoped("users"), server.RemoveUserAffiliation)
+				admin.DELETE("/users/:id/mfa", can(rbac.UserManage), server.TenantScoped("users"), server.AdminResetMFA)
+				admin.GET("/users/:id/login-attempts", can(rbac.UserManage), server.TenantScoped("users"), server.GetUserLoginAttempts)
+				admin.POST("/users/:id/unlock", can(rbac.UserManage), server.TenantScoped("users"), server.UnlockUser)
+				admin.GET("/users/:id/roles", can(rbac.RoleManage), server.TenantScoped("users"), server.GetUserRoles)
+				admin.PUT("/users/:id/roles", can(rbac.RoleManage), server.TenantScoped("users"), server.SetUserRoles)
+				admin.GET("/permissions", can(rbac.RoleManage), server.GetPermissions)
+				admin.GET("/roles", can(rbac.RoleManage), server.GetRoles)
+				admin.POST("/roles", can(rbac.RoleManage), server.CreateRole)
+				admin.PUT("/roles/:id", can(rbac.RoleManage), server.TenantScoped("roles"), server.UpdateRole)
+				admin.DELETE("/roles/:id", can(rbac.RoleManage), server.TenantScoped("roles"), server.DeleteRole)
+				admin.GET("/audit", can(rbac.AuditRead), server.GetAuditLog)
+				admin.GET("/audit/export", can(rbac.AuditRead), server.ExportAuditLog)
+				admin.GET("/audit/verify", can(rbac.AuditRead), server.VerifyAuditLog)
+				admin.GET("/api-scopes", can(rbac.ServiceAccountManage), server.GetAPIScopes)
+				admin.GET("/service-accounts", can(rbac.ServiceAccountManage), server.GetServiceAccounts)
+				admin.POST("/service-accounts", can(rbac.ServiceAccountManage), server.CreateServiceAccount)
+				admin.GET("/service-accounts/:id", can(rbac.ServiceAccountManage), server.TenantScoped("service_accounts"), server.GetServiceAccount)
+				admin.PUT("/service-accounts/:id", can(rbac.ServiceAccountManage), server.TenantScoped("service_accounts"), server.UpdateServiceAccount)
+				admin.DELETE("/service-accounts/:id", can(rbac.ServiceAccountManage), server.TenantScoped("service_accounts"), server.DeleteServiceAccount)
+				admin.POST("/service-accounts/:id/api-keys", can(rbac.ServiceAccountManage), server.TenantScoped("service_accounts"), server.CreateAPIKey)
+				admin.POST("/service-accounts/:id/api-keys/:keyId/rotate", can(rbac.ServiceAccountManage), server.TenantScoped("service_accounts"), server.RotateAPIKey)
+				admin.DELETE("/service-accounts/:id/api-keys/:keyId", can(rbac.ServiceAccountManage), server.TenantScoped("service_accounts"), server.RevokeAPIKey)
+				admin.GET("/tenant", can(rbac.TenantManage), server.GetTenantConfig)
+				admin.PUT("/tenant", can(rbac.TenantManage), server.UpdateTenantConfig)
 			}
 		}
 	}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SchedulePaperPublication sets when an accepted paper is published by the background job
+func (s *Server) SchedulePaperPublication(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.SchedulePaperRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.PublishAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at must be in the future"})
+		return
+	}
+	if req.EmbargoUntil != nil && !req.EmbargoUntil.After(req.PublishAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "embargo_until must be after publish_at"})
+		return
+	}
+
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	paper, err := s.getLivePaper(ctx, paperID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch paper"})
+		return
+	}
+	if !paper.CanSchedule() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only accepted papers awaiting publication can be scheduled"})
+		return
+	}
+
+	// The status is checked again here in case the paper was published or decided on since
+	err = s.db.Pool.QueryRow(ctx, `
+		UPDATE papers SET publish_at = $1, embargo_until = $2, updated_at = NOW()
+		WHERE id = $3 AND status = ANY($4) AND deleted_at IS NULL
+		RETURNING publish_at, embargo_until
+	`, req.PublishAt, req.EmbargoUntil, paperID, models.SchedulableStatuses).Scan(&paper.PublishAt, &paper.EmbargoUntil)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only accepted papers awaiting publication can be scheduled"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule paper"})
+		return
+	}
+
+	c.JSON(http.StatusOK, paper)
+}
+
+// UnschedulePaperPublication cancels a pending scheduled publication
+func (s *Server) UnschedulePaperPublication(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	result, err := s.db.Pool.Exec(c.Request.Context(), `
+		UPDATE papers SET publish_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = ANY($2) AND publish_at IS NOT NULL AND deleted_at IS NULL
+	`, paperID, models.SchedulableStatuses)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel schedule"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending publication for this paper"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scheduled publication cancelled"})
+}
+
+// SetPaperEmbargo sets or lifts the embargo on a paper's manuscript
+func (s *Server) SetPaperEmbargo(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.EmbargoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.EmbargoUntil != nil && !req.EmbargoUntil.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "embargo_until must be in the future"})
+		return
+	}
+
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+
+	var paper models.Paper
+	err = s.db.Pool.QueryRow(c.Request.Context(), `
+		UPDATE papers SET embargo_until = $1, updated_at = NOW()
+		WHERE id = $2 AND status = ANY($3) AND deleted_at IS NULL
+		RETURNING id, title, author_id, status, publish_at, embargo_until
+	`, req.EmbargoUntil, paperID, models.EmbargoableStatuses).Scan(&paper.ID, &paper.Title, &paper.AuthorID, &paper.Status, &paper.PublishAt, &paper.EmbargoUntil)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only accepted or published papers can be embargoed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update embargo"})
+		return
+	}
+	paper.Embargoed = paper.IsUnderEmbargo(time.Now())
+
+	c.JSON(http.StatusOK, paper)
+}
+
+// ScheduleNews sets when a draft news item is published by the background job
+func (s *Server) ScheduleNews(c *gin.Context) {
+	newsID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid news ID"})
+		return
+	}
+
+	var req models.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.PublishAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at must be in the future"})
+		return
+	}
+
+	query := `
+		UPDATE news
+		SET publish_at = $1, updated_at = NOW()
+		WHERE id = $2 AND status = 'draft'
+		RETURNING id, title, summary, content, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), editor_id, created_at, updated_at, publish_at
+	`
+
+	var news models.News
+	err = s.db.Pool.QueryRow(c.Request.Context(), query, req.PublishAt, newsID).Scan(
+		&news.ID, &news.Title, &news.Summary, &news.Content, &news.Category, &news.Status,
+		&news.ImageURL, &news.VideoURL, &news.EditorID, &news.CreatedAt, &news.UpdatedAt, &news.PublishAt,
+	)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No draft news item with this ID"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule news"})
+		return
+	}
+
+	c.JSON(http.StatusOK, news)
+}
+
+// ScheduleEvent sets when a draft event is published by the background job
+func (s *Server) ScheduleEvent(c *gin.Context) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.PublishAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at must be in the future"})
+		return
+	}
+
+	query := `
+		UPDATE events
+		SET publish_at = $1, updated_at = NOW()
+		WHERE id = $2 AND status = 'draft'
+		RETURNING id, title, description, category, status, COALESCE(image_url, ''), COALESCE(video_url, ''), date, location, coordinator_id, created_at, updated_at, publish_at
+	`
+
+	var event models.Event
+	err = s.db.Pool.QueryRow(c.Request.Context(), query, req.PublishAt, eventID).Scan(
+		&event.ID, &event.Title, &event.Description, &event.Category, &event.Status, &event.ImageURL, &event.VideoURL, &event.Date, &event.Location,
+		&event.CoordinatorID, &event.CreatedAt, &event.UpdatedAt, &event.PublishAt,
+	)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No draft event with this ID"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxKeyGrace bounds how long a rotated key keeps working next to its replacement
+const maxKeyGrace = 30 * 24 * time.Hour
+
+// invalidScopes returns the names in scopes that are not API scopes
+func invalidScopes(scopes []string) []string {
+	var invalid []string
+	for _, name := range scopes {
+		if !auth.ValidScope(name) {
+			invalid = append(invalid, name)
+		}
+	}
+	return invalid
+}
+
+// keyExpiry turns a validity in days into an expiry time; zero days never expire
+func keyExpiry(days int) *time.Time {
+	if days <= 0 {
+		return nil
+	}
+	t := time.Now().AddDate(0, 0, days)
+	return &t
+}
+
+const serviceAccountColumns = `
+	SELECT id, name, description, scopes, created_by, created_at, updated_at, disabled_at
+	FROM service_accounts `
+
+func scanServiceAccount(row pgx.Row, sa *models.ServiceAccount) error {
+	return row.Scan(&sa.ID, &sa.Name, &sa.Description, &sa.Scopes, &sa.CreatedBy, &sa.CreatedAt, &sa.UpdatedAt, &sa.DisabledAt)
+}
+
+// serviceAccountParam returns the service account of the :id route parameter, reporting a
+// missing one to the client. TenantScoped has already checked its tenant.
+func (s *Server) serviceAccountParam(c *gin.Context) (*models.ServiceAccount, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return nil, false
+	}
+	var sa models.ServiceAccount
+	err = scanServiceAccount(s.db.Pool.QueryRow(c.Request.Context(), serviceAccountColumns+"WHERE id = $1", id), &sa)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service account"})
+		return nil, false
+	}
+	return &sa, true
+}
+
+// GetAPIScopes lists the scopes service accounts can be granted
+func (s *Server) GetAPIScopes(c *gin.Context) {
+	names := make([]string, 0, len(auth.APIScopes))
+	for name := range auth.APIScopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	scopes := []gin.H{}
+	for _, name := range names {
+		scope := auth.APIScopes[name]
+		scopes = append(scopes, gin.H{"name": name, "description": scope.Description, "routes": scope.Routes})
+	}
+	c.JSON(http.StatusOK, scopes)
+}
+
+// GetServiceAccounts lists the tenant's service accounts
+func (s *Server) GetServiceAccounts(c *gin.Context) {
+	rows, err := s.db.Pool.Query(c.Request.Context(), serviceAccountColumns+"WHERE tenant_id = $1 ORDER BY name", tenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service accounts"})
+		return
+	}
+	defer rows.Close()
+
+	accounts := []models.ServiceAccount{}
+	for rows.Next() {
+		var sa models.ServiceAccount
+		if err := scanServiceAccount(rows, &sa); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan service account"})
+			return
+		}
+		accounts = append(accounts, sa)
+	}
+	c.JSON(http.StatusOK, accounts)
+}
+
+// CreateServiceAccount adds a service account to the tenant; it has no keys until one is created
+func (s *Server) CreateServiceAccount(c *gin.Context) {
+	var req models.CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if invalid := invalidScopes(req.Scopes); len(invalid) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scopes", "scopes": invalid})
+		return
+	}
+	createdBy, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	var sa models.ServiceAccount
+	err := scanServiceAccount(s.db.Pool.QueryRow(c.Request.Context(), `
+		INSERT INTO service_accounts (tenant_id, name, description, scopes, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, name, description, scopes, created_by, created_at, updated_at, disabled_at
+	`, tenantID(c), req.Name, strings.TrimSpace(req.Description), req.Scopes, createdBy), &sa)
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A service account with this name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sa)
+}
+
+// GetServiceAccount returns a service account with all its keys
+func (s *Server) GetServiceAccount(c *gin.Context) {
+	sa, ok := s.serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	keys, err := s.apiKeys.Keys(c.Request.Context(), sa.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+	sa.Keys = keys
+	c.JSON(http.StatusOK, sa)
+}
+
+// UpdateServiceAccount renames a service account, changes its scopes, or disables it, which
+// stops all its keys without revoking them
+func (s *Server) UpdateServiceAccount(c *gin.Context) {
+	sa, ok := s.serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	var req models.UpdateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Name != nil {
+		if *req.Name = strings.TrimSpace(*req.Name); *req.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+			return
+		}
+	}
+	if invalid := invalidScopes(req.Scopes); len(invalid) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scopes", "scopes": invalid})
+		return
+	}
+
+	err := scanServiceAccount(s.db.Pool.QueryRow(c.Request.Context(), `
+		UPDATE service_accounts
+		SET name = COALESCE($1, name),
+			description = COALESCE($2, description),
+			scopes = COALESCE($3::text[], scopes),
+			disabled_at = CASE WHEN $4::boolean IS NULL THEN disabled_at
+							   WHEN $4 THEN COALESCE(disabled_at, NOW()) END,
+			updated_at = NOW()
+		WHERE id = $5
+		RETURNING id, name, description, scopes, created_by, created_at, updated_at, disabled_at
+	`, req.Name, req.Description, req.Scopes, req.Disabled, sa.ID), sa)
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A service account with this name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sa)
+}
+
+// DeleteServiceAccount removes a service account together with its keys
+func (s *Server) DeleteServiceAccount(c *gin.Context) {
+	sa, ok := s.serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	if _, err := s.db.Pool.Exec(c.Request.Context(), "DELETE FROM service_accounts WHERE id = $1", sa.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service account"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Service account deleted"})
+}
+
+// CreateAPIKey issues a key for a service account. Only this response carries the plaintext key.
+func (s *Server) CreateAPIKey(c *gin.Context) {
+	sa, ok := s.serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ExpiresInDays < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days cannot be negative"})
+		return
+	}
+	createdBy, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	key, err := s.apiKeys.Create(c.Request.Context(), sa.ID, createdBy, keyExpiry(req.ExpiresInDays))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+// RotateAPIKey replaces a key with a new one. The old key keeps working for grace_hours so
+// the integration can switch over, or stops at once without a grace period.
+func (s *Server) RotateAPIKey(c *gin.Context) {
+	sa, ok := s.serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(c.Param("keyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+	var req models.RotateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	grace := time.Duration(req.GraceHours) * time.Hour
+	if req.ExpiresInDays < 0 || grace < 0 || grace > maxKeyGrace {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days cannot be negative and grace_hours must be between 0 and 720"})
+		return
+	}
+	createdBy, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	key, err := s.apiKeys.Rotate(c.Request.Context(), sa.ID, keyID, createdBy, keyExpiry(req.ExpiresInDays), grace)
+	if err == auth.ErrAPIKeyNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No usable API key with this ID"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate API key"})
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey stops a key from working at once
+func (s *Server) RevokeAPIKey(c *gin.Context) {
+	sa, ok := s.serviceAccountParam(c)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(c.Param("keyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	err = s.apiKeys.Revoke(c.Request.Context(), sa.ID, keyID)
+	if err == auth.ErrAPIKeyNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found or already revoked"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"rpms-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentSession returns the session of the access token the request was made with
+func currentSession(c *gin.Context) uuid.UUID {
+	id, _ := uuid.Parse(c.GetString("session_id"))
+	return id
+}
+
+// GetSessions lists where the caller is signed in
+func (s *Server) GetSessions(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := s.tokens.Sessions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	current := currentSession(c)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession signs the caller out of one of their sessions, which may be the current one
+func (s *Server) RevokeSession(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	err = s.tokens.RevokeUserSession(c.Request.Context(), userID, sessionID)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session ended"})
+}
+
+// RevokeOtherSessions signs the caller out everywhere except the current session
+func (s *Server) RevokeOtherSessions(c *gin.Context) {
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+	current := currentSession(c)
+	if current == uuid.Nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This token has no session; sign in again or use logout with all"})
+		return
+	}
+
+	n, err := s.tokens.RevokeOtherSessions(c.Request.Context(), userID, current)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions ended", "revoked": n})
+}
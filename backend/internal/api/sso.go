@@ -0,0 +1,263 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/config"
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/oidc"
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ssoAttemptTTL is how long a user has to sign in at the provider and come back
+const ssoAttemptTTL = 10 * time.Minute
+
+func ssoConfig(cfg *config.Config) oidc.Config {
+	return oidc.Config{
+		Issuer:       cfg.OIDC.Issuer,
+		ClientID:     cfg.OIDC.ClientID,
+		ClientSecret: cfg.OIDC.ClientSecret,
+		RedirectURL:  cfg.OIDC.RedirectURL,
+		Scopes:       cfg.OIDC.Scopes,
+	}
+}
+
+// ssoRoleMapping reads the configured role mapping. users.role only holds built-in roles, so
+// rules for other roles are dropped with a warning rather than failing every sign in.
+func ssoRoleMapping(cfg *config.Config) oidc.RoleMapping {
+	mapping := oidc.RoleMapping{Claim: cfg.OIDC.RoleClaim, Default: cfg.OIDC.DefaultRole}
+	if !rbac.IsBuiltIn(mapping.Default) {
+		fmt.Printf("OIDC_DEFAULT_ROLE %q is not a built-in role, using %q\n", mapping.Default, rbac.RoleAuthor)
+		mapping.Default = rbac.RoleAuthor
+	}
+
+	rules, err := oidc.ParseRoleMap(cfg.OIDC.RoleMap)
+	if err != nil {
+		fmt.Printf("Ignoring OIDC_ROLE_MAP: %v\n", err)
+		return mapping
+	}
+	for _, rule := range rules {
+		if !rbac.IsBuiltIn(rule.Role) {
+			fmt.Printf("Ignoring OIDC_ROLE_MAP rule %s=%s: not a built-in role\n", rule.Value, rule.Role)
+			continue
+		}
+		mapping.Rules = append(mapping.Rules, rule)
+	}
+	return mapping
+}
+
+// StartSSO begins a single sign-on. The frontend sends the user to the returned URL; the
+// provider sends them back to the configured redirect URL with a code and the state.
+func (s *Server) StartSSO(c *gin.Context) {
+	if !s.sso.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	var state, nonce, verifier string
+	for _, v := range []*string{&state, &nonce, &verifier} {
+		random, err := oidc.RandomString()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+			return
+		}
+		*v = random
+	}
+
+	ctx := c.Request.Context()
+	authURL, err := s.sso.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		fmt.Printf("Failed to discover the OpenID provider: %v\n", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach the identity provider"})
+		return
+	}
+
+	// Abandoned attempts are cleared as new ones start
+	if _, err := s.db.Pool.Exec(ctx, "DELETE FROM oidc_login_states WHERE expires_at < NOW()"); err != nil {
+		fmt.Printf("Failed to clear expired sign in attempts: %v\n", err)
+	}
+	_, err = s.db.Pool.Exec(ctx, `
+		INSERT INTO oidc_login_states (state_hash, tenant_id, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, auth.HashCode(state), tenantID(c), nonce, verifier, time.Now().Add(ssoAttemptTTL))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authURL, "state": state})
+}
+
+// FinishSSO completes a single sign-on with the code the provider returned. The account is
+// found by its provider identity, linked by verified email, or created on first sign in.
+func (s *Server) FinishSSO(c *gin.Context) {
+	var req models.SSOCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var attemptTenant uuid.UUID
+	var nonce, verifier string
+	var expiresAt time.Time
+	// Each state is used once, whether or not the sign in succeeds
+	err := s.db.Pool.QueryRow(ctx, `
+		DELETE FROM oidc_login_states WHERE state_hash = $1
+		RETURNING tenant_id, nonce, code_verifier, expires_at
+	`, auth.HashCode(req.State)).Scan(&attemptTenant, &nonce, &verifier, &expiresAt)
+	if err == pgx.ErrNoRows || (err == nil && time.Now().After(expiresAt)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown or expired sign in attempt, please start again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to finish single sign-on"})
+		return
+	}
+	if !accountInTenant(c, attemptTenant) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This sign in was started for another institution"})
+		return
+	}
+
+	claims, err := s.sso.SignIn(ctx, req.Code, verifier, nonce)
+	if errors.Is(err, oidc.ErrExchangeFailed) || errors.Is(err, oidc.ErrInvalidIDToken) {
+		fmt.Printf("Single sign-on rejected: %v\n", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Single sign-on failed"})
+		return
+	}
+	if err != nil {
+		fmt.Printf("Single sign-on failed: %v\n", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach the identity provider"})
+		return
+	}
+
+	user, ok := s.ssoAccount(c, claims)
+	if !ok {
+		return
+	}
+	s.loginSucceeded(c, user)
+	s.completeSignIn(c, user)
+}
+
+// ssoAccount returns the account of a provider identity, writing the response when there is
+// none it may sign in to. Accounts created by single sign-on follow the role mapping on every
+// sign in; linked accounts keep the role they had.
+func (s *Server) ssoAccount(c *gin.Context, claims *oidc.Claims) (*models.User, bool) {
+	ctx := c.Request.Context()
+	issuer := s.sso.Issuer()
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return nil, false
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	var provisioned, roleChanged bool
+	err = tx.QueryRow(ctx,
+		"SELECT user_id, provisioned FROM user_identities WHERE issuer = $1 AND subject = $2",
+		issuer, claims.Subject).Scan(&userID, &provisioned)
+	switch {
+	case err == nil:
+		if provisioned {
+			tag, err := tx.Exec(ctx, "UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2 AND role <> $1",
+				s.ssoRoles.Role(claims.Raw), userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+				return nil, false
+			}
+			roleChanged = tag.RowsAffected() > 0
+		}
+
+	case err == pgx.ErrNoRows:
+		// Without a verified email an identity could claim someone else's account
+		if claims.Email == "" || !claims.EmailVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Your identity provider did not confirm an email address"})
+			return nil, false
+		}
+
+		err = tx.QueryRow(ctx, "SELECT id FROM users WHERE LOWER(email) = LOWER($1)", claims.Email).Scan(&userID)
+		switch {
+		case err == nil:
+			// The provider has confirmed the address, which settles any pending verification
+			if _, err := tx.Exec(ctx,
+				"UPDATE users SET is_verified = TRUE, reverification_required = FALSE, updated_at = NOW() WHERE id = $1", userID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link account"})
+				return nil, false
+			}
+		case err == pgx.ErrNoRows:
+			userID = uuid.New()
+			provisioned = true
+			// No password: the account signs in through the provider only
+			_, err = tx.Exec(ctx, `
+				INSERT INTO users (id, email, password_hash, name, role, is_verified, preferences, tenant_id)
+				VALUES ($1, $2, '', $3, $4, TRUE, '{}', $5)
+			`, userID, claims.Email, claims.Name, s.ssoRoles.Role(claims.Raw), tenantID(c))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+				return nil, false
+			}
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			return nil, false
+		}
+
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_identities (user_id, issuer, subject, email, provisioned)
+			VALUES ($1, $2, $3, $4, $5)
+		`, userID, issuer, claims.Subject, claims.Email, provisioned); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link account"})
+			return nil, false
+		}
+
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch identity"})
+		return nil, false
+	}
+
+	// A linked account of another institution is refused here, before anything is committed
+	var active bool
+	var accountTenant uuid.UUID
+	if err := tx.QueryRow(ctx, "SELECT is_active, tenant_id FROM users WHERE id = $1", userID).Scan(&active, &accountTenant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return nil, false
+	}
+	if !accountInTenant(c, accountTenant) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account belongs to another institution"})
+		return nil, false
+	}
+	if !active {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been deactivated", "deactivated": true})
+		return nil, false
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE user_identities SET last_login_at = NOW(), email = $3 WHERE issuer = $1 AND subject = $2",
+		issuer, claims.Subject, claims.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return nil, false
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return nil, false
+	}
+	if roleChanged {
+		s.permissions.Invalidate()
+	}
+
+	user, err := s.sessionUser(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,146 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/oidc/oidctest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// setupSSOServer starts the API with single sign-on through a test provider
+func setupSSOServer(t *testing.T) (*gin.Engine, *oidctest.Provider) {
+	provider := oidctest.New(t)
+	t.Setenv("OIDC_ISSUER", provider.Issuer())
+	t.Setenv("OIDC_CLIENT_ID", oidctest.ClientID)
+	t.Setenv("OIDC_CLIENT_SECRET", oidctest.ClientSecret)
+	t.Setenv("OIDC_REDIRECT_URL", oidctest.RedirectURL)
+	t.Setenv("OIDC_ROLE_CLAIM", "groups")
+	t.Setenv("OIDC_ROLE_MAP", "journal-editors=editor,research-office=coordinator")
+	t.Setenv("OIDC_DEFAULT_ROLE", "author")
+
+	router, db := setupTestServer(t)
+	t.Cleanup(db.Close)
+	return router, provider
+}
+
+func ssoRequest(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// ssoSignIn signs in at the provider as user and posts the result back to the API
+func ssoSignIn(t *testing.T, router *gin.Engine, provider *oidctest.Provider, user jwt.MapClaims) *httptest.ResponseRecorder {
+	t.Helper()
+	w := ssoRequest(router, "GET", "/api/v1/auth/oidc/login", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("starting sign in: %d %s", w.Code, w.Body.String())
+	}
+	var start struct {
+		AuthorizationURL string `json:"authorization_url"`
+		State            string `json:"state"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &start)
+
+	provider.SetUser(user)
+	code, state := provider.Authorize(start.AuthorizationURL)
+	if state != start.State {
+		t.Fatalf("state %q came back as %q", start.State, state)
+	}
+	return ssoRequest(router, "POST", "/api/v1/auth/oidc/callback", models.SSOCallbackRequest{Code: code, State: state})
+}
+
+func signedInUser(t *testing.T, w *httptest.ResponseRecorder) models.User {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("sign in: %d %s", w.Code, w.Body.String())
+	}
+	var resp models.LoginResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Token == "" {
+		t.Fatalf("sign in response %s", w.Body.String())
+	}
+	return resp.User
+}
+
+func TestSSOProvisionsAndMapsRoles(t *testing.T) {
+	router, provider := setupSSOServer(t)
+	subject := fmt.Sprintf("staff-%d", time.Now().UnixNano())
+	email := subject + "@test.com"
+
+	user := signedInUser(t, ssoSignIn(t, router, provider, jwt.MapClaims{
+		"sub": subject, "email": email, "email_verified": true, "name": "Hana Tesfaye",
+		"groups": []string{"staff", "journal-editors"},
+	}))
+	if user.Email != email || user.Name != "Hana Tesfaye" || user.Role != "editor" {
+		t.Errorf("provisioned user = %s %q %s, want %s as editor", user.Email, user.Name, user.Role, email)
+	}
+
+	// A provisioned account follows the provider's groups
+	again := signedInUser(t, ssoSignIn(t, router, provider, jwt.MapClaims{
+		"sub": subject, "email": email, "email_verified": true, "groups": []string{"staff"},
+	}))
+	if again.ID != user.ID || again.Role != "author" {
+		t.Errorf("second sign in: user %s as %s, want %s as author", again.ID, again.Role, user.ID)
+	}
+}
+
+func TestSSOLinksExistingAccount(t *testing.T) {
+	router, provider := setupSSOServer(t)
+	email := fmt.Sprintf("linked_%d@test.com", time.Now().UnixNano())
+
+	w := ssoRequest(router, "POST", "/api/v1/auth/register", models.CreateUserRequest{
+		Name: "Linked Author", Email: email, Password: "password123", Role: "author",
+		AcademicYear: "2024", AuthorType: "Academic Staff", AuthorCategory: "Researcher",
+		AcademicRank: "Lecturer", Qualification: "PhD", EmploymentType: "Full Time", Gender: "Female",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register: %d %s", w.Code, w.Body.String())
+	}
+
+	// Without a verified email the identity cannot claim the account
+	w = ssoSignIn(t, router, provider, jwt.MapClaims{"sub": "unverified-" + email, "email": email, "email_verified": false})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("unverified email: status %d, want 403", w.Code)
+	}
+
+	// Linked accounts keep their role whatever the provider's groups say
+	user := signedInUser(t, ssoSignIn(t, router, provider, jwt.MapClaims{
+		"sub": "linked-" + email, "email": email, "email_verified": true, "groups": []string{"research-office"},
+	}))
+	if user.Email != email || user.Role != "author" {
+		t.Errorf("linked user = %s as %s, want %s as author", user.Email, user.Role, email)
+	}
+}
+
+func TestSSOStateIsSingleUse(t *testing.T) {
+	router, provider := setupSSOServer(t)
+	w := ssoRequest(router, "GET", "/api/v1/auth/oidc/login", nil)
+	var start struct {
+		AuthorizationURL string `json:"authorization_url"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &start)
+
+	provider.SetUser(jwt.MapClaims{"sub": fmt.Sprintf("replay-%d", time.Now().UnixNano())})
+	code, state := provider.Authorize(start.AuthorizationURL)
+	ssoRequest(router, "POST", "/api/v1/auth/oidc/callback", models.SSOCallbackRequest{Code: code, State: state})
+
+	w = ssoRequest(router, "POST", "/api/v1/auth/oidc/callback", models.SSOCallbackRequest{Code: code, State: state})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("replayed state: status %d, want 400", w.Code)
+	}
+}
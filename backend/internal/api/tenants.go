@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"rpms-backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// tenantID returns the tenant resolved for the request by TenantMiddleware
+func tenantID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString("tenant_id"))
+	if err != nil {
+		return tenant.DefaultID
+	}
+	return id
+}
+
+// currentTenant returns the resolved tenant, or a bare default tenant when the
+// middleware did not run
+func currentTenant(c *gin.Context) *tenant.Tenant {
+	if t, ok := c.Get(tenant.ContextKey); ok {
+		if current, ok := t.(*tenant.Tenant); ok {
+			return current
+		}
+	}
+	return tenant.Default()
+}
+
+// accountInTenant reports whether an account may sign in through this request. On a
+// tenant's own hostname the account must belong to that tenant; on shared hostnames
+// the account's tenant is carried into its token instead.
+func accountInTenant(c *gin.Context, accountTenant uuid.UUID) bool {
+	return !c.GetBool("tenant_from_host") || accountTenant == tenantID(c)
+}
+
+// TenantScoped rejects requests whose :id refers to a row of another tenant. It responds
+// with 404 so that IDs from other universities cannot be probed.
+func (s *Server) TenantScoped(table string) gin.HandlerFunc {
+	query := fmt.Sprintf("SELECT tenant_id FROM %s WHERE id::text = $1", pgx.Identifier{table}.Sanitize())
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if id == "" {
+			c.Next()
+			return
+		}
+
+		var rowTenant uuid.UUID
+		err := s.db.Pool.QueryRow(c.Request.Context(), query, id).Scan(&rowTenant)
+		if err != nil && err != pgx.ErrNoRows {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch resource"})
+			c.Abort()
+			return
+		}
+		// Missing rows are left for the handler to report
+		if err == nil && rowTenant != tenantID(c) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetTenant returns the public branding of the tenant serving the request
+func (s *Server) GetTenant(c *gin.Context) {
+	t := currentTenant(c)
+	c.JSON(http.StatusOK, gin.H{
+		"id":            t.ID,
+		"slug":          t.Slug,
+		"name":          t.Name,
+		"display_name":  t.DisplayName(),
+		"logo_url":      t.Config.Branding.LogoURL,
+		"primary_color": t.Config.Branding.PrimaryColor,
+	})
+}
+
+// GetTenantConfig returns the full configuration of the admin's tenant
+func (s *Server) GetTenantConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, currentTenant(c))
+}
+
+// UpdateTenantConfig replaces the configuration of the admin's tenant
+func (s *Server) UpdateTenantConfig(c *gin.Context) {
+	var req tenant.Config
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.PublicationIDStart < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "publication_id_start must not be negative"})
+		return
+	}
+
+	t := *currentTenant(c)
+	t.Config = req
+	_, err := s.db.Pool.Exec(c.Request.Context(),
+		"UPDATE tenants SET config = $1, updated_at = NOW() WHERE id = $2", t.Config, t.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tenant"})
+		return
+	}
+	if s.tenants != nil {
+		s.tenants.Invalidate()
+	}
+
+	c.JSON(http.StatusOK, t)
+}
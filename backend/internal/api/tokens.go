@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// requestClient describes the client making the request, for its session
+func requestClient(c *gin.Context) auth.Client {
+	return auth.Client{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// signIn starts a new session for the user and issues its first access and refresh tokens
+func (s *Server) signIn(c *gin.Context, user *models.User) (*models.LoginResponse, error) {
+	sessionID, refresh, err := s.tokens.Issue(c.Request.Context(), user.ID, requestClient(c))
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.jwtManager.GenerateToken(user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		User:         *user,
+		Token:        token,
+		ExpiresIn:    int(s.jwtManager.Expiry().Seconds()),
+		RefreshToken: refresh,
+		SessionID:    sessionID,
+	}, nil
+}
+
+// sessionUser loads the user fields that go into a sign in response
+func (s *Server) sessionUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT id, email, password_hash, name, role, avatar, bio, preferences, created_at, updated_at, tenant_id
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Avatar, &user.Bio, &user.Preferences, &user.CreatedAt, &user.UpdatedAt, &user.TenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new refresh token.
+// Claims are rebuilt from the users table so role changes take effect on the next refresh.
+func (s *Server) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// On an institution's own hostname only its accounts may refresh; others keep their token
+	var tenant *uuid.UUID
+	if c.GetBool("tenant_from_host") {
+		id := tenantID(c)
+		tenant = &id
+	}
+
+	ctx := c.Request.Context()
+	userID, sessionID, refresh, err := s.tokens.Rotate(ctx, req.RefreshToken, requestClient(c), tenant)
+	if errors.Is(err, auth.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used; please sign in again"})
+		return
+	}
+	if errors.Is(err, auth.ErrInvalidRefreshToken) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if errors.Is(err, auth.ErrOtherTenant) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account belongs to another institution"})
+		return
+	}
+	if errors.Is(err, auth.ErrAccountDeactivated) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been deactivated", "deactivated": true})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	user, err := s.sessionUser(ctx, userID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User no longer exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	token, err := s.jwtManager.GenerateToken(user, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginResponse{
+		User:         *user,
+		Token:        token,
+		ExpiresIn:    int(s.jwtManager.Expiry().Seconds()),
+		RefreshToken: refresh,
+		SessionID:    sessionID,
+	})
+}
+
+// Logout revokes the presented access token and its session or refresh token family. It does not
+// require a valid access token, so that a client holding only a refresh token can still
+// end its session.
+func (s *Server) Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	var claims *auth.Claims
+	if parts := strings.Split(c.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+		if parsed, err := s.jwtManager.ValidateToken(parts[1]); err == nil {
+			claims = parsed
+		}
+	}
+	if claims == nil && req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An access token or a refresh token is required"})
+		return
+	}
+
+	if claims != nil {
+		if err := s.tokens.RevokeAccessToken(ctx, claims); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+			return
+		}
+		if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+			if err := s.tokens.RevokeSession(ctx, sessionID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end session"})
+				return
+			}
+		}
+	}
+	if req.RefreshToken != "" {
+		if err := s.tokens.RevokeFamily(ctx, req.RefreshToken); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
+			return
+		}
+	}
+	if req.All {
+		if claims == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "A valid access token is required to sign out everywhere"})
+			return
+		}
+		userID, err := uuid.Parse(claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		if err := s.tokens.RevokeUser(ctx, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
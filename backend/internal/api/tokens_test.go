@@ -0,0 +1,126 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rpms-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func postJSON(router *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	buf, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func getProfile(router *gin.Engine, token string) int {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func login(t *testing.T, router *gin.Engine, email, password string) models.LoginResponse {
+	t.Helper()
+	w := postJSON(router, "/api/v1/auth/login", map[string]string{"email": email, "password": password})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", w.Code, w.Body.String())
+	}
+	var resp models.LoginResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	router, db := setupTestServer(t)
+	defer db.Close()
+
+	email := fmt.Sprintf("refresh_%d@test.com", time.Now().UnixNano())
+	insertTestUser(t, router, db, email, "password123", "author")
+	defer db.Pool.Exec(context.Background(), "DELETE FROM users WHERE email = $1", email)
+
+	first := login(t, router, email, "password123")
+
+	w := postJSON(router, "/api/v1/auth/refresh", models.RefreshTokenRequest{RefreshToken: first.RefreshToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("refresh: %d %s", w.Code, w.Body.String())
+	}
+	var second models.LoginResponse
+	json.Unmarshal(w.Body.Bytes(), &second)
+	if second.RefreshToken == first.RefreshToken || second.SessionID != first.SessionID {
+		t.Fatalf("refresh should rotate the token within the session: %+v", second)
+	}
+	if code := getProfile(router, second.Token); code != http.StatusOK {
+		t.Fatalf("refreshed access token: status %d", code)
+	}
+
+	// Presenting the rotated token again ends the whole family
+	if w := postJSON(router, "/api/v1/auth/refresh", models.RefreshTokenRequest{RefreshToken: first.RefreshToken}); w.Code != http.StatusUnauthorized {
+		t.Errorf("reused refresh token: status %d, want 401", w.Code)
+	}
+	if w := postJSON(router, "/api/v1/auth/refresh", models.RefreshTokenRequest{RefreshToken: second.RefreshToken}); w.Code != http.StatusUnauthorized {
+		t.Errorf("newest token of a revoked family: status %d, want 401", w.Code)
+	}
+	if code := getProfile(router, second.Token); code != http.StatusUnauthorized {
+		t.Errorf("access token of a revoked session: status %d, want 401", code)
+	}
+}
+
+func TestRefreshTokenOfDeactivatedAccount(t *testing.T) {
+	router, db := setupTestServer(t)
+	defer db.Close()
+
+	email := fmt.Sprintf("deactivated_%d@test.com", time.Now().UnixNano())
+	userID := insertTestUser(t, router, db, email, "password123", "author")
+	defer db.Pool.Exec(context.Background(), "DELETE FROM users WHERE email = $1", email)
+
+	session := login(t, router, email, "password123")
+	if _, err := db.Pool.Exec(context.Background(), "UPDATE users SET is_active = FALSE WHERE id = $1", userID); err != nil {
+		t.Fatal(err)
+	}
+
+	w := postJSON(router, "/api/v1/auth/refresh", models.RefreshTokenRequest{RefreshToken: session.RefreshToken})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("refresh of a deactivated account: status %d, want 403", w.Code)
+	}
+	if code := getProfile(router, session.Token); code != http.StatusUnauthorized {
+		t.Errorf("access token of a deactivated account: status %d, want 401", code)
+	}
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	router, db := setupTestServer(t)
+	defer db.Close()
+
+	email := fmt.Sprintf("logout_%d@test.com", time.Now().UnixNano())
+	insertTestUser(t, router, db, email, "password123", "author")
+	defer db.Pool.Exec(context.Background(), "DELETE FROM users WHERE email = $1", email)
+
+	session := login(t, router, email, "password123")
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+session.Token)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("logout: %d %s", w.Code, w.Body.String())
+	}
+
+	if code := getProfile(router, session.Token); code != http.StatusUnauthorized {
+		t.Errorf("access token after logout: status %d, want 401", code)
+	}
+	if w := postJSON(router, "/api/v1/auth/refresh", models.RefreshTokenRequest{RefreshToken: session.RefreshToken}); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh token after logout: status %d, want 401", w.Code)
+	}
+}
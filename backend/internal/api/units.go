@@ -0,0 +1,691 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// unitSubtree expands the unit IDs bound to the given parameter into those units and everything below them
+const unitSubtree = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM org_units WHERE id = ANY($%d::uuid[])
+		UNION
+		SELECT u.id FROM org_units u JOIN subtree s ON u.parent_id = s.id
+	)
+	SELECT id FROM subtree`
+
+// inUnitSubtree returns a condition matching column against the subtree of the units bound to parameter argIndex
+func inUnitSubtree(column string, argIndex int) string {
+	return fmt.Sprintf("%s IN (%s)", column, fmt.Sprintf(unitSubtree, argIndex))
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// staffUnitScope returns the units staff who read all papers or reviews are restricted to,
+// whichever role grants them that. A nil scope means unrestricted: those who manage the unit
+// tree, authors (scoped by ownership instead) and staff without affiliations.
+func (s *Server) staffUnitScope(c *gin.Context) ([]uuid.UUID, error) {
+	if s.can(c, rbac.UnitManage) || !(s.can(c, rbac.PaperReadAll) || s.can(c, rbac.ReviewReadAll)) {
+		return nil, nil
+	}
+
+	rows, err := s.db.Pool.Query(c.Request.Context(), "SELECT unit_id FROM user_affiliations WHERE user_id = $1", c.GetString("user_id"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var units []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		units = append(units, id)
+	}
+	return units, rows.Err()
+}
+
+// requirePaperScope rejects the request unless the paper belongs to the caller's tenant and
+// falls within the caller's units. Papers not attributed to any unit stay visible to all staff.
+func (s *Server) requirePaperScope(c *gin.Context, paperID uuid.UUID) bool {
+	scope, err := s.staffUnitScope(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+		return false
+	}
+
+	var allowed bool
+	err = s.db.Pool.QueryRow(c.Request.Context(),
+		"SELECT $3::uuid[] IS NULL OR p.unit_id IS NULL OR "+inUnitSubtree("p.unit_id", 3)+" FROM papers p WHERE p.id = $1 AND p.tenant_id = $2",
+		paperID, tenantID(c), scope).Scan(&allowed)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This paper is outside your units"})
+		return false
+	}
+	return true
+}
+
+// requireUnitScope rejects the request unless the unit belongs to the caller's tenant and is
+// one of the caller's units or below them
+func (s *Server) requireUnitScope(c *gin.Context, unitID uuid.UUID) bool {
+	scope, err := s.staffUnitScope(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+		return false
+	}
+
+	var allowed bool
+	err = s.db.Pool.QueryRow(c.Request.Context(),
+		"SELECT $3::uuid[] IS NULL OR "+inUnitSubtree("id", 3)+" FROM org_units WHERE id = $1 AND tenant_id = $2",
+		unitID, tenantID(c), scope).Scan(&allowed)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve unit scope"})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This unit is outside your units"})
+		return false
+	}
+	return true
+}
+
+const unitColumns = "id, parent_id, type, name, COALESCE(code, ''), created_at, updated_at"
+
+func scanUnit(row pgx.Row, u *models.OrgUnit) error {
+	return row.Scan(&u.ID, &u.ParentID, &u.Type, &u.Name, &u.Code, &u.CreatedAt, &u.UpdatedAt)
+}
+
+func (s *Server) getUnit(ctx context.Context, tenantID, unitID uuid.UUID) (*models.OrgUnit, error) {
+	var unit models.OrgUnit
+	err := scanUnit(s.db.Pool.QueryRow(ctx, "SELECT "+unitColumns+" FROM org_units WHERE id = $1 AND tenant_id = $2", unitID, tenantID), &unit)
+	if err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// GetUnits lists units, filtered by ?type and ?parent_id, or as a nested tree with ?tree=true
+func (s *Server) GetUnits(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	query := "SELECT " + unitColumns + " FROM org_units WHERE tenant_id = $1"
+	args := []interface{}{tenantID(c)}
+	if unitType := c.Query("type"); unitType != "" {
+		args = append(args, unitType)
+		query += fmt.Sprintf(" AND type = $%d", len(args))
+	}
+	if parentID := c.Query("parent_id"); parentID != "" {
+		id, err := uuid.Parse(parentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent ID"})
+			return
+		}
+		args = append(args, id)
+		query += fmt.Sprintf(" AND parent_id = $%d", len(args))
+	}
+	query += " ORDER BY name ASC"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch units"})
+		return
+	}
+	defer rows.Close()
+
+	units := []models.OrgUnit{}
+	for rows.Next() {
+		var unit models.OrgUnit
+		if err := scanUnit(rows, &unit); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan unit"})
+			return
+		}
+		units = append(units, unit)
+	}
+
+	if c.Query("tree") == "true" {
+		c.JSON(http.StatusOK, buildUnitTree(units))
+		return
+	}
+
+	c.JSON(http.StatusOK, units)
+}
+
+// buildUnitTree nests units under their parents; units whose parent is not in the list become roots
+func buildUnitTree(units []models.OrgUnit) []models.OrgUnit {
+	present := map[uuid.UUID]bool{}
+	children := map[uuid.UUID][]models.OrgUnit{}
+	for _, u := range units {
+		present[u.ID] = true
+	}
+
+	var roots []models.OrgUnit
+	for _, u := range units {
+		if u.ParentID != nil && present[*u.ParentID] {
+			children[*u.ParentID] = append(children[*u.ParentID], u)
+		} else {
+			roots = append(roots, u)
+		}
+	}
+
+	var attach func(list []models.OrgUnit) []models.OrgUnit
+	attach = func(list []models.OrgUnit) []models.OrgUnit {
+		for i := range list {
+			list[i].Children = attach(children[list[i].ID])
+		}
+		return list
+	}
+	return attach(roots)
+}
+
+func (s *Server) GetUnit(c *gin.Context) {
+	unitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+		return
+	}
+
+	unit, err := s.getUnit(c.Request.Context(), tenantID(c), unitID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, unit)
+}
+
+func (s *Server) CreateUnit(c *gin.Context) {
+	var req models.CreateUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// An institution is a root; colleges sit under institutions and departments under colleges
+	wantParent := models.ParentUnitType(req.Type)
+	if wantParent == "" && req.ParentID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An institution cannot have a parent unit"})
+		return
+	}
+	if wantParent != "" {
+		if req.ParentID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A %s must belong to a %s", req.Type, wantParent)})
+			return
+		}
+		parent, err := s.getUnit(ctx, tenantID(c), *req.ParentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent unit not found"})
+			return
+		}
+		if parent.Type != wantParent {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A %s must belong to a %s, not a %s", req.Type, wantParent, parent.Type)})
+			return
+		}
+	}
+
+	var unit models.OrgUnit
+	err := scanUnit(s.db.Pool.QueryRow(ctx, `
+		INSERT INTO org_units (parent_id, type, name, code, tenant_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING `+unitColumns,
+		req.ParentID, req.Type, strings.TrimSpace(req.Name), strings.TrimSpace(req.Code), tenantID(c)), &unit)
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A unit with this code already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create unit"})
+		return
+	}
+
+	// Attach papers still carrying this institution's code
+	if unit.Type == models.UnitInstitution && unit.Code != "" {
+		s.db.Pool.Exec(ctx, "UPDATE papers SET unit_id = $1 WHERE unit_id IS NULL AND institution_code = $2 AND tenant_id = $3", unit.ID, unit.Code, tenantID(c))
+	}
+
+	c.JSON(http.StatusCreated, unit)
+}
+
+func (s *Server) UpdateUnit(c *gin.Context) {
+	unitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+		return
+	}
+
+	var req models.UpdateUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var unit models.OrgUnit
+	err = scanUnit(s.db.Pool.QueryRow(c.Request.Context(), `
+		UPDATE org_units SET name = $1, code = NULLIF($2, ''), updated_at = NOW()
+		WHERE id = $3
+		RETURNING `+unitColumns,
+		strings.TrimSpace(req.Name), strings.TrimSpace(req.Code), unitID), &unit)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		return
+	}
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A unit with this code already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update unit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, unit)
+}
+
+func (s *Server) DeleteUnit(c *gin.Context) {
+	unitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var children int
+	if err := s.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM org_units WHERE parent_id = $1", unitID).Scan(&children); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check the units below this one"})
+		return
+	}
+	if children > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Remove or move the units below this one first"})
+		return
+	}
+
+	result, err := s.db.Pool.Exec(ctx, "DELETE FROM org_units WHERE id = $1", unitID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete unit"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unit deleted successfully"})
+}
+
+// GetUnitMembers lists the users affiliated with a unit or any unit below it
+func (s *Server) GetUnitMembers(c *gin.Context) {
+	unitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+		return
+	}
+	if !s.requireUnitScope(c, unitID) {
+		return
+	}
+
+	rows, err := s.db.Pool.Query(c.Request.Context(), `
+		SELECT DISTINCT ON (u.id) u.id, u.name, u.email, u.role, COALESCE(u.academic_rank, ''), ua.unit_id, ou.name
+		FROM user_affiliations ua
+		JOIN users u ON u.id = ua.user_id
+		JOIN org_units ou ON ou.id = ua.unit_id
+		WHERE `+inUnitSubtree("ua.unit_id", 1)+`
+		ORDER BY u.id, ua.is_primary DESC
+	`, []uuid.UUID{unitID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch unit members"})
+		return
+	}
+	defer rows.Close()
+
+	members := []gin.H{}
+	for rows.Next() {
+		var id, memberUnitID uuid.UUID
+		var name, email, role, rank, unitName string
+		if err := rows.Scan(&id, &name, &email, &role, &rank, &memberUnitID, &unitName); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan unit member"})
+			return
+		}
+		members = append(members, gin.H{
+			"id": id, "name": name, "email": email, "role": role, "academic_rank": rank,
+			"unit_id": memberUnitID, "unit_name": unitName,
+		})
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+func (s *Server) listAffiliations(c *gin.Context, userID uuid.UUID) {
+	rows, err := s.db.Pool.Query(c.Request.Context(), `
+		SELECT ua.user_id, ua.unit_id, ou.name, ou.type, ua.is_primary, ua.created_at
+		FROM user_affiliations ua
+		JOIN org_units ou ON ou.id = ua.unit_id
+		WHERE ua.user_id = $1
+		ORDER BY ua.is_primary DESC, ou.name ASC
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch affiliations"})
+		return
+	}
+	defer rows.Close()
+
+	affiliations := []models.UserAffiliation{}
+	for rows.Next() {
+		var a models.UserAffiliation
+		if err := rows.Scan(&a.UserID, &a.UnitID, &a.UnitName, &a.UnitType, &a.IsPrimary, &a.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan affiliation"})
+			return
+		}
+		affiliations = append(affiliations, a)
+	}
+
+	c.JSON(http.StatusOK, affiliations)
+}
+
+// GetMyAffiliations lists the caller's own affiliations
+func (s *Server) GetMyAffiliations(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	s.listAffiliations(c, id)
+}
+
+func (s *Server) GetUserAffiliations(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	s.listAffiliations(c, userID)
+}
+
+// AddUserAffiliation affiliates a user with a unit, optionally making it their primary affiliation
+func (s *Server) AddUserAffiliation(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.AddAffiliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add affiliation"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if req.IsPrimary {
+		if _, err := tx.Exec(ctx, "UPDATE user_affiliations SET is_primary = FALSE WHERE user_id = $1", userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add affiliation"})
+			return
+		}
+	}
+
+	a := models.UserAffiliation{UserID: userID, UnitID: req.UnitID}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO user_affiliations (user_id, unit_id, is_primary)
+		SELECT u.id, ou.id, $3 FROM users u, org_units ou
+		WHERE u.id = $1 AND ou.id = $2 AND u.tenant_id = $4 AND ou.tenant_id = $4
+		ON CONFLICT (user_id, unit_id) DO UPDATE SET is_primary = EXCLUDED.is_primary
+		RETURNING is_primary, created_at, (SELECT name FROM org_units WHERE id = $2), (SELECT type FROM org_units WHERE id = $2)
+	`, userID, req.UnitID, req.IsPrimary, tenantID(c)).Scan(&a.IsPrimary, &a.CreatedAt, &a.UnitName, &a.UnitType)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User or unit not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add affiliation"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add affiliation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, a)
+}
+
+func (s *Server) RemoveUserAffiliation(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	unitID, err := uuid.Parse(c.Param("unitId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+		return
+	}
+
+	result, err := s.db.Pool.Exec(c.Request.Context(), "DELETE FROM user_affiliations WHERE user_id = $1 AND unit_id = $2", userID, unitID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove affiliation"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Affiliation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Affiliation removed"})
+}
+
+// AssignPaperUnit attributes a paper to a unit, or clears the attribution when unit_id is null
+func (s *Server) AssignPaperUnit(c *gin.Context) {
+	paperID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paper ID"})
+		return
+	}
+
+	var req models.AssignPaperUnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !s.requirePaperScope(c, paperID) {
+		return
+	}
+	if req.UnitID != nil && !s.requireUnitScope(c, *req.UnitID) {
+		return
+	}
+
+	var unitID *uuid.UUID
+	err = s.db.Pool.QueryRow(c.Request.Context(), `
+		UPDATE papers SET unit_id = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING unit_id
+	`, req.UnitID, paperID).Scan(&unitID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Paper not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign unit"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"paper_id": paperID, "unit_id": unitID})
+}
+
+// paperStats aggregates a tenant's papers, optionally restricted to the given units and everything below them
+func (s *Server) paperStats(ctx context.Context, tenantID uuid.UUID, unitIDs []uuid.UUID) (models.PaperStats, error) {
+	stats := models.PaperStats{ByStatus: map[string]int{}}
+
+	where := "p.deleted_at IS NULL AND p.tenant_id = $1"
+	args := []interface{}{tenantID}
+	if unitIDs != nil {
+		args = append(args, unitIDs)
+		where += " AND " + inUnitSubtree("p.unit_id", 2)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, "SELECT p.status, COUNT(*) FROM papers p WHERE "+where+" GROUP BY p.status", args...)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return stats, err
+		}
+		stats.ByStatus[status] = count
+		stats.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+	stats.Published = stats.ByStatus["published"]
+
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FILTER (WHERE p.type ILIKE '%project%'),
+			   COALESCE(SUM(COALESCE(p.allocated_budget, 0) + COALESCE(p.external_budget, 0) + COALESCE(p.nrf_fund, 0))
+				   FILTER (WHERE p.type ILIKE '%project%'), 0)::float8,
+			   COUNT(DISTINCT p.author_id),
+			   (SELECT COUNT(*) FROM reviews r JOIN papers p ON r.paper_id = p.id WHERE r.status != 'draft' AND `+where+`)
+		FROM papers p
+		WHERE `+where, args...).Scan(&stats.Projects, &stats.Funding, &stats.DistinctAuthors, &stats.Reviews)
+	return stats, err
+}
+
+// GetUnitStats returns a unit's statistics rolled up over everything below it, with a drill-down into its direct children
+func (s *Server) GetUnitStats(c *gin.Context) {
+	unitID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+		return
+	}
+	if !s.requireUnitScope(c, unitID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	unit, err := s.getUnit(ctx, tenantID(c), unitID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		return
+	}
+
+	result := models.UnitStats{Unit: *unit, Children: []models.UnitStats{}}
+	result.Stats, err = s.paperStats(ctx, tenantID(c), []uuid.UUID{unit.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch unit statistics"})
+		return
+	}
+
+	rows, err := s.db.Pool.Query(ctx, "SELECT "+unitColumns+" FROM org_units WHERE parent_id = $1 ORDER BY name ASC", unit.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch unit statistics"})
+		return
+	}
+	var children []models.OrgUnit
+	for rows.Next() {
+		var child models.OrgUnit
+		if err := scanUnit(rows, &child); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan unit"})
+			return
+		}
+		children = append(children, child)
+	}
+	rows.Close()
+
+	for _, child := range children {
+		stats, err := s.paperStats(ctx, tenantID(c), []uuid.UUID{child.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch unit statistics"})
+			return
+		}
+		result.Children = append(result.Children, models.UnitStats{Unit: child, Stats: stats})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAdminStats returns tenant-wide statistics, or those of a single unit with ?unit_id
+func (s *Server) GetAdminStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var unitIDs []uuid.UUID
+	if raw := c.Query("unit_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid unit ID"})
+			return
+		}
+		unitIDs = []uuid.UUID{id}
+	}
+
+	stats, err := s.paperStats(ctx, tenantID(c), unitIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statistics"})
+		return
+	}
+
+	usersByRole := map[string]int{}
+	query := "SELECT u.role, COUNT(*) FROM users u WHERE u.tenant_id = $1"
+	args := []interface{}{tenantID(c)}
+	if unitIDs != nil {
+		query += " AND EXISTS (SELECT 1 FROM user_affiliations ua WHERE ua.user_id = u.id AND " + inUnitSubtree("ua.unit_id", 2) + ")"
+		args = append(args, unitIDs)
+	}
+	rows, err := s.db.Pool.Query(ctx, query+" GROUP BY u.role", args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statistics"})
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var role string
+		var count int
+		if err := rows.Scan(&role, &count); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan statistics"})
+			return
+		}
+		usersByRole[role] = count
+	}
+
+	c.JSON(http.StatusOK, gin.H{"papers": stats, "users_by_role": usersByRole})
+}
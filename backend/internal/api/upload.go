@@ -59,8 +59,8 @@ func (h *UploadHandler) UploadFile(c *gin.Context) {
 		return
 	}
 
-	// Upload to Supabase
-	url, err := h.storage.UploadFile(file, header)
+	// Upload to Supabase, into the institution's own bucket when it has one
+	url, err := h.storage.WithBucket(currentTenant(c).Config.SupabaseBucket).UploadFile(file, header)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to upload file: %v", err)})
 		return
@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func chain(n int) []Entry {
+	tenant := uuid.New()
+	actor := uuid.New()
+	entries := make([]Entry, n)
+	prev := ""
+	for i := range entries {
+		entries[i] = Entry{
+			TenantID:   tenant,
+			ActorID:    &actor,
+			ActorEmail: "editor@example.edu",
+			Action:     "paper.update",
+			EntityType: "paper",
+			EntityID:   uuid.NewString(),
+			Method:     "PUT",
+			Path:       "/api/v1/papers/:id",
+			Status:     200,
+			Changes:    map[string]Change{"status": {Before: "submitted", After: "under_review"}},
+			CreatedAt:  time.Date(2026, 3, 1, 9, 0, i, 123456789, time.UTC),
+		}
+		entries[i].Seal(prev)
+		prev = entries[i].Hash
+	}
+	return entries
+}
+
+func TestChainIntact(t *testing.T) {
+	entries := chain(5)
+	if got := VerifyChain(entries); got != -1 {
+		t.Fatalf("intact chain reported broken at %d", got)
+	}
+	if entries[0].PrevHash != "" || entries[3].PrevHash != entries[2].Hash {
+		t.Error("entries are not linked to their predecessors")
+	}
+}
+
+func TestChainDetectsTampering(t *testing.T) {
+	cases := map[string]func(entries []Entry) []Entry{
+		"edited field": func(e []Entry) []Entry {
+			e[2].Status = 403
+			return e
+		},
+		"edited change": func(e []Entry) []Entry {
+			e[2].Changes["status"] = Change{Before: "submitted", After: "published"}
+			return e
+		},
+		"removed entry": func(e []Entry) []Entry {
+			return append(e[:2], e[3:]...)
+		},
+		"reordered entries": func(e []Entry) []Entry {
+			e[1], e[2] = e[2], e[1]
+			return e
+		},
+		"rehashed entry": func(e []Entry) []Entry {
+			// Recomputing the edited entry's hash still breaks the link from the next one
+			e[2].ActorEmail = "someone@example.edu"
+			e[2].Seal(e[2].PrevHash)
+			return e
+		},
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := VerifyChain(tamper(chain(5))); got == -1 {
+				t.Error("tampering went undetected")
+			}
+		})
+	}
+}
+
+// Entries read back from the database carry JSON-decoded changes and a time in another zone
+func TestHashSurvivesStorageRoundTrip(t *testing.T) {
+	e := chain(1)[0]
+	e.Changes["pages"] = Change{Before: 12, After: []string{"a", "b"}}
+	e.Seal("")
+
+	raw, err := json.Marshal(e.Changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := e
+	stored.Changes = nil
+	if err := json.Unmarshal(raw, &stored.Changes); err != nil {
+		t.Fatal(err)
+	}
+	stored.CreatedAt = e.CreatedAt.In(time.FixedZone("EAT", 3*60*60))
+
+	if !stored.Follows("") {
+		t.Error("hash changed after a storage round trip")
+	}
+}
+
+func TestEmptyChangesHashAlike(t *testing.T) {
+	a := Entry{TenantID: uuid.New(), CreatedAt: time.Now()}
+	b := a
+	b.Changes = map[string]Change{}
+	if a.ComputeHash("") != b.ComputeHash("") {
+		t.Error("nil and empty changes should hash the same")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := map[string]interface{}{"title": "Old", "status": "draft", "password_hash": "x", "abstract": "same"}
+	after := map[string]interface{}{"title": "New", "status": "draft", "password_hash": "y", "abstract": "same", "doi": "10.1/x"}
+
+	changes := Diff(before, after)
+	if len(changes) != 3 {
+		t.Fatalf("got %d changes, want 3: %v", len(changes), changes)
+	}
+	if c := changes["title"]; c.Before != "Old" || c.After != "New" {
+		t.Errorf("title change = %+v", c)
+	}
+	if c := changes["doi"]; c.Before != nil || c.After != "10.1/x" {
+		t.Errorf("added field change = %+v", c)
+	}
+	if c := changes["password_hash"]; c.Before != redacted || c.After != redacted {
+		t.Errorf("secret leaked into the diff: %+v", c)
+	}
+
+	if deleted := Diff(before, nil); len(deleted) != len(before) {
+		t.Errorf("deletion should list every field, got %v", deleted)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		method, route         string
+		action, entity, param string
+	}{
+		{"POST", "/api/v1/papers", "paper.create", "paper", ""},
+		{"PUT", "/api/v1/papers/:id", "paper.update", "paper", "id"},
+		{"DELETE", "/api/v1/papers/:id", "paper.delete", "paper", "id"},
+		{"POST", "/api/v1/papers/:id/withdraw", "paper.withdraw", "paper", "id"},
+		{"DELETE", "/api/v1/papers/:id/schedule", "paper.schedule.delete", "paper", "id"},
+		{"DELETE", "/api/v1/papers/:id/staff/:userId", "staff.delete", "staff", "userId"},
+		{"PUT", "/api/v1/admin/users/:id/role", "user.role", "user", "id"},
+		{"PUT", "/api/v1/review-assignments/:id/accept", "review_assignment.accept", "review_assignment", "id"},
+		{"POST", "/api/v1/volumes/:id/issues", "volume.issues", "volume", "id"},
+		{"PUT", "/api/v1/news/:id/publish", "news.publish", "news", "id"},
+		{"POST", "/api/v1/auth/login", "auth.login", "auth", ""},
+		{"PUT", "/api/v1/admin/tenant", "tenant.update", "tenant", ""},
+	}
+	for _, tc := range cases {
+		action, entity, param := Describe(tc.method, tc.route)
+		if action != tc.action || entity != tc.entity || param != tc.param {
+			t.Errorf("Describe(%s %s) = %q, %q, %q; want %q, %q, %q",
+				tc.method, tc.route, action, entity, param, tc.action, tc.entity, tc.param)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Tables maps entity types to the table whose rows are snapshotted before and after a
+// change. Entities missing here are logged without a diff.
+var Tables = map[string]string{
+	"paper":             "papers",
+	"review":            "reviews",
+	"review_assignment": "review_assignments",
+	"event":             "events",
+	"news":              "news",
+	"call":              "calls",
+	"journal":           "journals",
+	"volume":            "journal_volumes",
+	"issue":             "journal_issues",
+	"unit":              "org_units",
+	"role":              "roles",
+	"user":              "users",
+	"notification":      "notifications",
+	"service_account":   "service_accounts",
+	"api_key":           "api_keys",
+}
+
+// Describe names the action of a request from its method and route pattern, such as
+// "paper.create" for POST /api/v1/papers, "paper.update" for PUT /api/v1/papers/:id and
+// "paper.withdraw" for POST /api/v1/papers/:id/withdraw. It also returns the entity type and
+// the route parameter holding the entity's ID, empty when the route has none.
+func Describe(method, route string) (action, entityType, idParam string) {
+	route = strings.TrimPrefix(route, "/api/v1")
+	var segments []string
+	for _, s := range strings.Split(route, "/") {
+		if s != "" && s != "admin" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 {
+		return strings.ToLower(method), "", ""
+	}
+
+	// The entity is the one named before the last ID in the route, or the first segment
+	entityAt := 0
+	for i := len(segments) - 1; i > 0; i-- {
+		if isParam(segments[i]) {
+			entityAt = i - 1
+			idParam = strings.TrimPrefix(segments[i], ":")
+			break
+		}
+	}
+	entityType = singular(segments[entityAt])
+
+	var rest []string
+	for _, s := range segments[entityAt+1:] {
+		if !isParam(s) {
+			rest = append(rest, strings.ReplaceAll(s, "-", "_"))
+		}
+	}
+	if len(rest) == 0 {
+		return entityType + "." + verb(method), entityType, idParam
+	}
+	action = entityType + "." + strings.Join(rest, ".")
+	if method == http.MethodDelete {
+		action += ".delete"
+	}
+	return action, entityType, idParam
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
+
+func verb(method string) string {
+	switch method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return strings.ToLower(method)
+}
+
+// singular turns a route collection name into an entity type
+func singular(name string) string {
+	name = strings.ReplaceAll(name, "-", "_")
+	switch {
+	case name == "news" || strings.HasSuffix(name, "ss"):
+		return name
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	}
+	return strings.TrimSuffix(name, "s")
+}
@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Log stores entries in audit_log. A trigger rejects updates and deletes of stored entries.
+type Log struct {
+	db *database.Database
+}
+
+func NewLog(db *database.Database) *Log {
+	return &Log{db: db}
+}
+
+// Snapshot returns the row of an entity as JSON fields, or nil if there is no such row
+func (l *Log) Snapshot(ctx context.Context, entityType, id string) (map[string]interface{}, error) {
+	table, ok := Tables[entityType]
+	if !ok || id == "" {
+		return nil, nil
+	}
+	var row map[string]interface{}
+	query := fmt.Sprintf("SELECT to_jsonb(t) FROM %s t WHERE t.id::text = $1", pgx.Identifier{table}.Sanitize())
+	err := l.db.Pool.QueryRow(ctx, query, id).Scan(&row)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	return row, err
+}
+
+// Append seals an entry onto its tenant's chain and stores it. Appends to one tenant's
+// chain are serialized with an advisory lock so that no two entries share a predecessor.
+func (l *Log) Append(ctx context.Context, e *Entry) error {
+	tx, err := l.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtextextended('audit_log:' || $1::text, 0))", e.TenantID); err != nil {
+		return err
+	}
+
+	var prevHash string
+	err = tx.QueryRow(ctx, "SELECT hash FROM audit_log WHERE tenant_id = $1 ORDER BY id DESC LIMIT 1", e.TenantID).Scan(&prevHash)
+	if err != nil && err != pgx.ErrNoRows {
+		return err
+	}
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if e.Changes == nil {
+		e.Changes = map[string]Change{}
+	}
+	e.Seal(prevHash)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO audit_log (
+			tenant_id, actor_id, actor_email, actor_role, action, entity_type, entity_id,
+			method, path, status, changes, ip_address, user_agent, created_at, prev_hash, hash
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id
+	`, e.TenantID, e.ActorID, e.ActorEmail, e.ActorRole, e.Action, e.EntityType, e.EntityID,
+		e.Method, e.Path, e.Status, e.Changes, e.IPAddress, e.UserAgent, e.CreatedAt, e.PrevHash, e.Hash,
+	).Scan(&e.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Filter narrows a query of the log; zero fields match everything
+type Filter struct {
+	TenantID   uuid.UUID
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+func (f Filter) where() (string, []interface{}) {
+	where := "WHERE tenant_id = $1"
+	args := []interface{}{f.TenantID}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND "+condition, len(args))
+	}
+	if f.ActorID != nil {
+		add("actor_id = $%d", *f.ActorID)
+	}
+	if f.Action != "" {
+		// A trailing dot selects every action of an entity, such as "paper."
+		add("action LIKE $%d", likePrefix(f.Action))
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", *f.To)
+	}
+	return where, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// likePrefix escapes an action for LIKE, matching it exactly unless it ends with a dot
+func likePrefix(action string) string {
+	escaped := likeEscaper.Replace(action)
+	if strings.HasSuffix(action, ".") {
+		escaped += "%"
+	}
+	return escaped
+}
+
+const entryColumns = `
+	SELECT id, tenant_id, actor_id, actor_email, actor_role, action, entity_type, entity_id,
+		   method, path, status, changes, ip_address, user_agent, created_at, prev_hash, hash
+	FROM audit_log `
+
+func scanEntry(row pgx.Row, e *Entry) error {
+	return row.Scan(&e.ID, &e.TenantID, &e.ActorID, &e.ActorEmail, &e.ActorRole, &e.Action, &e.EntityType, &e.EntityID,
+		&e.Method, &e.Path, &e.Status, &e.Changes, &e.IPAddress, &e.UserAgent, &e.CreatedAt, &e.PrevHash, &e.Hash)
+}
+
+// Count returns the number of entries matching the filter
+func (l *Log) Count(ctx context.Context, f Filter) (int, error) {
+	where, args := f.where()
+	var n int
+	err := l.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM audit_log "+where, args...).Scan(&n)
+	return n, err
+}
+
+// Each calls fn for the entries matching the filter, newest first. A limit of zero means all.
+func (l *Log) Each(ctx context.Context, f Filter, limit, offset int, fn func(*Entry) error) error {
+	where, args := f.where()
+	query := entryColumns + where + " ORDER BY id DESC"
+	if limit > 0 {
+		args = append(args, limit, offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+	rows, err := l.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e Entry
+		if err := scanEntry(rows, &e); err != nil {
+			return err
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// VerifyResult reports the state of a tenant's chain
+type VerifyResult struct {
+	Entries  int   `json:"entries"`
+	Intact   bool  `json:"intact"`
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
+
+// Verify walks a tenant's whole chain from the first entry, which must have no predecessor
+func (l *Log) Verify(ctx context.Context, tenantID uuid.UUID) (*VerifyResult, error) {
+	rows, err := l.db.Pool.Query(ctx, entryColumns+"WHERE tenant_id = $1 ORDER BY id", tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &VerifyResult{Intact: true}
+	prevHash := ""
+	for rows.Next() {
+		var e Entry
+		if err := scanEntry(rows, &e); err != nil {
+			return nil, err
+		}
+		result.Entries++
+		if result.Intact && !e.Follows(prevHash) {
+			result.Intact = false
+			result.BrokenAt = e.ID
+		}
+		prevHash = e.Hash
+	}
+	return result, rows.Err()
+}
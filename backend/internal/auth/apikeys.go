@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/database"
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/rbac"
+	"rpms-backend/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// APIKeyHeader carries the API key of a service account instead of a bearer token
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix starts every key, so that leaked keys are easy to recognize and scan for
+const apiKeyPrefix = "rpms"
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+// APIScope is what a service account with the scope may call: read-only routes, with the
+// permissions their handlers check
+type APIScope struct {
+	Description string   `json:"description"`
+	Routes      []string `json:"routes"`
+	Permissions []string `json:"permissions"`
+}
+
+// APIScopes lists every scope a service account can be granted
+var APIScopes = map[string]APIScope{
+	"read:papers": {
+		Description: "List papers, their contributors, calls and journal issues",
+		Routes: []string{
+			"/api/v1/papers", "/api/v1/papers/:id/contributors",
+			"/api/v1/calls", "/api/v1/calls/:id",
+			"/api/v1/journals", "/api/v1/journals/:id/volumes", "/api/v1/volumes/:id/issues",
+		},
+		Permissions: []string{rbac.PaperReadAll},
+	},
+	"read:reports": {
+		Description: "Read institution, unit, call and reviewer statistics",
+		Routes: []string{
+			"/api/v1/admin/stats", "/api/v1/admin/reviewers/stats",
+			"/api/v1/units", "/api/v1/units/:id", "/api/v1/units/:id/members", "/api/v1/units/:id/stats",
+			"/api/v1/calls/:id/stats",
+		},
+		Permissions: []string{rbac.ReportView, rbac.UnitView, rbac.CallViewSubmissions},
+	},
+	"read:audit": {
+		Description: "Search and export the audit log",
+		Routes:      []string{"/api/v1/admin/audit", "/api/v1/admin/audit/export", "/api/v1/admin/audit/verify"},
+		Permissions: []string{rbac.AuditRead},
+	},
+}
+
+// ValidScope reports whether name is a known API scope
+func ValidScope(name string) bool {
+	_, ok := APIScopes[name]
+	return ok
+}
+
+// ScopesAllow reports whether one of the scopes lets a key call the route pattern with method.
+// Keys can only read.
+func ScopesAllow(scopes []string, method, route string) bool {
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	for _, name := range scopes {
+		for _, allowed := range APIScopes[name].Routes {
+			if allowed == route {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ScopePermissions returns the permissions granted by a set of scopes
+func ScopePermissions(scopes []string) rbac.Set {
+	lists := make([][]string, 0, len(scopes))
+	for _, name := range scopes {
+		lists = append(lists, APIScopes[name].Permissions)
+	}
+	return rbac.NewSet(lists...)
+}
+
+// GenerateAPIKey returns a new key of the form rpms_<prefix>_<secret> and its prefix, which
+// identifies the key without revealing it
+func GenerateAPIKey() (key, prefix string, err error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(id)
+	return apiKeyPrefix + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+// ParseAPIKey returns the prefix of a well-formed key
+func ParseAPIKey(key string) (prefix string, ok bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || len(parts[1]) != 12 || parts[2] == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// APIPrincipal is the service account an API key authenticated
+type APIPrincipal struct {
+	ServiceAccountID uuid.UUID
+	KeyID            uuid.UUID
+	Name             string
+	Scopes           []string
+	Tenant           *tenant.Tenant
+}
+
+// APIKeyAuthenticator resolves API keys to the service account they belong to
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key, ipAddress string) (*APIPrincipal, error)
+}
+
+// APIKeyStore keeps the hashed API keys of service accounts
+type APIKeyStore struct {
+	db      *database.Database
+	tenants tenant.Resolver
+}
+
+func NewAPIKeyStore(db *database.Database, tenants tenant.Resolver) *APIKeyStore {
+	return &APIKeyStore{db: db, tenants: tenants}
+}
+
+// AuthenticateAPIKey accepts a key that is neither expired nor revoked and whose service
+// account is enabled, and records its use
+func (s *APIKeyStore) AuthenticateAPIKey(ctx context.Context, key, ipAddress string) (*APIPrincipal, error) {
+	prefix, ok := ParseAPIKey(key)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var principal APIPrincipal
+	var tenantID uuid.UUID
+	var keyHash string
+	var lastUsed *time.Time
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT k.id, k.key_hash, k.last_used_at, sa.id, sa.name, sa.scopes, sa.tenant_id
+		FROM api_keys k
+		JOIN service_accounts sa ON sa.id = k.service_account_id
+		WHERE k.prefix = $1 AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())
+		  AND sa.disabled_at IS NULL
+	`, prefix).Scan(&principal.KeyID, &keyHash, &lastUsed, &principal.ServiceAccountID, &principal.Name,
+		&principal.Scopes, &tenantID)
+	if err == pgx.ErrNoRows {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(HashCode(key)), []byte(keyHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	principal.Tenant, err = s.tenants.ByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Busy integrations only need their last use recorded once in a while
+	if lastUsed == nil || time.Since(*lastUsed) > lastSeenGranularity {
+		if _, err := s.db.Pool.Exec(ctx,
+			"UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $2 WHERE id = $1",
+			principal.KeyID, ipAddress); err != nil {
+			return nil, err
+		}
+	}
+	return &principal, nil
+}
+
+const apiKeyColumns = `
+	SELECT id, service_account_id, prefix, expires_at, rotated_from, last_used_at, last_used_ip, created_at, revoked_at
+	FROM api_keys `
+
+func scanAPIKey(row pgx.Row, k *models.APIKey) error {
+	return row.Scan(&k.ID, &k.ServiceAccountID, &k.Prefix, &k.ExpiresAt, &k.RotatedFrom, &k.LastUsedAt,
+		&k.LastUsedIP, &k.CreatedAt, &k.RevokedAt)
+}
+
+// Keys lists the keys of a service account, newest first, including expired and revoked ones
+func (s *APIKeyStore) Keys(ctx context.Context, serviceAccountID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := s.db.Pool.Query(ctx, apiKeyColumns+"WHERE service_account_id = $1 ORDER BY created_at DESC", serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var k models.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// rowQuerier is a pool or a transaction
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// insertKey stores a new key of a service account and returns it with its plaintext
+func insertKey(ctx context.Context, q rowQuerier, serviceAccountID, createdBy uuid.UUID, expiresAt *time.Time, rotatedFrom *uuid.UUID) (*models.NewAPIKey, error) {
+	key, prefix, err := GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	created := &models.NewAPIKey{Key: key}
+	err = scanAPIKey(q.QueryRow(ctx, `
+		INSERT INTO api_keys (service_account_id, prefix, key_hash, expires_at, rotated_from, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, service_account_id, prefix, expires_at, rotated_from, last_used_at, last_used_ip, created_at, revoked_at
+	`, serviceAccountID, prefix, HashCode(key), expiresAt, rotatedFrom, createdBy), &created.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Create issues a new key for a service account; a nil expiry means the key does not expire
+func (s *APIKeyStore) Create(ctx context.Context, serviceAccountID, createdBy uuid.UUID, expiresAt *time.Time) (*models.NewAPIKey, error) {
+	return insertKey(ctx, s.db.Pool, serviceAccountID, createdBy, expiresAt, nil)
+}
+
+// Rotate replaces a usable key with a new one. The old key keeps working for the grace
+// period, or stops at once without one.
+func (s *APIKeyStore) Rotate(ctx context.Context, serviceAccountID, keyID, createdBy uuid.UUID, expiresAt *time.Time, grace time.Duration) (*models.NewAPIKey, error) {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var usable bool
+	err = tx.QueryRow(ctx, `
+		SELECT revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+		FROM api_keys WHERE id = $1 AND service_account_id = $2
+		FOR UPDATE
+	`, keyID, serviceAccountID).Scan(&usable)
+	if err == pgx.ErrNoRows || (err == nil && !usable) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if grace > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE api_keys SET expires_at = LEAST(COALESCE(expires_at, 'infinity'), $2) WHERE id = $1
+		`, keyID, time.Now().Add(grace))
+	} else {
+		_, err = tx.Exec(ctx, "UPDATE api_keys SET revoked_at = NOW() WHERE id = $1", keyID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := insertKey(ctx, tx, serviceAccountID, createdBy, expiresAt, &keyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Revoke stops a key of a service account from working
+func (s *APIKeyStore) Revoke(ctx context.Context, serviceAccountID, keyID uuid.UUID) error {
+	tag, err := s.db.Pool.Exec(ctx,
+		"UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND service_account_id = $2 AND revoked_at IS NULL",
+		keyID, serviceAccountID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
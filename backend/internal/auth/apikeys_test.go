@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"rpms-backend/internal/rbac"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "rpms_"+prefix+"_") {
+		t.Errorf("key %q does not carry its prefix %q", key, prefix)
+	}
+	parsed, ok := ParseAPIKey(key)
+	if !ok || parsed != prefix {
+		t.Errorf("ParseAPIKey(%q) = %q, %v; want %q", key, parsed, ok, prefix)
+	}
+
+	other, otherPrefix, _ := GenerateAPIKey()
+	if other == key || otherPrefix == prefix {
+		t.Error("two generated keys collide")
+	}
+}
+
+func TestParseAPIKeyRejectsMalformedKeys(t *testing.T) {
+	for _, key := range []string{
+		"",
+		"rpms_0123456789ab",
+		"rpms_0123456789ab_",
+		"sk_0123456789ab_secret",
+		"rpms_0123456789_secret",
+		"rpms_0123456789zz_secret",
+		"Bearer rpms_0123456789ab_secret",
+	} {
+		if _, ok := ParseAPIKey(key); ok {
+			t.Errorf("ParseAPIKey(%q) accepted a malformed key", key)
+		}
+	}
+}
+
+func TestScopesAllow(t *testing.T) {
+	cases := []struct {
+		scopes        []string
+		method, route string
+		want          bool
+	}{
+		{[]string{"read:papers"}, http.MethodGet, "/api/v1/papers", true},
+		{[]string{"read:papers"}, http.MethodHead, "/api/v1/calls/:id", true},
+		{[]string{"read:papers"}, http.MethodPost, "/api/v1/papers", false},
+		{[]string{"read:papers"}, http.MethodGet, "/api/v1/admin/stats", false},
+		{[]string{"read:papers", "read:reports"}, http.MethodGet, "/api/v1/admin/stats", true},
+		{[]string{"read:reports"}, http.MethodGet, "/api/v1/admin/users", false},
+		{[]string{"read:everything"}, http.MethodGet, "/api/v1/papers", false},
+		{nil, http.MethodGet, "/api/v1/papers", false},
+	}
+	for _, tc := range cases {
+		if got := ScopesAllow(tc.scopes, tc.method, tc.route); got != tc.want {
+			t.Errorf("ScopesAllow(%v, %s %s) = %v, want %v", tc.scopes, tc.method, tc.route, got, tc.want)
+		}
+	}
+}
+
+func TestScopePermissions(t *testing.T) {
+	set := ScopePermissions([]string{"read:reports"})
+	if !set.Has(rbac.ReportView) || !set.Has(rbac.UnitView) {
+		t.Errorf("read:reports grants %v", set.List())
+	}
+	if set.Has(rbac.PaperReadAll) || set.Has(rbac.UserManage) {
+		t.Errorf("read:reports grants more than it should: %v", set.List())
+	}
+
+	for name, scope := range APIScopes {
+		for _, p := range scope.Permissions {
+			if !rbac.Valid(p) {
+				t.Errorf("scope %s grants unknown permission %q", name, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidCode        = errors.New("invalid or expired verification code")
+	ErrAlreadyRegistered  = errors.New("email already registered")
+	ErrRateLimited        = errors.New("too many requests")
+)
+
+// Identity is an account as known to the identity provider
+type Identity struct {
+	ID    uuid.UUID
+	Email string
+	// Metadata holds the profile captured at sign up (name, role, tenant_id, ...)
+	Metadata map[string]interface{}
+	// PasswordHash is the bcrypt hash to keep locally, when the provider knows the password
+	PasswordHash string
+}
+
+// IdentityProvider registers, verifies and authenticates accounts. The users table stays the
+// source of truth for profiles and roles; the provider only owns credentials and email verification.
+type IdentityProvider interface {
+	// SignUp starts a registration that is completed by Verify with the emailed code
+	SignUp(ctx context.Context, email, password string, metadata map[string]interface{}) error
+	// Verify confirms a registration and returns the new identity
+	Verify(ctx context.Context, email, code string) (*Identity, error)
+	// ResendVerification sends a fresh verification code
+	ResendVerification(ctx context.Context, email string) error
+	// SignIn checks an email and password
+	SignIn(ctx context.Context, email, password string) (*Identity, error)
+	// CreateConfirmed creates an account that needs no email verification
+	CreateConfirmed(ctx context.Context, email, password string, metadata map[string]interface{}) (*Identity, error)
+	// UpdatePassword sets a new password for an existing account
+	UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error
+	// UpdateMetadata merges profile fields (such as a changed role) into the account
+	UpdateMetadata(ctx context.Context, userID uuid.UUID, metadata map[string]interface{}) error
+	// SetDisabled blocks or unblocks sign in for an account
+	SetDisabled(ctx context.Context, userID uuid.UUID, disabled bool) error
+	// Delete removes the account; deleting an account the provider does not know is not an error
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+// NewVerificationCode returns a random six digit code
+func NewVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// HashCode hashes a one-time code for storage; codes are short-lived so a plain digest is enough
+func HashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -8,38 +8,77 @@ import (
 	"rpms-backend/internal/models"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// TenantID is the university the user belongs to
+	TenantID string `json:"tenant_id,omitempty"`
+	// SessionID ties an access token to the sign in (and refresh token family) it came from
+	SessionID string `json:"sid,omitempty"`
+	// Scope limits a pre-auth token to finishing sign in; full access tokens have none
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Pre-auth token scopes
+const (
+	// ScopeMFAVerify allows submitting a second factor code
+	ScopeMFAVerify = "mfa_verify"
+	// ScopeMFAEnroll allows enrolling a second factor the user's role requires
+	ScopeMFAEnroll = "mfa_enroll"
+)
+
+// PreAuthExpiry is how long a user has to complete the second step of sign in
+const PreAuthExpiry = 10 * time.Minute
+
 type JWTManager struct {
-	secretKey string
-	expiry    time.Duration
+	secretKey     string
+	expiry        time.Duration
+	refreshExpiry time.Duration
 }
 
 func NewJWTManager(cfg *config.Config) *JWTManager {
 	expiry, err := time.ParseDuration(cfg.JWT.Expiry)
 	if err != nil {
-		expiry = 24 * time.Hour // Default to 24 hours
+		expiry = 15 * time.Minute // Default to 15 minutes
+	}
+	refreshExpiry, err := time.ParseDuration(cfg.JWT.RefreshExpiry)
+	if err != nil {
+		refreshExpiry = 30 * 24 * time.Hour // Default to 30 days
 	}
 
 	return &JWTManager{
-		secretKey: cfg.JWT.Secret,
-		expiry:    expiry,
+		secretKey:     cfg.JWT.Secret,
+		expiry:        expiry,
+		refreshExpiry: refreshExpiry,
 	}
 }
 
-func (j *JWTManager) GenerateToken(user *models.User) (string, error) {
+// Expiry is the lifetime of the access tokens issued
+func (j *JWTManager) Expiry() time.Duration {
+	return j.expiry
+}
+
+// RefreshExpiry is the lifetime of the refresh tokens issued
+func (j *JWTManager) RefreshExpiry() time.Duration {
+	return j.refreshExpiry
+}
+
+// GenerateToken issues an access token for a session started by TokenStore.Issue
+func (j *JWTManager) GenerateToken(user *models.User, sessionID uuid.UUID) (string, error) {
 	claims := &Claims{
-		UserID: user.ID.String(),
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID.String(),
+		Email:     user.Email,
+		Role:      user.Role,
+		TenantID:  tenantID(user),
+		SessionID: sessionID.String(),
 		RegisteredClaims: jwt.RegisteredClaims{
+			// The ID lets a single access token be revoked before it expires
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -50,6 +89,26 @@ func (j *JWTManager) GenerateToken(user *models.User) (string, error) {
 	return token.SignedString([]byte(j.secretKey))
 }
 
+// GeneratePreAuthToken issues a short-lived token that only lets the user finish signing in
+func (j *JWTManager) GeneratePreAuthToken(user *models.User, scope string) (string, error) {
+	claims := &Claims{
+		UserID:   user.ID.String(),
+		Email:    user.Email,
+		Role:     user.Role,
+		TenantID: tenantID(user),
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(PreAuthExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.secretKey))
+}
+
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -69,24 +128,9 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	return nil, fmt.Errorf("invalid token")
 }
 
-func (j *JWTManager) RefreshToken(tokenString string) (string, error) {
-	claims, err := j.ValidateToken(tokenString)
-	if err != nil {
-		return "", err
+func tenantID(user *models.User) string {
+	if user.TenantID == uuid.Nil {
+		return ""
 	}
-
-	// Create new claims with updated expiration
-	newClaims := &Claims{
-		UserID: claims.UserID,
-		Email:  claims.Email,
-		Role:   claims.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims)
-	return token.SignedString([]byte(j.secretKey))
+	return user.TenantID.String()
 }
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/database"
+	"rpms-backend/internal/email"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	verificationCodeTTL    = 24 * time.Hour
+	verificationResendWait = time.Minute
+	maxVerificationTries   = 5
+)
+
+// LocalProvider keeps credentials in our own database: passwords as bcrypt hashes in users
+// and registrations awaiting their emailed code in pending_registrations
+type LocalProvider struct {
+	db    *database.Database
+	email *email.EmailSender
+}
+
+func NewLocalProvider(db *database.Database, sender *email.EmailSender) *LocalProvider {
+	return &LocalProvider{db: db, email: sender}
+}
+
+func normalizeEmail(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
+
+func (p *LocalProvider) SignUp(ctx context.Context, address, password string, metadata map[string]interface{}) error {
+	address = normalizeEmail(address)
+
+	var exists bool
+	if err := p.db.Pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(email) = $1)", address).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrAlreadyRegistered
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	code, err := NewVerificationCode()
+	if err != nil {
+		return err
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	// Signing up again replaces the pending registration and its code
+	_, err = p.db.Pool.Exec(ctx, `
+		INSERT INTO pending_registrations (email, password_hash, metadata, code_hash, attempts, sent_at, expires_at)
+		VALUES ($1, $2, $3, $4, 0, NOW(), $5)
+		ON CONFLICT (email) DO UPDATE
+		SET password_hash = EXCLUDED.password_hash, metadata = EXCLUDED.metadata, code_hash = EXCLUDED.code_hash,
+			attempts = 0, sent_at = NOW(), expires_at = EXCLUDED.expires_at
+	`, address, hash, metadata, HashCode(code), time.Now().Add(verificationCodeTTL))
+	if err != nil {
+		return err
+	}
+
+	return p.email.SendVerificationEmail(address, code)
+}
+
+func (p *LocalProvider) Verify(ctx context.Context, address, code string) (*Identity, error) {
+	address = normalizeEmail(address)
+
+	tx, err := p.db.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	identity := &Identity{Email: address}
+	var codeHash string
+	var attempts int
+	var expiresAt time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT password_hash, metadata, code_hash, attempts, expires_at
+		FROM pending_registrations WHERE email = $1 FOR UPDATE
+	`, address).Scan(&identity.PasswordHash, &identity.Metadata, &codeHash, &attempts, &expiresAt)
+	if err == pgx.ErrNoRows {
+		return nil, ErrInvalidCode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if attempts >= maxVerificationTries || time.Now().After(expiresAt) {
+		return nil, ErrInvalidCode
+	}
+	if HashCode(strings.TrimSpace(code)) != codeHash {
+		if _, err := tx.Exec(ctx, "UPDATE pending_registrations SET attempts = attempts + 1 WHERE email = $1", address); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCode
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM pending_registrations WHERE email = $1", address); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	identity.ID = uuid.New()
+	return identity, nil
+}
+
+func (p *LocalProvider) ResendVerification(ctx context.Context, address string) error {
+	address = normalizeEmail(address)
+
+	code, err := NewVerificationCode()
+	if err != nil {
+		return err
+	}
+
+	var sentAt time.Time
+	err = p.db.Pool.QueryRow(ctx, "SELECT sent_at FROM pending_registrations WHERE email = $1", address).Scan(&sentAt)
+	if err == pgx.ErrNoRows {
+		return ErrInvalidCode
+	}
+	if err != nil {
+		return err
+	}
+	if time.Since(sentAt) < verificationResendWait {
+		return ErrRateLimited
+	}
+
+	_, err = p.db.Pool.Exec(ctx, `
+		UPDATE pending_registrations
+		SET code_hash = $1, attempts = 0, sent_at = NOW(), expires_at = $2
+		WHERE email = $3
+	`, HashCode(code), time.Now().Add(verificationCodeTTL), address)
+	if err != nil {
+		return err
+	}
+
+	return p.email.SendVerificationEmail(address, code)
+}
+
+func (p *LocalProvider) SignIn(ctx context.Context, address, password string) (*Identity, error) {
+	identity := &Identity{Email: normalizeEmail(address)}
+	err := p.db.Pool.QueryRow(ctx,
+		"SELECT id, COALESCE(password_hash, '') FROM users WHERE LOWER(email) = $1",
+		identity.Email).Scan(&identity.ID, &identity.PasswordHash)
+	if err == pgx.ErrNoRows {
+		// Spend the same time as a wrong password so that registered emails cannot be probed
+		CheckPassword(password, dummyHash)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if identity.PasswordHash == "" || !CheckPassword(password, identity.PasswordHash) {
+		return nil, ErrInvalidCredentials
+	}
+	return identity, nil
+}
+
+func (p *LocalProvider) CreateConfirmed(ctx context.Context, address, password string, metadata map[string]interface{}) (*Identity, error) {
+	address = normalizeEmail(address)
+
+	var exists bool
+	if err := p.db.Pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(email) = $1)", address).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrAlreadyRegistered
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{ID: uuid.New(), Email: address, Metadata: metadata, PasswordHash: hash}, nil
+}
+
+// UpdatePassword is a no-op: the password lives in users.password_hash, which the caller updates
+func (p *LocalProvider) UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	return nil
+}
+
+// UpdateMetadata is a no-op: the profile lives in users
+func (p *LocalProvider) UpdateMetadata(ctx context.Context, userID uuid.UUID, metadata map[string]interface{}) error {
+	return nil
+}
+
+// SetDisabled is a no-op: users.is_active is checked at login for every provider
+func (p *LocalProvider) SetDisabled(ctx context.Context, userID uuid.UUID, disabled bool) error {
+	return nil
+}
+
+// Delete is a no-op: the caller deletes the users row together with any registration still
+// pending for its email
+func (p *LocalProvider) Delete(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+// dummyHash is compared against when no account matches
+var dummyHash, _ = HashPassword(uuid.NewString())
@@ -0,0 +1,88 @@
+package auth
+
+import "time"
+
+// LockoutPolicy decides how long sign in is refused after failed attempts. The first
+// FreeAttempts failures cost nothing; after that each failure doubles the wait, from
+// BaseDelay up to MaxDelay, and reaching Threshold locks sign in for LockoutDuration.
+// Failures older than Window are forgotten.
+type LockoutPolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	Threshold       int
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+var (
+	// AccountLockout applies to failed sign ins for one email address
+	AccountLockout = LockoutPolicy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		Threshold:       10,
+		LockoutDuration: 30 * time.Minute,
+		Window:          time.Hour,
+	}
+	// IPLockout applies to failed sign ins from one client address, across accounts
+	IPLockout = LockoutPolicy{
+		FreeAttempts:    20,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		Threshold:       100,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+	}
+)
+
+// FailureState is the failed sign in history kept for one account or address
+type FailureState struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Backoff is the wait required after the given number of consecutive failures
+func (p LockoutPolicy) Backoff(failures int) time.Duration {
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// RetryAfter is how long until another attempt is allowed; zero when it is allowed now
+func (p LockoutPolicy) RetryAfter(st FailureState, now time.Time) time.Duration {
+	if now.Before(st.LockedUntil) {
+		return st.LockedUntil.Sub(now)
+	}
+	if now.Sub(st.LastFailure) > p.Window {
+		return 0
+	}
+	if wait := st.LastFailure.Add(p.Backoff(st.Failures)).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// RecordFailure adds a failure to the state and reports whether it just triggered a lockout
+func (p LockoutPolicy) RecordFailure(st FailureState, now time.Time) (FailureState, bool) {
+	if now.Sub(st.LastFailure) > p.Window || (!st.LockedUntil.IsZero() && !now.Before(st.LockedUntil)) {
+		// Stale history, or a lockout that has run its course: start over
+		st = FailureState{}
+	}
+	st.Failures++
+	st.LastFailure = now
+	if st.Failures >= p.Threshold && now.After(st.LockedUntil) {
+		st.LockedUntil = now.Add(p.LockoutDuration)
+		return st, true
+	}
+	return st, false
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+var testPolicy = LockoutPolicy{
+	FreeAttempts:    3,
+	BaseDelay:       time.Second,
+	MaxDelay:        10 * time.Second,
+	Threshold:       8,
+	LockoutDuration: 30 * time.Minute,
+	Window:          time.Hour,
+}
+
+func TestBackoff(t *testing.T) {
+	want := map[int]time.Duration{
+		0: 0, 1: 0, 3: 0,
+		4:  time.Second,
+		5:  2 * time.Second,
+		6:  4 * time.Second,
+		7:  8 * time.Second,
+		8:  10 * time.Second,
+		50: 10 * time.Second,
+	}
+	for failures, delay := range want {
+		if got := testPolicy.Backoff(failures); got != delay {
+			t.Errorf("Backoff(%d) = %v, want %v", failures, got, delay)
+		}
+	}
+}
+
+func TestRecordFailureLocksAtThreshold(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	var st FailureState
+	var locked bool
+	for i := 1; i < testPolicy.Threshold; i++ {
+		st, locked = testPolicy.RecordFailure(st, now)
+		if locked {
+			t.Fatalf("locked after %d failures", i)
+		}
+		now = now.Add(testPolicy.Backoff(st.Failures))
+	}
+
+	st, locked = testPolicy.RecordFailure(st, now)
+	if !locked {
+		t.Fatal("threshold failure should lock")
+	}
+	if got := testPolicy.RetryAfter(st, now.Add(time.Minute)); got != 29*time.Minute {
+		t.Errorf("RetryAfter during lockout = %v, want 29m", got)
+	}
+
+	// Further failures during the lockout do not extend it or report a new lockout
+	if next, again := testPolicy.RecordFailure(st, now.Add(time.Minute)); again || !next.LockedUntil.Equal(st.LockedUntil) {
+		t.Error("failure during lockout extended it")
+	}
+
+	// Once it has expired the history starts over
+	after := st.LockedUntil.Add(time.Second)
+	if got := testPolicy.RetryAfter(st, after); got != 0 {
+		t.Errorf("RetryAfter after lockout = %v, want 0", got)
+	}
+	st, _ = testPolicy.RecordFailure(st, after)
+	if st.Failures != 1 {
+		t.Errorf("failures after lockout expiry = %d, want 1", st.Failures)
+	}
+}
+
+func TestRetryAfterBackoffAndWindow(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	st := FailureState{Failures: 5, LastFailure: now}
+
+	if got := testPolicy.RetryAfter(st, now.Add(500*time.Millisecond)); got != 1500*time.Millisecond {
+		t.Errorf("RetryAfter = %v, want 1.5s", got)
+	}
+	if got := testPolicy.RetryAfter(st, now.Add(2*time.Second)); got != 0 {
+		t.Errorf("RetryAfter after backoff = %v, want 0", got)
+	}
+
+	st, _ = testPolicy.RecordFailure(st, now.Add(2*time.Hour))
+	if st.Failures != 1 {
+		t.Errorf("failures outside the window = %d, want 1", st.Failures)
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"rpms-backend/internal/database"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// LoginThrottle keeps failed sign in history per key ("account:<email>" or "ip:<address>")
+// in login_throttles, so that limits hold across server instances and restarts
+type LoginThrottle struct {
+	db *database.Database
+}
+
+func NewLoginThrottle(db *database.Database) *LoginThrottle {
+	return &LoginThrottle{db: db}
+}
+
+// State returns the failure history for key; unknown keys have none
+func (t *LoginThrottle) State(ctx context.Context, key string) (FailureState, error) {
+	var st FailureState
+	var lockedUntil *time.Time
+	err := t.db.Pool.QueryRow(ctx,
+		"SELECT failures, last_failure_at, locked_until FROM login_throttles WHERE key = $1", key,
+	).Scan(&st.Failures, &st.LastFailure, &lockedUntil)
+	if err == pgx.ErrNoRows {
+		return FailureState{}, nil
+	}
+	if lockedUntil != nil {
+		st.LockedUntil = *lockedUntil
+	}
+	return st, err
+}
+
+// Fail records a failed attempt under policy and reports whether it locked the key
+func (t *LoginThrottle) Fail(ctx context.Context, key string, policy LockoutPolicy) (FailureState, bool, error) {
+	tx, err := t.db.BeginTx(ctx)
+	if err != nil {
+		return FailureState{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO login_throttles (key, failures, last_failure_at) VALUES ($1, 0, NOW())
+		ON CONFLICT (key) DO NOTHING
+	`, key); err != nil {
+		return FailureState{}, false, err
+	}
+
+	var st FailureState
+	var lockedUntil *time.Time
+	if err := tx.QueryRow(ctx,
+		"SELECT failures, last_failure_at, locked_until FROM login_throttles WHERE key = $1 FOR UPDATE", key,
+	).Scan(&st.Failures, &st.LastFailure, &lockedUntil); err != nil {
+		return FailureState{}, false, err
+	}
+	if lockedUntil != nil {
+		st.LockedUntil = *lockedUntil
+	}
+
+	st, locked := policy.RecordFailure(st, time.Now())
+	var until *time.Time
+	if !st.LockedUntil.IsZero() {
+		until = &st.LockedUntil
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE login_throttles SET failures = $1, last_failure_at = $2, locked_until = $3 WHERE key = $4",
+		st.Failures, st.LastFailure, until, key); err != nil {
+		return FailureState{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return FailureState{}, false, err
+	}
+	return st, locked, nil
+}
+
+// Reset forgets the failure history for key, after a successful sign in or an admin unlock
+func (t *LoginThrottle) Reset(ctx context.Context, key string) error {
+	_, err := t.db.Pool.Exec(ctx, "DELETE FROM login_throttles WHERE key = $1", key)
+	return err
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const passwordResetTTL = time.Hour
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// IssuePasswordReset creates a single-use reset token for the user, replacing any earlier
+// one that has not been used
+func (s *TokenStore) IssuePasswordReset(ctx context.Context, userID uuid.UUID, requestIP string) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		"DELETE FROM password_reset_tokens WHERE user_id = $1 AND used_at IS NULL", userID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, requested_ip)
+		VALUES ($1, $2, $3, $4)
+	`, userID, HashCode(token), time.Now().Add(passwordResetTTL), requestIP); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumePasswordReset marks a reset token used within tx and returns its user. The token
+// stays usable if tx is rolled back.
+func (s *TokenStore) ConsumePasswordReset(ctx context.Context, tx pgx.Tx, token string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := tx.QueryRow(ctx, `
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, HashCode(token)).Scan(&userID)
+	if err == pgx.ErrNoRows {
+		return uuid.Nil, ErrInvalidResetToken
+	}
+	return userID, err
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionTracker records the activity of the session an access token belongs to
+type SessionTracker interface {
+	Touch(ctx context.Context, claims *Claims, ipAddress string) error
+}
+
+// lastSeenGranularity limits how often a busy session's last activity is written
+const lastSeenGranularity = time.Minute
+
+// Touch updates when and from where a session was last used
+func (s *TokenStore) Touch(ctx context.Context, claims *Claims, ipAddress string) error {
+	if claims.SessionID == "" {
+		return nil
+	}
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE sessions SET last_seen_at = NOW(), ip_address = $1
+		WHERE id::text = $2 AND revoked_at IS NULL AND last_seen_at < $3
+	`, ipAddress, claims.SessionID, time.Now().Add(-lastSeenGranularity))
+	return err
+}
+
+// Sessions lists a user's sessions that are neither revoked nor expired, most recently used first
+func (s *TokenStore) Sessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, device, ip_address, user_agent, created_at, last_seen_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.Device, &session.IPAddress, &session.UserAgent,
+			&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeUserSession ends one of the user's own sessions
+func (s *TokenStore) RevokeUserSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	var owned bool
+	err := s.db.Pool.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL)",
+		sessionID, userID).Scan(&owned)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return ErrSessionNotFound
+	}
+	return s.RevokeSession(ctx, sessionID)
+}
+
+// RevokeOtherSessions ends every session of the user except keep and returns how many it ended
+func (s *TokenStore) RevokeOtherSessions(ctx context.Context, userID, keep uuid.UUID) (int, error) {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		"UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL", userID, keep)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL", userID, keep); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// DeviceName summarizes a user agent as "<browser> on <platform>", for listing sessions
+func DeviceName(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	// Order matters: Edge and Opera also claim Chrome, and Chrome also claims Safari
+	for _, b := range []struct{ marker, name string }{
+		{"edg/", "Edge"}, {"opr/", "Opera"}, {"firefox/", "Firefox"}, {"chrome/", "Chrome"},
+		{"crios/", "Chrome"}, {"safari/", "Safari"}, {"okhttp", "Android app"}, {"dart", "Mobile app"},
+		{"curl/", "curl"}, {"postman", "Postman"},
+	} {
+		if strings.Contains(ua, b.marker) {
+			browser = b.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, p := range []struct{ marker, name string }{
+		{"android", "Android"}, {"iphone", "iPhone"}, {"ipad", "iPad"}, {"windows", "Windows"},
+		{"mac os x", "macOS"}, {"cros", "ChromeOS"}, {"linux", "Linux"},
+	} {
+		if strings.Contains(ua, p.marker) {
+			platform = p.name
+			break
+		}
+	}
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
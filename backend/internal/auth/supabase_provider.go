@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"rpms-backend/internal/supabase"
+
+	"github.com/google/uuid"
+)
+
+// SupabaseProvider delegates registration, verification and sign in to Supabase Auth
+type SupabaseProvider struct {
+	client *supabase.Client
+}
+
+func NewSupabaseProvider(client *supabase.Client) *SupabaseProvider {
+	return &SupabaseProvider{client: client}
+}
+
+func (p *SupabaseProvider) SignUp(ctx context.Context, email, password string, metadata map[string]interface{}) error {
+	_, err := p.client.SignUp(email, password, metadata)
+	return err
+}
+
+func (p *SupabaseProvider) Verify(ctx context.Context, email, code string) (*Identity, error) {
+	user, err := p.client.Verify(email, code)
+	if rejected(err) {
+		return nil, errors.Join(ErrInvalidCode, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return supabaseIdentity(user, "")
+}
+
+func (p *SupabaseProvider) ResendVerification(ctx context.Context, email string) error {
+	err := p.client.Resend(email)
+	var sbErr *supabase.SupabaseError
+	if errors.As(err, &sbErr) && sbErr.StatusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+	return err
+}
+
+func (p *SupabaseProvider) SignIn(ctx context.Context, email, password string) (*Identity, error) {
+	resp, err := p.client.SignIn(email, password)
+	if rejected(err) {
+		return nil, errors.Join(ErrInvalidCredentials, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return supabaseIdentity(&resp.User, password)
+}
+
+func (p *SupabaseProvider) CreateConfirmed(ctx context.Context, email, password string, metadata map[string]interface{}) (*Identity, error) {
+	user, err := p.client.AdminCreateUser(email, password, metadata)
+	if err != nil {
+		return nil, err
+	}
+	return supabaseIdentity(user, password)
+}
+
+func (p *SupabaseProvider) UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	return ignoreNotFound(p.client.AdminUpdatePassword(userID.String(), password))
+}
+
+func (p *SupabaseProvider) UpdateMetadata(ctx context.Context, userID uuid.UUID, metadata map[string]interface{}) error {
+	return ignoreNotFound(p.client.AdminUpdateUser(userID.String(), supabase.AdminUpdateUserRequest{UserMetadata: metadata}))
+}
+
+// bannedFor is how long Supabase bans a disabled account; it is lifted explicitly on reactivation
+const bannedFor = "876000h"
+
+func (p *SupabaseProvider) SetDisabled(ctx context.Context, userID uuid.UUID, disabled bool) error {
+	duration := "none"
+	if disabled {
+		duration = bannedFor
+	}
+	return ignoreNotFound(p.client.AdminUpdateUser(userID.String(), supabase.AdminUpdateUserRequest{BanDuration: duration}))
+}
+
+func (p *SupabaseProvider) Delete(ctx context.Context, userID uuid.UUID) error {
+	return ignoreNotFound(p.client.AdminDeleteUser(userID.String()))
+}
+
+// ignoreNotFound drops the error of an admin call on an account Supabase does not know, such as
+// one imported or provisioned through single sign-on, which only exists locally
+func ignoreNotFound(err error) error {
+	var sbErr *supabase.SupabaseError
+	if errors.As(err, &sbErr) && sbErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// rejected reports whether Supabase turned the credentials or code down, as opposed to failing
+// to answer; only a rejection counts against the account
+func rejected(err error) bool {
+	var sbErr *supabase.SupabaseError
+	return errors.As(err, &sbErr) && (sbErr.StatusCode == http.StatusBadRequest || sbErr.StatusCode == http.StatusUnauthorized)
+}
+
+// supabaseIdentity converts a Supabase user, hashing the password when we were given it
+// so that it can also be checked locally
+func supabaseIdentity(user *supabase.User, password string) (*Identity, error) {
+	id, err := uuid.Parse(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	identity := &Identity{ID: id, Email: user.Email, Metadata: user.UserMetadata}
+	if password != "" {
+		if identity.PasswordHash, err = HashPassword(password); err != nil {
+			return nil, err
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rpms-backend/internal/config"
+	"rpms-backend/internal/supabase"
+
+	"github.com/google/uuid"
+)
+
+// supabaseAnswering returns a provider whose Supabase answers every request with status
+func supabaseAnswering(t *testing.T, status int) *SupabaseProvider {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"msg": "refused"}`))
+	}))
+	t.Cleanup(server.Close)
+	return NewSupabaseProvider(supabase.NewClient(&config.Config{Supabase: config.SupabaseConfig{URL: server.URL}}))
+}
+
+func TestSupabaseProviderRejections(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		rejected bool
+	}{
+		{"bad request", http.StatusBadRequest, true},
+		{"unauthorized", http.StatusUnauthorized, true},
+		{"rate limited", http.StatusTooManyRequests, false},
+		{"server error", http.StatusInternalServerError, false},
+		{"unavailable", http.StatusServiceUnavailable, false},
+	}
+	for _, tc := range cases {
+		provider := supabaseAnswering(t, tc.status)
+
+		_, err := provider.SignIn(context.Background(), "author@test.com", "password123")
+		if err == nil || errors.Is(err, ErrInvalidCredentials) != tc.rejected {
+			t.Errorf("%s: SignIn error = %v, want invalid credentials %v", tc.name, err, tc.rejected)
+		}
+		_, err = provider.Verify(context.Background(), "author@test.com", "123456")
+		if err == nil || errors.Is(err, ErrInvalidCode) != tc.rejected {
+			t.Errorf("%s: Verify error = %v, want invalid code %v", tc.name, err, tc.rejected)
+		}
+	}
+}
+
+func TestSupabaseProviderAdminCalls(t *testing.T) {
+	ctx := context.Background()
+	calls := map[string]func(p *SupabaseProvider, id uuid.UUID) error{
+		"UpdatePassword": func(p *SupabaseProvider, id uuid.UUID) error { return p.UpdatePassword(ctx, id, "password123") },
+		"UpdateMetadata": func(p *SupabaseProvider, id uuid.UUID) error {
+			return p.UpdateMetadata(ctx, id, map[string]interface{}{"role": "editor"})
+		},
+		"SetDisabled": func(p *SupabaseProvider, id uuid.UUID) error { return p.SetDisabled(ctx, id, true) },
+		"Delete":      func(p *SupabaseProvider, id uuid.UUID) error { return p.Delete(ctx, id) },
+	}
+	// Accounts Supabase does not know are only local, which is not an error
+	unknown, failing := supabaseAnswering(t, http.StatusNotFound), supabaseAnswering(t, http.StatusInternalServerError)
+	for name, call := range calls {
+		if err := call(unknown, uuid.New()); err != nil {
+			t.Errorf("%s on an unknown account: %v", name, err)
+		}
+		if err := call(failing, uuid.New()); err == nil {
+			t.Errorf("%s with Supabase failing: no error", name)
+		}
+	}
+}
+
+func TestSupabaseProviderUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	provider := NewSupabaseProvider(supabase.NewClient(&config.Config{Supabase: config.SupabaseConfig{URL: server.URL}}))
+
+	if _, err := provider.SignIn(context.Background(), "author@test.com", "password123"); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("SignIn error = %v, want a connection error", err)
+	}
+	if _, err := provider.Verify(context.Background(), "author@test.com", "123456"); err == nil || errors.Is(err, ErrInvalidCode) {
+		t.Errorf("Verify error = %v, want a connection error", err)
+	}
+}
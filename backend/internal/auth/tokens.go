@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"rpms-backend/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused means a rotated token was presented again, so it has leaked
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrAccountDeactivated means the token is valid but its user may no longer sign in
+	ErrAccountDeactivated = errors.New("account deactivated")
+	// ErrOtherTenant means the token is valid but was presented to another institution
+	ErrOtherTenant = errors.New("account belongs to another tenant")
+)
+
+// RevocationList tells whether an otherwise valid access token has been revoked
+type RevocationList interface {
+	IsRevoked(ctx context.Context, claims *Claims) (bool, error)
+}
+
+// TokenStore keeps refresh tokens and revoked access tokens. Refresh tokens are opaque,
+// stored hashed and rotated on every use; all tokens descending from one sign in share a
+// family, which is revoked as a whole when a rotated token comes back.
+type TokenStore struct {
+	db     *database.Database
+	expiry time.Duration
+}
+
+func NewTokenStore(db *database.Database, jwtManager *JWTManager) *TokenStore {
+	return &TokenStore{db: db, expiry: jwtManager.RefreshExpiry()}
+}
+
+// newOpaqueToken returns a random URL-safe token; only its hash is stored
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Client describes where a sign in comes from
+type Client struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Issue starts a new token family for a fresh sign in, recorded as a session with the same
+// ID, and returns the session ID with the first refresh token
+func (s *TokenStore) Issue(ctx context.Context, userID uuid.UUID, client Client) (uuid.UUID, string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	sessionID := uuid.New()
+	expiresAt := time.Now().Add(s.expiry)
+
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO sessions (id, user_id, device, ip_address, user_agent, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, sessionID, userID, DeviceName(client.UserAgent), client.IPAddress, client.UserAgent, expiresAt); err != nil {
+		return uuid.Nil, "", err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, sessionID, HashCode(token), expiresAt); err != nil {
+		return uuid.Nil, "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, "", err
+	}
+	return sessionID, token, nil
+}
+
+// refreshState is what Rotate knows about a presented refresh token
+type refreshState struct {
+	expiresAt time.Time
+	usedAt    *time.Time
+	revokedAt *time.Time
+	active    bool
+	tenantID  uuid.UUID
+}
+
+// checkRefresh decides what becomes of a refresh token presented at now to tenant, nil for
+// any tenant: nil to rotate it, ErrRefreshTokenReused to revoke its family, or the error to
+// answer with. Reuse is detected first so a leaked token still ends the family.
+func checkRefresh(token refreshState, now time.Time, tenant *uuid.UUID) error {
+	if token.revokedAt != nil || now.After(token.expiresAt) {
+		return ErrInvalidRefreshToken
+	}
+	if token.usedAt != nil {
+		return ErrRefreshTokenReused
+	}
+	if tenant != nil && *tenant != token.tenantID {
+		return ErrOtherTenant
+	}
+	if !token.active {
+		return ErrAccountDeactivated
+	}
+	return nil
+}
+
+// Rotate exchanges a refresh token for a new one in the same family and returns the
+// user and session it belongs to. The session's last activity is updated with the client.
+// Tokens of deactivated accounts are refused with ErrAccountDeactivated, and when tenant is
+// not nil, tokens of its accounts only are accepted, others being refused with ErrOtherTenant
+// and left valid.
+func (s *TokenStore) Rotate(ctx context.Context, token string, client Client, tenant *uuid.UUID) (uuid.UUID, uuid.UUID, string, error) {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+	defer tx.Rollback(ctx)
+
+	var id, userID, familyID uuid.UUID
+	var state refreshState
+	err = tx.QueryRow(ctx, `
+		SELECT rt.id, rt.user_id, rt.family_id, rt.expires_at, rt.used_at, rt.revoked_at, u.is_active, u.tenant_id
+		FROM refresh_tokens rt JOIN users u ON u.id = rt.user_id
+		WHERE rt.token_hash = $1 FOR UPDATE OF rt
+	`, HashCode(token)).Scan(&id, &userID, &familyID, &state.expiresAt, &state.usedAt, &state.revokedAt, &state.active, &state.tenantID)
+	if err == pgx.ErrNoRows {
+		return uuid.Nil, uuid.Nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+
+	switch err := checkRefresh(state, time.Now(), tenant); err {
+	case nil:
+	case ErrRefreshTokenReused:
+		// Whoever holds the newer token is cut off too; the user has to sign in again
+		if err := revokeFamily(ctx, tx, familyID); err != nil {
+			return uuid.Nil, uuid.Nil, "", err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return uuid.Nil, uuid.Nil, "", err
+		}
+		return uuid.Nil, uuid.Nil, "", ErrRefreshTokenReused
+	default:
+		return uuid.Nil, uuid.Nil, "", err
+	}
+
+	next, err := newOpaqueToken()
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+	nextExpiry := time.Now().Add(s.expiry)
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, familyID, HashCode(next), nextExpiry); err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1", id); err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+	// Families started before sessions existed get their session on first refresh
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO sessions (id, user_id, device, ip_address, user_agent, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE
+		SET ip_address = EXCLUDED.ip_address, user_agent = EXCLUDED.user_agent, device = EXCLUDED.device,
+			last_seen_at = NOW(), expires_at = EXCLUDED.expires_at
+	`, familyID, userID, DeviceName(client.UserAgent), client.IPAddress, client.UserAgent, nextExpiry); err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+
+	return userID, familyID, next, nil
+}
+
+// revokeFamily ends a session and every refresh token in its family
+func revokeFamily(ctx context.Context, tx pgx.Tx, familyID uuid.UUID) error {
+	if _, err := tx.Exec(ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL", familyID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx,
+		"UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", familyID)
+	return err
+}
+
+// RevokeFamily ends the sign in a refresh token belongs to. Unknown tokens are ignored.
+func (s *TokenStore) RevokeFamily(ctx context.Context, token string) error {
+	var familyID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, "SELECT family_id FROM refresh_tokens WHERE token_hash = $1", HashCode(token)).Scan(&familyID)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.RevokeSession(ctx, familyID)
+}
+
+// RevokeSession ends one session, its refresh tokens and the access tokens issued for it
+func (s *TokenStore) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := revokeFamily(ctx, tx, sessionID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RevokeUser ends every sign in of a user, including access tokens already issued
+func (s *TokenStore) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.db.Pool.Exec(ctx,
+		"UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID); err != nil {
+		return err
+	}
+	if _, err := s.db.Pool.Exec(ctx,
+		"UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", userID); err != nil {
+		return err
+	}
+	// Token issue times are whole seconds, so the cutoff is too
+	_, err := s.db.Pool.Exec(ctx,
+		"UPDATE users SET tokens_revoked_at = date_trunc('second', NOW()) WHERE id = $1", userID)
+	return err
+}
+
+// ExpireAccessTokens invalidates the access tokens a user already holds but keeps their
+// refresh tokens, so the next refresh picks up changed claims without signing out
+func (s *TokenStore) ExpireAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.db.Pool.Exec(ctx,
+		"UPDATE users SET tokens_revoked_at = date_trunc('second', NOW()) WHERE id = $1", userID)
+	return err
+}
+
+// RevokeAccessToken puts an access token on the revocation list until it would have expired
+func (s *TokenStore) RevokeAccessToken(ctx context.Context, claims *Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	// Entries are only needed while the token could still be presented
+	if _, err := s.db.Pool.Exec(ctx, "DELETE FROM revoked_tokens WHERE expires_at < NOW()"); err != nil {
+		return err
+	}
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`, claims.ID, claims.ExpiresAt.Time)
+	return err
+}
+
+// IsRevoked reports whether an access token is on the revocation list, belongs to a revoked
+// session, was issued before its user's sessions were revoked, or belongs to a deactivated account
+func (s *TokenStore) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	var revoked bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+			OR EXISTS (SELECT 1 FROM sessions WHERE id::text = $4 AND revoked_at IS NOT NULL)
+			OR EXISTS (SELECT 1 FROM users WHERE id::text = $2 AND (tokens_revoked_at > $3 OR is_active = FALSE))
+	`, claims.ID, claims.UserID, issuedAt, claims.SessionID).Scan(&revoked)
+	return revoked, err
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCheckRefresh(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+	home, other := uuid.New(), uuid.New()
+	valid := refreshState{expiresAt: now.Add(time.Hour), active: true, tenantID: home}
+
+	cases := []struct {
+		name   string
+		token  func(refreshState) refreshState
+		tenant *uuid.UUID
+		want   error
+	}{
+		{"valid", func(s refreshState) refreshState { return s }, nil, nil},
+		{"expired", func(s refreshState) refreshState { s.expiresAt = earlier; return s }, nil, ErrInvalidRefreshToken},
+		{"revoked", func(s refreshState) refreshState { s.revokedAt = &earlier; return s }, nil, ErrInvalidRefreshToken},
+		{"already rotated", func(s refreshState) refreshState { s.usedAt = &earlier; return s }, nil, ErrRefreshTokenReused},
+		// Once the family is revoked its rotated tokens are plain invalid, not a new reuse
+		{"rotated and revoked", func(s refreshState) refreshState { s.usedAt = &earlier; s.revokedAt = &now; return s }, nil, ErrInvalidRefreshToken},
+		{"deactivated", func(s refreshState) refreshState { s.active = false; return s }, nil, ErrAccountDeactivated},
+		// A leaked token of a deactivated account still ends its family
+		{"rotated and deactivated", func(s refreshState) refreshState { s.usedAt = &earlier; s.active = false; return s }, nil, ErrRefreshTokenReused},
+		{"own tenant", func(s refreshState) refreshState { return s }, &home, nil},
+		{"other tenant", func(s refreshState) refreshState { return s }, &other, ErrOtherTenant},
+		{"rotated on another tenant", func(s refreshState) refreshState { s.usedAt = &earlier; return s }, &other, ErrRefreshTokenReused},
+	}
+	for _, tc := range cases {
+		if got := checkRefresh(tc.token(valid), now, tc.tenant); got != tc.want {
+			t.Errorf("%s: checkRefresh = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B, SHA1 with the ASCII key "12345678901234567890" and 8 digits
+func TestHOTPRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tc := range cases {
+		step := uint64(totpStep(time.Unix(tc.unix, 0)))
+		if got := hotp(key, step, 8); got != tc.want {
+			t.Errorf("TOTP at %d = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+// RFC 4226 appendix D, six digit HOTP values for counters 0..9
+func TestHOTPRFC4226Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp(key, uint64(counter), 6); got != code {
+			t.Errorf("HOTP(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111111, 0)
+
+	code, err := TOTPCode(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step, ok := ValidateTOTP(secret, code, now, 0)
+	if !ok || step != totpStep(now) {
+		t.Fatalf("current code rejected: step %d ok %v", step, ok)
+	}
+	if _, ok := ValidateTOTP(secret, code, now, step); ok {
+		t.Error("replayed code accepted")
+	}
+
+	previous, _ := TOTPCode(secret, now.Add(-totpPeriod))
+	if _, ok := ValidateTOTP(secret, previous, now, 0); !ok {
+		t.Error("code from the previous period should be accepted")
+	}
+	stale, _ := TOTPCode(secret, now.Add(-3*totpPeriod))
+	if _, ok := ValidateTOTP(secret, stale, now, 0); ok {
+		t.Error("code from three periods ago accepted")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now, 0); ok {
+		t.Error("short code accepted")
+	}
+	if _, ok := ValidateTOTP("not base32!", code, now, 0); ok {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("RPMS SMU", "editor@smu.edu", "JBSWY3DPEHPK3PXP")
+	for _, part := range []string{"otpauth://totp/RPMS%20SMU:editor@smu.edu?", "secret=JBSWY3DPEHPK3PXP", "issuer=RPMS+SMU", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("URI %q missing %q", uri, part)
+		}
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("malformed recovery code %q", code)
+		}
+		if NormalizeRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", ""))) != code {
+			t.Errorf("NormalizeRecoveryCode does not round trip %q", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) != len(codes) {
+		t.Error("duplicate recovery codes")
+	}
+}
@@ -8,8 +8,11 @@ import (
 type Config struct {
 	Database DatabaseConfig
 	Supabase SupabaseConfig
+	Auth     AuthConfig
+	OIDC     OIDCConfig
 	JWT      JWTConfig
 	SMTP     SMTPConfig
+	Jobs     JobsConfig
 	GinMode  string
 }
 
@@ -29,9 +32,39 @@ type SupabaseConfig struct {
 	Bucket         string
 }
 
+type AuthConfig struct {
+	// Provider is the identity provider accounts are registered with: "supabase" or "local"
+	Provider string
+	// PasswordResetURL is the frontend page reset links point to; the token is appended as ?token=
+	PasswordResetURL string
+}
+
+// OIDCConfig sets up single sign-on with an OpenID Connect provider, such as the university's
+// identity provider. Single sign-on is off while Issuer is empty.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the frontend page the provider returns to; it posts the code back to the API
+	RedirectURL string
+	Scopes      []string
+	// RoleClaim is the ID token claim holding the user's groups, e.g. "groups" or "realm_access.roles"
+	RoleClaim string
+	// RoleMap assigns roles to claim values as "value=role,...", the first match winning
+	RoleMap string
+	// DefaultRole is given to provisioned users that no claim value maps to a role
+	DefaultRole string
+}
+
 type JWTConfig struct {
 	Secret string
-	Expiry string
+	// Expiry is the lifetime of access tokens; clients renew them with a refresh token
+	Expiry        string
+	RefreshExpiry string
+}
+
+type JobsConfig struct {
+	PublishInterval string
 }
 
 type SMTPConfig struct {
@@ -57,9 +90,24 @@ func New() *Config {
 			ServiceRoleKey: getEnv("SUPABASE_SERVICE_ROLE_KEY", ""),
 			Bucket:         getEnv("SUPABASE_BUCKET", "chat-attachments"),
 		},
+		Auth: AuthConfig{
+			Provider:         getEnv("AUTH_PROVIDER", defaultAuthProvider()),
+			PasswordResetURL: getEnv("PASSWORD_RESET_URL", ""),
+		},
+		OIDC: OIDCConfig{
+			Issuer:       getEnv("OIDC_ISSUER", ""),
+			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv("OIDC_REDIRECT_URL", ""),
+			Scopes:       strings.Fields(getEnv("OIDC_SCOPES", "openid email profile")),
+			RoleClaim:    getEnv("OIDC_ROLE_CLAIM", "groups"),
+			RoleMap:      getEnv("OIDC_ROLE_MAP", ""),
+			DefaultRole:  getEnv("OIDC_DEFAULT_ROLE", "author"),
+		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "your-secret-key"),
-			Expiry: getEnv("JWT_EXPIRY", "24h"),
+			Secret:        getEnv("JWT_SECRET", "your-secret-key"),
+			Expiry:        getEnv("JWT_EXPIRY", "15m"),
+			RefreshExpiry: getEnv("JWT_REFRESH_EXPIRY", "720h"),
 		},
 		SMTP: SMTPConfig{
 			Host:     getEnv("SMTP_HOST", "smtp.gmail.com"),
@@ -67,10 +115,21 @@ func New() *Config {
 			Email:    getEnv("SMTP_EMAIL", ""),
 			Password: getEnv("SMTP_PASSWORD", ""),
 		},
+		Jobs: JobsConfig{
+			PublishInterval: getEnv("PUBLISH_JOB_INTERVAL", "1m"),
+		},
 		GinMode: getEnv("GIN_MODE", "debug"),
 	}
 }
 
+// defaultAuthProvider keeps Supabase for existing deployments and runs fully local when it is not configured
+func defaultAuthProvider() string {
+	if os.Getenv("SUPABASE_URL") != "" {
+		return "supabase"
+	}
+	return "local"
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -7,6 +7,7 @@ import (
 
 	"rpms-backend/internal/config"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -253,7 +254,7 @@ func RunMigrations(db *Database) error {
 			IF EXISTS (SELECT 1 FROM information_schema.constraint_column_usage WHERE table_name = 'papers' AND constraint_name = 'papers_status_check') THEN
 				ALTER TABLE papers DROP CONSTRAINT papers_status_check;
 			END IF;
-			ALTER TABLE papers ADD CONSTRAINT papers_status_check CHECK (status IN ('draft', 'submitted', 'under_review', 'approved', 'rejected', 'published', 'recommended_for_publication'));
+			ALTER TABLE papers ADD CONSTRAINT papers_status_check CHECK (status IN ('draft', 'submitted', 'under_review', 'approved', 'rejected', 'published', 'recommended_for_publication', 'withdrawn', 'retracted'));
 		END $$;
 	`
 
@@ -343,6 +344,567 @@ func RunMigrations(db *Database) error {
 		ALTER TABLE events ADD COLUMN IF NOT EXISTS video_url TEXT;
 	`
 
+	// Create calls table (calls for papers and grant calls)
+	createCallsTable := `
+	CREATE TABLE IF NOT EXISTS calls (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		title VARCHAR(500) NOT NULL,
+		description TEXT,
+		type VARCHAR(50) NOT NULL CHECK (type IN ('call_for_papers', 'grant')),
+		eligible_categories TEXT[] DEFAULT '{}',
+		eligible_ranks TEXT[] DEFAULT '{}',
+		required_fields TEXT[] DEFAULT '{}',
+		rubric JSONB DEFAULT '[]',
+		opens_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		closes_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		coordinator_id UUID REFERENCES users(id) ON DELETE CASCADE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		CHECK (closes_at > opens_at)
+	);`
+
+	// Link papers to the call they were submitted against
+	addCallIdToPapers := `
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS call_id UUID REFERENCES calls(id) ON DELETE SET NULL;
+		CREATE INDEX IF NOT EXISTS idx_papers_call_id ON papers(call_id);
+		CREATE INDEX IF NOT EXISTS idx_calls_closes_at ON calls(closes_at);
+	`
+
+	// Create journal, volume and issue tables
+	createJournalTables := `
+	CREATE TABLE IF NOT EXISTS journals (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		name VARCHAR(255) UNIQUE NOT NULL,
+		issn VARCHAR(20),
+		publisher VARCHAR(255),
+		description TEXT,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+	CREATE TABLE IF NOT EXISTS journal_volumes (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		journal_id UUID NOT NULL REFERENCES journals(id) ON DELETE CASCADE,
+		number INTEGER NOT NULL,
+		year INTEGER NOT NULL,
+		title VARCHAR(255),
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		UNIQUE(journal_id, number)
+	);
+	CREATE TABLE IF NOT EXISTS journal_issues (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		volume_id UUID NOT NULL REFERENCES journal_volumes(id) ON DELETE CASCADE,
+		number INTEGER NOT NULL,
+		title VARCHAR(255),
+		publication_date TIMESTAMP WITH TIME ZONE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		UNIQUE(volume_id, number)
+	);`
+
+	// Add issue placement columns to papers
+	addIssueColumnsToPapers := `
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS issue_id UUID REFERENCES journal_issues(id) ON DELETE SET NULL;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS page_start INTEGER;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS page_end INTEGER;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS issue_order INTEGER;
+		CREATE INDEX IF NOT EXISTS idx_papers_issue_id ON papers(issue_id);
+	`
+
+	// Add withdrawal, retraction and soft deletion columns to papers
+	addPaperLifecycleColumns := `
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS withdrawn_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS withdrawal_reason TEXT;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS retracted_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS retracted_by UUID REFERENCES users(id) ON DELETE SET NULL;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS retraction_notice TEXT;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+		CREATE INDEX IF NOT EXISTS idx_papers_deleted_at ON papers(deleted_at);
+	`
+
+	// Scheduled publication and manuscript embargo
+	addPublicationSchedule := `
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS embargo_until TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE news ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE events ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP WITH TIME ZONE;
+		CREATE INDEX IF NOT EXISTS idx_papers_publish_at ON papers(publish_at) WHERE publish_at IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_papers_embargo_until ON papers(embargo_until) WHERE embargo_until IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_news_publish_at ON news(publish_at) WHERE publish_at IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_events_publish_at ON events(publish_at) WHERE publish_at IS NOT NULL;
+	`
+
+	// Researcher profiles: ORCID iD and paper contributors beyond the submitting author
+	addResearcherProfiles := `
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS orcid VARCHAR(19);
+
+		CREATE TABLE IF NOT EXISTS paper_contributors (
+			paper_id UUID NOT NULL REFERENCES papers(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			role VARCHAR(50) NOT NULL DEFAULT 'co-author',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (paper_id, user_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_paper_contributors_user_id ON paper_contributors(user_id);
+	`
+
+	// Institution -> college -> department hierarchy, user affiliations and unit attribution of papers
+	createOrgUnits := `
+		CREATE TABLE IF NOT EXISTS org_units (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			parent_id UUID REFERENCES org_units(id) ON DELETE RESTRICT,
+			type VARCHAR(20) NOT NULL CHECK (type IN ('institution', 'college', 'department')),
+			name VARCHAR(255) NOT NULL,
+			code VARCHAR(50) UNIQUE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			CHECK ((type = 'institution') = (parent_id IS NULL))
+		);
+		CREATE INDEX IF NOT EXISTS idx_org_units_parent_id ON org_units(parent_id);
+
+		CREATE TABLE IF NOT EXISTS user_affiliations (
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			unit_id UUID NOT NULL REFERENCES org_units(id) ON DELETE CASCADE,
+			is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (user_id, unit_id)
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_user_affiliations_primary ON user_affiliations(user_id) WHERE is_primary;
+		CREATE INDEX IF NOT EXISTS idx_user_affiliations_unit_id ON user_affiliations(unit_id);
+
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS unit_id UUID REFERENCES org_units(id) ON DELETE SET NULL;
+		CREATE INDEX IF NOT EXISTS idx_papers_unit_id ON papers(unit_id);
+
+		-- Attribute legacy papers to the institution matching their institution_code
+		UPDATE papers p SET unit_id = u.id
+		FROM org_units u
+		WHERE p.unit_id IS NULL AND u.type = 'institution' AND u.code = p.institution_code;
+	`
+
+	// Tenants (one per university) and the trigger that stamps child rows with their parent's tenant
+	createTenants := `
+		CREATE TABLE IF NOT EXISTS tenants (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			slug VARCHAR(50) UNIQUE NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			hostnames TEXT[] NOT NULL DEFAULT '{}',
+			config JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_tenants_hostnames ON tenants USING GIN (hostnames);
+
+		INSERT INTO tenants (id, slug, name, config)
+		VALUES ('00000000-0000-0000-0000-000000000001', 'smu', 'SMU',
+			'{"publication_id_prefix": "SMU_P", "publication_id_start": 201817001, "branding": {"display_name": "SMU"}}')
+		ON CONFLICT (id) DO NOTHING;
+
+		-- set_tenant_from_parent(parent_table, fk_column) copies tenant_id from the referenced row
+		-- when an insert does not set one; rows without a parent fall back to the default tenant.
+		CREATE OR REPLACE FUNCTION set_tenant_from_parent() RETURNS trigger AS $$
+		DECLARE
+			parent UUID;
+		BEGIN
+			IF NEW.tenant_id IS NULL AND TG_NARGS = 2 THEN
+				EXECUTE format('SELECT ($1).%I', TG_ARGV[1]) INTO parent USING NEW;
+				IF parent IS NOT NULL THEN
+					EXECUTE format('SELECT tenant_id FROM %I WHERE id = $1', TG_ARGV[0]) INTO NEW.tenant_id USING parent;
+				END IF;
+			END IF;
+			IF NEW.tenant_id IS NULL THEN
+				NEW.tenant_id := '00000000-0000-0000-0000-000000000001';
+			END IF;
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+	`
+
+	// Every table gets a tenant_id, derived from the parent row it belongs to
+	tenantParents := []struct{ table, parent, column string }{
+		{"users", "", ""},
+		{"journals", "", ""},
+		{"org_units", "org_units", "parent_id"},
+		{"papers", "users", "author_id"},
+		{"reviews", "papers", "paper_id"},
+		{"events", "users", "coordinator_id"},
+		{"news", "users", "editor_id"},
+		{"calls", "users", "coordinator_id"},
+		{"messages", "users", "sender_id"},
+		{"notifications", "users", "user_id"},
+		{"likes", "users", "user_id"},
+		{"comments", "users", "user_id"},
+		{"shares", "users", "user_id"},
+		{"journal_volumes", "journals", "journal_id"},
+		{"journal_issues", "journal_volumes", "volume_id"},
+		{"paper_contributors", "papers", "paper_id"},
+		{"user_affiliations", "users", "user_id"},
+	}
+	var addTenantColumns string
+	for _, t := range tenantParents {
+		args := ""
+		if t.parent != "" {
+			args = fmt.Sprintf("'%s', '%s'", t.parent, t.column)
+		}
+		addTenantColumns += fmt.Sprintf(`
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE RESTRICT;
+		UPDATE %[1]s SET tenant_id = '00000000-0000-0000-0000-000000000001' WHERE tenant_id IS NULL;
+		ALTER TABLE %[1]s ALTER COLUMN tenant_id SET NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_%[1]s_tenant_id ON %[1]s(tenant_id);
+		DROP TRIGGER IF EXISTS trg_%[1]s_tenant ON %[1]s;
+		CREATE TRIGGER trg_%[1]s_tenant BEFORE INSERT ON %[1]s
+			FOR EACH ROW EXECUTE FUNCTION set_tenant_from_parent(%[2]s);
+		`, t.table, args)
+	}
+
+	// Journal names and unit codes only need to be unique within a tenant
+	scopeUniqueKeysToTenant := `
+		ALTER TABLE journals DROP CONSTRAINT IF EXISTS journals_name_key;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_journals_tenant_name ON journals(tenant_id, name);
+		ALTER TABLE org_units DROP CONSTRAINT IF EXISTS org_units_code_key;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_org_units_tenant_code ON org_units(tenant_id, code);
+	`
+
+	// Reviews can be saved as drafts and are locked once the paper has a final decision.
+	// Existing reviews were all submitted when they were created.
+	addReviewLifecycle := `
+		ALTER TABLE reviews ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'submitted';
+		ALTER TABLE reviews ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE reviews ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP WITH TIME ZONE;
+		UPDATE reviews SET submitted_at = created_at WHERE submitted_at IS NULL AND status != 'draft';
+		UPDATE reviews r SET status = 'locked', locked_at = NOW()
+		FROM papers p
+		WHERE p.id = r.paper_id AND r.status = 'submitted'
+		  AND p.status IN ('approved', 'rejected', 'published', 'withdrawn', 'retracted');
+		DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE table_name = 'reviews' AND constraint_name = 'reviews_status_check') THEN
+				ALTER TABLE reviews ADD CONSTRAINT reviews_status_check CHECK (status IN ('draft', 'submitted', 'locked'));
+			END IF;
+		END $$;
+		CREATE INDEX IF NOT EXISTS idx_reviews_status ON reviews(status);
+	`
+
+	// Confidential comments to the editors and annotated files attached to reviews
+	addReviewConfidentiality := `
+		ALTER TABLE reviews ADD COLUMN IF NOT EXISTS confidential_comments TEXT;
+
+		CREATE TABLE IF NOT EXISTS review_attachments (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			review_id UUID NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+			file_url TEXT NOT NULL,
+			file_name VARCHAR(255) NOT NULL,
+			file_type VARCHAR(100),
+			file_size BIGINT,
+			note TEXT,
+			confidential BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_review_attachments_review_id ON review_attachments(review_id);
+	`
+
+	// Declared reviewer expertise and paper keywords, used to suggest reviewers
+	addExpertiseAndKeywords := `
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS expertise_keywords TEXT[] NOT NULL DEFAULT '{}';
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS expertise_fields TEXT[] NOT NULL DEFAULT '{}';
+		ALTER TABLE papers ADD COLUMN IF NOT EXISTS keywords TEXT[] NOT NULL DEFAULT '{}';
+		CREATE INDEX IF NOT EXISTS idx_users_expertise_keywords ON users USING GIN(expertise_keywords);
+		CREATE INDEX IF NOT EXISTS idx_papers_keywords ON papers USING GIN(keywords);
+	`
+
+	// Editorial staff assigned to a paper and their internal discussion of it
+	createPaperDiscussions := `
+		CREATE TABLE IF NOT EXISTS paper_staff (
+			paper_id UUID NOT NULL REFERENCES papers(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (paper_id, user_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_paper_staff_user_id ON paper_staff(user_id);
+
+		CREATE TABLE IF NOT EXISTS paper_discussion_messages (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			paper_id UUID NOT NULL REFERENCES papers(id) ON DELETE CASCADE,
+			author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			kind VARCHAR(20) NOT NULL DEFAULT 'comment' CHECK (kind IN ('comment', 'decision')),
+			body TEXT NOT NULL DEFAULT '',
+			mentions UUID[] NOT NULL DEFAULT '{}',
+			attachment_url TEXT,
+			attachment_name VARCHAR(255),
+			attachment_type VARCHAR(100),
+			attachment_size INTEGER,
+			pinned BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_paper_discussion_messages_paper_id ON paper_discussion_messages(paper_id, created_at);
+	`
+
+	// Invitations to review a paper, with due dates and the reviewer's response
+	createReviewAssignments := `
+		CREATE TABLE IF NOT EXISTS review_assignments (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			paper_id UUID NOT NULL REFERENCES papers(id) ON DELETE CASCADE,
+			reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'invited'
+				CHECK (status IN ('invited', 'accepted', 'declined', 'completed', 'cancelled')),
+			due_at TIMESTAMP WITH TIME ZONE,
+			responded_at TIMESTAMP WITH TIME ZONE,
+			completed_at TIMESTAMP WITH TIME ZONE,
+			decline_reason TEXT,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE(paper_id, reviewer_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_review_assignments_reviewer_id ON review_assignments(reviewer_id, status);
+	`
+
+	createPendingRegistrations := `
+		CREATE TABLE IF NOT EXISTS pending_registrations (
+			email VARCHAR(255) PRIMARY KEY,
+			password_hash VARCHAR(255) NOT NULL,
+			metadata JSONB NOT NULL DEFAULT '{}',
+			code_hash VARCHAR(64) NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			sent_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+	`
+
+	createTokenTables := `
+		CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			family_id UUID NOT NULL,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE,
+			revoked_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+		CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+
+		CREATE TABLE IF NOT EXISTS revoked_tokens (
+			jti VARCHAR(64) PRIMARY KEY,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+	`
+
+	createPasswordResets := `
+		CREATE TABLE IF NOT EXISTS password_reset_tokens (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE,
+			requested_ip VARCHAR(64),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_revoked_at TIMESTAMP WITH TIME ZONE;
+	`
+
+	addTwoFactorAuth := `
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+		CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			code_hash VARCHAR(64) NOT NULL,
+			used_at TIMESTAMP WITH TIME ZONE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+	`
+
+	createLoginThrottling := `
+		CREATE TABLE IF NOT EXISTS login_throttles (
+			key VARCHAR(320) PRIMARY KEY,
+			failures INTEGER NOT NULL DEFAULT 0,
+			last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			locked_until TIMESTAMP WITH TIME ZONE
+		);
+
+		CREATE TABLE IF NOT EXISTS login_attempts (
+			id BIGSERIAL PRIMARY KEY,
+			email VARCHAR(255) NOT NULL,
+			user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			ip_address VARCHAR(64),
+			user_agent TEXT,
+			outcome VARCHAR(30) NOT NULL CHECK (outcome IN ('success', 'invalid_credentials', 'throttled', 'locked')),
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts(email, created_at DESC);
+	`
+
+	// The seeded permissions match rbac.DefaultRoles, which also applies to tenants without rows
+	createRoles := `
+		CREATE TABLE IF NOT EXISTS roles (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+			name VARCHAR(50) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			permissions TEXT[] NOT NULL DEFAULT '{}',
+			built_in BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			UNIQUE(tenant_id, name)
+		);
+
+		CREATE TABLE IF NOT EXISTS user_roles (
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+			granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			PRIMARY KEY (user_id, role_id)
+		);
+
+		INSERT INTO roles (tenant_id, name, description, permissions, built_in)
+		SELECT t.id, d.name, d.description, d.permissions::text[], TRUE
+		FROM tenants t
+		CROSS JOIN (VALUES
+			('author', 'Submits papers', '{paper.submit}'),
+			('editor', 'Handles peer review and journals',
+				'{paper.read_all,paper.edit,paper.recommend,paper.review,paper.assign,paper.discuss,review.read_all,review.read_confidential,unit.view,call.view_submissions,journal.manage}'),
+			('coordinator', 'Runs calls, events and news',
+				'{paper.read_all,paper.edit,paper.discuss,review.read_all,unit.view,call.manage,call.view_submissions,event.publish,news.publish}'),
+			('admin', 'Administers the institution',
+				'{call.manage,call.view_submissions,data.import,event.publish,journal.manage,news.publish,paper.assign,paper.discuss,paper.edit,paper.publish,paper.read_all,paper.recommend,paper.review,paper.submit,report.view,review.read_all,review.read_confidential,role.manage,tenant.manage,unit.manage,unit.view,user.manage}')
+		) AS d(name, description, permissions)
+		ON CONFLICT (tenant_id, name) DO NOTHING;
+	`
+
+	// Deactivated accounts keep their data but cannot sign in; admins can also force a
+	// verified account to confirm its email again
+	addAccountStatus := `
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS reverification_required BOOLEAN NOT NULL DEFAULT FALSE;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMP WITH TIME ZONE;
+		ALTER TABLE users ALTER COLUMN verification_code TYPE VARCHAR(64);
+	`
+
+	// Entries are never updated or deleted; each links to the previous hash of its tenant.
+	// No foreign keys, so entries outlive the users and tenants they mention.
+	createAuditLog := `
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id BIGSERIAL PRIMARY KEY,
+			tenant_id UUID NOT NULL,
+			actor_id UUID,
+			actor_email VARCHAR(255) NOT NULL DEFAULT '',
+			actor_role VARCHAR(50) NOT NULL DEFAULT '',
+			action VARCHAR(100) NOT NULL,
+			entity_type VARCHAR(50) NOT NULL DEFAULT '',
+			entity_id VARCHAR(100) NOT NULL DEFAULT '',
+			method VARCHAR(10) NOT NULL,
+			path TEXT NOT NULL,
+			status INTEGER NOT NULL,
+			changes JSONB NOT NULL DEFAULT '{}',
+			ip_address VARCHAR(64) NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+			hash VARCHAR(64) NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_tenant ON audit_log(tenant_id, id DESC);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(tenant_id, entity_type, entity_id);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(tenant_id, actor_id);
+
+		CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_log is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+		CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+			FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+		UPDATE roles SET permissions = array_append(permissions, 'audit.read'), updated_at = NOW()
+		WHERE name = 'admin' AND built_in AND NOT ('audit.read' = ANY(permissions));
+	`
+
+	// A session is a sign in; its ID is the family_id of its refresh tokens and the sid
+	// claim of its access tokens
+	createSessions := `
+		CREATE TABLE IF NOT EXISTS sessions (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			device VARCHAR(100) NOT NULL DEFAULT '',
+			ip_address VARCHAR(64) NOT NULL DEFAULT '',
+			user_agent TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			revoked_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+	`
+
+	// Service accounts let integrations call the API with keys limited to read scopes.
+	// Keys are stored as a SHA-256 hash and looked up by their public prefix.
+	createServiceAccounts := `
+		CREATE TABLE IF NOT EXISTS service_accounts (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			scopes TEXT[] NOT NULL DEFAULT '{}',
+			created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			disabled_at TIMESTAMP WITH TIME ZONE,
+			UNIQUE (tenant_id, name)
+		);
+
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			service_account_id UUID NOT NULL REFERENCES service_accounts(id) ON DELETE CASCADE,
+			prefix VARCHAR(16) NOT NULL UNIQUE,
+			key_hash VARCHAR(64) NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE,
+			rotated_from UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+			last_used_at TIMESTAMP WITH TIME ZONE,
+			last_used_ip VARCHAR(64) NOT NULL DEFAULT '',
+			created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			revoked_at TIMESTAMP WITH TIME ZONE
+		);
+		CREATE INDEX IF NOT EXISTS idx_api_keys_service_account ON api_keys(service_account_id);
+
+		UPDATE roles SET permissions = array_append(permissions, 'service_account.manage'), updated_at = NOW()
+		WHERE name = 'admin' AND built_in AND NOT ('service_account.manage' = ANY(permissions));
+	`
+
+	// Single sign-on: an authorization request waits in oidc_login_states, keyed by the hash
+	// of its state, until the provider sends the user back; user_identities links provider
+	// accounts to users, marking the ones it created
+	createSingleSignOn := `
+		CREATE TABLE IF NOT EXISTS oidc_login_states (
+			state_hash VARCHAR(64) PRIMARY KEY,
+			tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+			nonce VARCHAR(64) NOT NULL,
+			code_verifier VARCHAR(128) NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS user_identities (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			issuer TEXT NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			email VARCHAR(255) NOT NULL DEFAULT '',
+			provisioned BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			last_login_at TIMESTAMP WITH TIME ZONE,
+			UNIQUE (issuer, subject)
+		);
+		CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+	`
+
 	migrations := []string{
 		createUsersTable,
 		createPapersTable,
@@ -376,6 +938,33 @@ func RunMigrations(db *Database) error {
 		addReviewRatingColumns,
 		addMediaToNews,
 		addMediaToEvents,
+		createCallsTable,
+		addCallIdToPapers,
+		createJournalTables,
+		addIssueColumnsToPapers,
+		addPaperLifecycleColumns,
+		addPublicationSchedule,
+		addResearcherProfiles,
+		createOrgUnits,
+		createTenants,
+		addTenantColumns,
+		scopeUniqueKeysToTenant,
+		addReviewLifecycle,
+		addReviewConfidentiality,
+		addExpertiseAndKeywords,
+		createPaperDiscussions,
+		createReviewAssignments,
+		createPendingRegistrations,
+		createTokenTables,
+		createPasswordResets,
+		addTwoFactorAuth,
+		createLoginThrottling,
+		createRoles,
+		addAccountStatus,
+		createAuditLog,
+		createSessions,
+		createServiceAccounts,
+		createSingleSignOn,
 	}
 
 	for _, migration := range migrations {
@@ -403,3 +992,18 @@ func (db *Database) Query(ctx context.Context, sql string, args ...interface{})
 func (db *Database) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
 	return db.Pool.Exec(ctx, sql, args...)
 }
+
+// LockReviews freezes the submitted reviews of a paper once a final decision has been made
+// and withdraws the invitations nobody has acted on
+func (db *Database) LockReviews(ctx context.Context, paperID uuid.UUID) error {
+	_, err := db.Pool.Exec(ctx,
+		"UPDATE reviews SET status = 'locked', locked_at = NOW(), updated_at = NOW() WHERE paper_id = $1 AND status = 'submitted'",
+		paperID)
+	if err != nil {
+		return err
+	}
+	_, err = db.Pool.Exec(ctx,
+		"UPDATE review_assignments SET status = 'cancelled' WHERE paper_id = $1 AND status IN ('invited', 'accepted')",
+		paperID)
+	return err
+}
@@ -3,6 +3,7 @@ package email
 import (
 	"fmt"
 	"net/smtp"
+	"net/url"
 	"rpms-backend/internal/config"
 )
 
@@ -21,14 +22,7 @@ func (s *EmailSender) SendVerificationEmail(toEmail, code string) error {
 		return nil
 	}
 
-	from := s.config.SMTP.Email
-	password := s.config.SMTP.Password
-	host := s.config.SMTP.Host
-	port := s.config.SMTP.Port
-	address := host + ":" + port
-
-	subject := "Subject: Verify your RPMS Account\n"
-	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	subject := "Verify your RPMS Account"
 	body := fmt.Sprintf(`
 		<html>
 			<body>
@@ -40,7 +34,48 @@ func (s *EmailSender) SendVerificationEmail(toEmail, code string) error {
 		</html>
 	`, code)
 
-	message := []byte(subject + mime + body)
+	return s.send(toEmail, subject, body)
+}
+
+// SendPasswordResetEmail sends a password reset token, as a link when a reset page is configured
+func (s *EmailSender) SendPasswordResetEmail(toEmail, token string) error {
+	// The token grants access to the account, so it is never written to the log
+	if s.config.SMTP.Email == "" || s.config.SMTP.Password == "" {
+		fmt.Printf("SMTP credentials not set. Password reset email to %s suppressed\n", toEmail)
+		return nil
+	}
+
+	action := fmt.Sprintf("<p>Use the following token to choose a new password:</p>\n\t\t\t\t<h3>%s</h3>", token)
+	if base := s.config.Auth.PasswordResetURL; base != "" {
+		link := base + "?token=" + url.QueryEscape(token)
+		action = fmt.Sprintf(`<p><a href="%s">Choose a new password</a></p>`, link)
+	}
+
+	subject := "Reset your RPMS password"
+	body := fmt.Sprintf(`
+		<html>
+			<body>
+				<h2>Password reset</h2>
+				%s
+				<p>It can be used once and expires in one hour.</p>
+				<p>If you did not request this, please ignore this email; your password is unchanged.</p>
+			</body>
+		</html>
+	`, action)
+
+	return s.send(toEmail, subject, body)
+}
+
+func (s *EmailSender) send(toEmail, subject, body string) error {
+	from := s.config.SMTP.Email
+	password := s.config.SMTP.Password
+	host := s.config.SMTP.Host
+	port := s.config.SMTP.Port
+	address := host + ":" + port
+
+	header := "Subject: " + subject + "\n"
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	message := []byte(header + mime + body)
 
 	auth := smtp.PlainAuth("", from, password, host)
 
@@ -0,0 +1,319 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"rpms-backend/internal/models"
+)
+
+const (
+	KindPapers   = "papers"
+	KindProjects = "projects"
+)
+
+// Row is a single parsed CSV record mapped onto a paper
+type Row struct {
+	Line        int          `json:"line"`
+	Paper       models.Paper `json:"-"`
+	AuthorEmail string       `json:"author_email"`
+	AuthorName  string       `json:"author_name"`
+	Errors      []string     `json:"errors,omitempty"`
+}
+
+func (r *Row) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+type setter func(p *models.Paper, value string) error
+
+// fields maps canonical column names onto paper fields
+var fields = map[string]setter{
+	"title":                      func(p *models.Paper, v string) error { p.Title = v; return nil },
+	"abstract":                   func(p *models.Paper, v string) error { p.Abstract = v; return nil },
+	"file_url":                   func(p *models.Paper, v string) error { p.FileUrl = v; return nil },
+	"status":                     setStatus,
+	"institution_code":           func(p *models.Paper, v string) error { p.InstitutionCode = v; return nil },
+	"publication_id":             func(p *models.Paper, v string) error { p.PublicationID = v; return nil },
+	"publication_isced_band":     func(p *models.Paper, v string) error { p.PublicationISCEDBand = v; return nil },
+	"publication_title_amharic":  func(p *models.Paper, v string) error { p.PublicationTitleAmharic = v; return nil },
+	"publication_date":           setPublicationDate,
+	"publication_type":           func(p *models.Paper, v string) error { p.PublicationType = v; return nil },
+	"journal_type":               func(p *models.Paper, v string) error { p.JournalType = v; return nil },
+	"journal_name":               func(p *models.Paper, v string) error { p.JournalName = v; return nil },
+	"indigenous_knowledge":       boolField(func(p *models.Paper) *bool { return &p.IndigenousKnowledge }),
+	"fiscal_year":                func(p *models.Paper, v string) error { p.FiscalYear = v; return nil },
+	"allocated_budget":           floatField(func(p *models.Paper) *float64 { return &p.AllocatedBudget }),
+	"external_budget":            floatField(func(p *models.Paper) *float64 { return &p.ExternalBudget }),
+	"nrf_fund":                   floatField(func(p *models.Paper) *float64 { return &p.NRFFund }),
+	"research_type":              func(p *models.Paper, v string) error { p.ResearchType = v; return nil },
+	"completion_status":          func(p *models.Paper, v string) error { p.CompletionStatus = v; return nil },
+	"female_researchers":         intField(func(p *models.Paper) *int { return &p.FemaleResearchers }),
+	"male_researchers":           intField(func(p *models.Paper) *int { return &p.MaleResearchers }),
+	"outside_female_researchers": intField(func(p *models.Paper) *int { return &p.OutsideFemaleResearchers }),
+	"outside_male_researchers":   intField(func(p *models.Paper) *int { return &p.OutsideMaleResearchers }),
+	"benefited_industry":         func(p *models.Paper, v string) error { p.BenefitedIndustry = v; return nil },
+	"ethical_clearance":          func(p *models.Paper, v string) error { p.EthicalClearance = v; return nil },
+	"pi_name":                    func(p *models.Paper, v string) error { p.PIName = v; return nil },
+	"pi_gender":                  func(p *models.Paper, v string) error { p.PIGender = v; return nil },
+	"co_investigators":           func(p *models.Paper, v string) error { p.CoInvestigators = v; return nil },
+	"produced_prototype":         func(p *models.Paper, v string) error { p.ProducedPrototype = v; return nil },
+	"hetril_collaboration":       func(p *models.Paper, v string) error { p.HetrilCollaboration = v; return nil },
+	"submitted_to_incubator":     func(p *models.Paper, v string) error { p.SubmittedToIncubator = v; return nil },
+}
+
+// aliases maps normalized ministry reporting headers onto canonical column names
+var aliases = map[string]string{
+	"publication_title":           "title",
+	"research_title":              "title",
+	"project_title":               "title",
+	"isced_band":                  "publication_isced_band",
+	"publication_isced":           "publication_isced_band",
+	"title_in_amharic":            "publication_title_amharic",
+	"amharic_title":               "publication_title_amharic",
+	"date_of_publication":         "publication_date",
+	"published_date":              "publication_date",
+	"name_of_journal":             "journal_name",
+	"indigenous":                  "indigenous_knowledge",
+	"budget":                      "allocated_budget",
+	"university_budget":           "allocated_budget",
+	"nrf":                         "nrf_fund",
+	"principal_investigator":      "pi_name",
+	"principal_investigator_name": "pi_name",
+	"co_investigator":             "co_investigators",
+	"prototype":                   "produced_prototype",
+	"hetril":                      "hetril_collaboration",
+	"incubator":                   "submitted_to_incubator",
+	"email":                       "author_email",
+	"author":                      "author_name",
+	"pi_email":                    "author_email",
+	"manuscript_url":              "file_url",
+}
+
+var dateLayouts = []string{"2006-01-02", "02/01/2006", "2006/01/02", "2006-01", "2006"}
+
+// normalizeHeader turns "Publication Title (Amharic)" into "publication_title_amharic"
+func normalizeHeader(h string) string {
+	h = strings.TrimPrefix(h, "\ufeff") // UTF-8 byte order mark written by spreadsheet exports
+	h = strings.ToLower(strings.TrimSpace(h))
+	var sb strings.Builder
+	lastUnderscore := false
+	for _, r := range h {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			lastUnderscore = false
+		} else if !lastUnderscore && sb.Len() > 0 {
+			sb.WriteRune('_')
+			lastUnderscore = true
+		}
+	}
+	name := strings.TrimSuffix(sb.String(), "_")
+	if canonical, ok := aliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// FileError is a problem with the uploaded file as a whole, such as a missing column, as
+// opposed to a failure to store the import
+type FileError struct {
+	Message string
+}
+
+func (e *FileError) Error() string {
+	return e.Message
+}
+
+// Parse reads a CSV export and maps every record onto a paper of the given kind.
+// Per-row problems are recorded on the row rather than aborting the parse; problems with the
+// file itself are returned as a *FileError.
+func Parse(r io.Reader, kind string) ([]Row, error) {
+	if kind != KindPapers && kind != KindProjects {
+		return nil, &FileError{fmt.Sprintf("unknown import kind %q", kind)}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, &FileError{fmt.Sprintf("failed to read header: %v", err)}
+	}
+
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, h := range header {
+		columns[i] = normalizeHeader(h)
+		seen[columns[i]] = true
+	}
+	if !seen["title"] {
+		return nil, &FileError{"missing required column: title"}
+	}
+	if !seen["author_email"] {
+		return nil, &FileError{"missing required column: author_email"}
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line := 0
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				line = parseErr.StartLine
+			}
+			rows = append(rows, Row{Line: line, Errors: []string{err.Error()}})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+		rows = append(rows, parseRecord(line, kind, columns, record))
+	}
+
+	markDuplicates(rows)
+	return rows, nil
+}
+
+func parseRecord(line int, kind string, columns, record []string) Row {
+	row := Row{Line: line}
+	row.Paper.Status = "published"
+	row.Paper.Type = "Research Paper"
+	if kind == KindProjects {
+		row.Paper.Status = "approved"
+		row.Paper.Type = "Research Project"
+	}
+
+	for i, value := range record {
+		if i >= len(columns) {
+			break
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		switch columns[i] {
+		case "author_email":
+			row.AuthorEmail = strings.ToLower(value)
+		case "author_name":
+			row.AuthorName = value
+		default:
+			set, ok := fields[columns[i]]
+			if !ok {
+				continue // Unknown columns are ignored
+			}
+			if err := set(&row.Paper, value); err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s: %v", columns[i], err))
+			}
+		}
+	}
+
+	if row.Paper.Title == "" {
+		row.Errors = append(row.Errors, "title is required")
+	} else if len(row.Paper.Title) > 500 {
+		row.Errors = append(row.Errors, "title must be at most 500 characters")
+	}
+
+	if row.AuthorEmail == "" {
+		row.Errors = append(row.Errors, "author_email is required")
+	} else if _, err := mail.ParseAddress(row.AuthorEmail); err != nil {
+		row.Errors = append(row.Errors, fmt.Sprintf("author_email: invalid address %q", row.AuthorEmail))
+	}
+
+	if kind == KindProjects && row.Paper.FiscalYear == "" {
+		row.Errors = append(row.Errors, "fiscal_year is required for research projects")
+	}
+
+	return row
+}
+
+// markDuplicates flags rows repeating a publication ID already used earlier in the file
+func markDuplicates(rows []Row) {
+	firstLine := map[string]int{}
+	for i := range rows {
+		id := rows[i].Paper.PublicationID
+		if id == "" {
+			continue
+		}
+		if line, ok := firstLine[id]; ok {
+			rows[i].Errors = append(rows[i].Errors, fmt.Sprintf("publication_id %s duplicates line %d", id, line))
+			continue
+		}
+		firstLine[id] = rows[i].Line
+	}
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func setStatus(p *models.Paper, v string) error {
+	status := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(v), " ", "_"))
+	switch status {
+	case "draft", "submitted", "under_review", "approved", "rejected", "published", "recommended_for_publication", "withdrawn", "retracted":
+		p.Status = status
+		return nil
+	}
+	return fmt.Errorf("unknown status %q", v)
+}
+
+func setPublicationDate(p *models.Paper, v string) error {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			p.PublicationDate = &t
+			return nil
+		}
+	}
+	return fmt.Errorf("unrecognised date %q", v)
+}
+
+func boolField(target func(*models.Paper) *bool) setter {
+	return func(p *models.Paper, v string) error {
+		switch strings.ToLower(v) {
+		case "yes", "y", "true", "1":
+			*target(p) = true
+		case "no", "n", "false", "0":
+			*target(p) = false
+		default:
+			return fmt.Errorf("expected yes/no, got %q", v)
+		}
+		return nil
+	}
+}
+
+func floatField(target func(*models.Paper) *float64) setter {
+	return func(p *models.Paper, v string) error {
+		f, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+		if err != nil || f < 0 {
+			return fmt.Errorf("expected a non-negative amount, got %q", v)
+		}
+		*target(p) = f
+		return nil
+	}
+}
+
+func intField(target func(*models.Paper) *int) setter {
+	return func(p *models.Paper, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("expected a non-negative whole number, got %q", v)
+		}
+		*target(p) = n
+		return nil
+	}
+}
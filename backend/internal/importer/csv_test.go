@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePapers(t *testing.T) {
+	input := "\ufeffPublication Title,Author Email,Author Name,Publication ID,ISCED Band,Date of Publication,Indigenous Knowledge\n" +
+		"Soil salinity in the Rift Valley,Alice@Example.com,Alice,SMU_P1,Band 1,2019-05-01,yes\n" +
+		",bob@example.com,Bob,SMU_P2,Band 2,2020,no\n" +
+		"Duplicate ID,carol@example.com,Carol,SMU_P1,Band 1,not-a-date,maybe\n" +
+		",,,,,,\n"
+
+	rows, err := Parse(strings.NewReader(input), KindPapers)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows (blank row skipped), got %d", len(rows))
+	}
+
+	first := rows[0]
+	if !first.Valid() {
+		t.Fatalf("expected first row to be valid, got errors %v", first.Errors)
+	}
+	if first.Line != 2 {
+		t.Errorf("expected line 2, got %d", first.Line)
+	}
+	if first.AuthorEmail != "alice@example.com" {
+		t.Errorf("expected lower-cased email, got %q", first.AuthorEmail)
+	}
+	if first.Paper.PublicationISCEDBand != "Band 1" || !first.Paper.IndigenousKnowledge {
+		t.Errorf("columns not mapped: %+v", first.Paper)
+	}
+	if first.Paper.PublicationDate == nil || first.Paper.PublicationDate.Year() != 2019 {
+		t.Errorf("publication date not parsed: %v", first.Paper.PublicationDate)
+	}
+	if first.Paper.Status != "published" || first.Paper.Type != "Research Paper" {
+		t.Errorf("unexpected defaults: status=%q type=%q", first.Paper.Status, first.Paper.Type)
+	}
+
+	if rows[1].Valid() || !strings.Contains(strings.Join(rows[1].Errors, ";"), "title is required") {
+		t.Errorf("expected missing title error, got %v", rows[1].Errors)
+	}
+
+	errs := strings.Join(rows[2].Errors, ";")
+	for _, want := range []string{"publication_date", "indigenous_knowledge", "duplicates line 2"} {
+		if !strings.Contains(errs, want) {
+			t.Errorf("expected error mentioning %q, got %v", want, rows[2].Errors)
+		}
+	}
+}
+
+func TestParseProjects(t *testing.T) {
+	input := "Research Title,PI Email,Fiscal Year,Allocated Budget,Female Researchers\n" +
+		"Drought resistant teff,pi@example.com,2015,\"150,000\",3\n" +
+		"No year,pi@example.com,,10,-1\n"
+
+	rows, err := Parse(strings.NewReader(input), KindProjects)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if !rows[0].Valid() {
+		t.Fatalf("expected first row to be valid, got %v", rows[0].Errors)
+	}
+	if rows[0].Paper.AllocatedBudget != 150000 || rows[0].Paper.FemaleResearchers != 3 {
+		t.Errorf("numeric columns not mapped: %+v", rows[0].Paper)
+	}
+	if rows[0].Paper.Type != "Research Project" || rows[0].Paper.Status != "approved" {
+		t.Errorf("unexpected project defaults: status=%q type=%q", rows[0].Paper.Status, rows[0].Paper.Type)
+	}
+
+	errs := strings.Join(rows[1].Errors, ";")
+	if !strings.Contains(errs, "fiscal_year") || !strings.Contains(errs, "female_researchers") {
+		t.Errorf("expected fiscal year and count errors, got %v", rows[1].Errors)
+	}
+}
+
+func TestParseRequiresColumns(t *testing.T) {
+	var fileErr *FileError
+	if _, err := Parse(strings.NewReader("Title,Abstract\nx,y\n"), KindPapers); !errors.As(err, &fileErr) {
+		t.Error("expected error when author email column is missing")
+	}
+	if _, err := Parse(strings.NewReader("Title,Author Email\n"), "theses"); !errors.As(err, &fileErr) {
+		t.Error("expected error for unknown kind")
+	}
+}
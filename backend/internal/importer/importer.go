@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"rpms-backend/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RowResult describes what happened (or would happen) to a single CSV row
+type RowResult struct {
+	Line         int        `json:"line"`
+	Title        string     `json:"title"`
+	AuthorEmail  string     `json:"author_email"`
+	AuthorAction string     `json:"author_action,omitempty"` // "matched" or "created"
+	PaperID      *uuid.UUID `json:"paper_id,omitempty"`
+	Errors       []string   `json:"errors,omitempty"`
+}
+
+type Report struct {
+	Kind           string      `json:"kind"`
+	DryRun         bool        `json:"dry_run"`
+	TotalRows      int         `json:"total_rows"`
+	ValidRows      int         `json:"valid_rows"`
+	InvalidRows    int         `json:"invalid_rows"`
+	AuthorsCreated int         `json:"authors_created"`
+	Imported       int         `json:"imported"`
+	Rows           []RowResult `json:"rows"`
+}
+
+// Importer imports rows into a single tenant; authors are matched and created within it
+type Importer struct {
+	db       *database.Database
+	tenantID uuid.UUID
+}
+
+func New(db *database.Database, tenantID uuid.UUID) *Importer {
+	return &Importer{db: db, tenantID: tenantID}
+}
+
+// Import parses a CSV export and, unless dryRun is set, commits every valid row in a single transaction.
+// Invalid rows are reported and skipped; a dry run reports the same outcome without writing anything.
+func (im *Importer) Import(ctx context.Context, r io.Reader, kind string, dryRun bool) (*Report, error) {
+	rows, err := Parse(r, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := im.db.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	report := &Report{Kind: kind, DryRun: dryRun, TotalRows: len(rows)}
+	createdAuthors := map[string]uuid.UUID{}
+
+	for i := range rows {
+		row := &rows[i]
+		result := RowResult{Line: row.Line, Title: row.Paper.Title, AuthorEmail: row.AuthorEmail}
+
+		if row.Valid() && row.Paper.PublicationID != "" {
+			var exists bool
+			err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM papers WHERE publication_id = $1 AND tenant_id = $2)", row.Paper.PublicationID, im.tenantID).Scan(&exists)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", row.Line, err)
+			}
+			if exists {
+				row.Errors = append(row.Errors, fmt.Sprintf("publication_id %s already exists", row.Paper.PublicationID))
+			}
+		}
+
+		if row.Valid() {
+			authorID, action, err := im.resolveAuthor(ctx, tx, row, createdAuthors, dryRun)
+			if rowErr, ok := err.(rowError); ok {
+				row.Errors = append(row.Errors, string(rowErr))
+			} else if err != nil {
+				return nil, fmt.Errorf("line %d: %w", row.Line, err)
+			} else {
+				result.AuthorAction = action
+				row.Paper.AuthorID = authorID
+			}
+		}
+
+		if !row.Valid() {
+			result.Errors = row.Errors
+			report.InvalidRows++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+		report.ValidRows++
+
+		if !dryRun {
+			paperID, err := insertPaper(ctx, tx, row)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: failed to insert paper: %w", row.Line, err)
+			}
+			result.PaperID = &paperID
+			report.Imported++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	report.AuthorsCreated = len(createdAuthors)
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	return report, nil
+}
+
+// rowError is a problem with a single row that should be reported rather than abort the import
+type rowError string
+
+func (e rowError) Error() string {
+	return string(e)
+}
+
+// resolveAuthor matches the row's author by email, creating an unverified author account when none exists
+func (im *Importer) resolveAuthor(ctx context.Context, tx pgx.Tx, row *Row, created map[string]uuid.UUID, dryRun bool) (uuid.UUID, string, error) {
+	if id, ok := created[row.AuthorEmail]; ok {
+		return id, "created", nil
+	}
+
+	var id, tenantID uuid.UUID
+	err := tx.QueryRow(ctx, "SELECT id, tenant_id FROM users WHERE LOWER(email) = $1", row.AuthorEmail).Scan(&id, &tenantID)
+	if err == nil {
+		if tenantID != im.tenantID {
+			return uuid.Nil, "", rowError(fmt.Sprintf("author %s belongs to another institution", row.AuthorEmail))
+		}
+		return id, "matched", nil
+	}
+	if err != pgx.ErrNoRows {
+		return uuid.Nil, "", err
+	}
+
+	if row.AuthorName == "" {
+		return uuid.Nil, "", rowError(fmt.Sprintf("author %s does not exist and author_name is empty", row.AuthorEmail))
+	}
+
+	if dryRun {
+		id = uuid.New()
+	} else {
+		err = tx.QueryRow(ctx, `
+			INSERT INTO users (email, password_hash, name, role, is_verified, preferences, tenant_id)
+			VALUES ($1, '', $2, 'author', FALSE, '{}', $3)
+			RETURNING id
+		`, row.AuthorEmail, row.AuthorName, im.tenantID).Scan(&id)
+		if err != nil {
+			return uuid.Nil, "", fmt.Errorf("failed to create author %s: %w", row.AuthorEmail, err)
+		}
+	}
+
+	created[row.AuthorEmail] = id
+	return id, "created", nil
+}
+
+func insertPaper(ctx context.Context, tx pgx.Tx, row *Row) (uuid.UUID, error) {
+	p := &row.Paper
+	query := `
+		INSERT INTO papers (
+			title, abstract, file_url, author_id, status, type,
+			institution_code, publication_id, publication_isced_band, publication_title_amharic,
+			publication_date, publication_type, journal_type, journal_name, indigenous_knowledge,
+			fiscal_year, allocated_budget, external_budget, nrf_fund, research_type, completion_status,
+			female_researchers, male_researchers, outside_female_researchers, outside_male_researchers,
+			benefited_industry, ethical_clearance, pi_name, pi_gender, co_investigators,
+			produced_prototype, hetril_collaboration, submitted_to_incubator
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+				$21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33)
+		RETURNING id
+	`
+
+	var id uuid.UUID
+	err := tx.QueryRow(ctx, query,
+		p.Title, p.Abstract, p.FileUrl, p.AuthorID, p.Status, p.Type,
+		p.InstitutionCode, p.PublicationID, p.PublicationISCEDBand, p.PublicationTitleAmharic,
+		p.PublicationDate, p.PublicationType, p.JournalType, p.JournalName, p.IndigenousKnowledge,
+		p.FiscalYear, p.AllocatedBudget, p.ExternalBudget, p.NRFFund, p.ResearchType, p.CompletionStatus,
+		p.FemaleResearchers, p.MaleResearchers, p.OutsideFemaleResearchers, p.OutsideMaleResearchers,
+		p.BenefitedIndustry, p.EthicalClearance, p.PIName, p.PIGender, p.CoInvestigators,
+		p.ProducedPrototype, p.HetrilCollaboration, p.SubmittedToIncubator,
+	).Scan(&id)
+	return id, err
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/config"
+	"rpms-backend/internal/rbac"
+	"rpms-backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type fakeAPIKeys map[string]*auth.APIPrincipal
+
+func (f fakeAPIKeys) AuthenticateAPIKey(_ context.Context, key, _ string) (*auth.APIPrincipal, error) {
+	if p, ok := f[key]; ok {
+		return p, nil
+	}
+	return nil, auth.ErrInvalidAPIKey
+}
+
+func setupAPIKeyRouter(t *testing.T) (*gin.Engine, fakeResolver) {
+	gin.SetMode(gin.TestMode)
+	resolver := fakeResolver{
+		"smu": {ID: tenant.DefaultID, Slug: "smu", Hostnames: []string{"rpms.smu.edu"}},
+		"aau": {ID: uuid.New(), Slug: "aau", Hostnames: []string{"rpms.aau.edu"}},
+	}
+	jwtManager := auth.NewJWTManager(&config.Config{JWT: config.JWTConfig{Secret: "test", Expiry: "1h"}})
+	keys := fakeAPIKeys{
+		"smu-reports": {ServiceAccountID: uuid.New(), Name: "BI export", Scopes: []string{"read:reports"}, Tenant: resolver["smu"]},
+		"aau-papers":  {ServiceAccountID: uuid.New(), Name: "Repository sync", Scopes: []string{"read:papers"}, Tenant: resolver["aau"]},
+	}
+	permissions := &staticResolver{}
+
+	router := gin.New()
+	router.Use(TenantMiddleware(resolver, jwtManager), AuthMiddleware(jwtManager, nil, keys))
+	report := func(c *gin.Context) { c.String(http.StatusOK, c.GetString("tenant_id")+" "+c.GetString("role")) }
+	router.GET("/api/v1/admin/stats", RequirePermission(permissions, rbac.ReportView), report)
+	router.GET("/api/v1/admin/users", RequirePermission(permissions, rbac.UserManage), report)
+	router.GET("/api/v1/papers", RequirePermission(permissions, rbac.PaperReadAll), report)
+	router.POST("/api/v1/papers", report)
+	return router, resolver
+}
+
+func apiKeyRequest(router *gin.Engine, method, path, host, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = host
+	req.Header.Set(auth.APIKeyHeader, key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKeyWithinScope(t *testing.T) {
+	router, _ := setupAPIKeyRouter(t)
+	rec := apiKeyRequest(router, http.MethodGet, "/api/v1/admin/stats", "rpms.smu.edu", "smu-reports")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if want := tenant.DefaultID.String() + " " + ServiceAccountRole; rec.Body.String() != want {
+		t.Errorf("body %q, want %q", rec.Body, want)
+	}
+}
+
+func TestAPIKeyOutsideScope(t *testing.T) {
+	router, _ := setupAPIKeyRouter(t)
+	cases := []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/admin/users"},
+		{http.MethodGet, "/api/v1/papers"},
+		{http.MethodPost, "/api/v1/papers"},
+	}
+	for _, tc := range cases {
+		if rec := apiKeyRequest(router, tc.method, tc.path, "rpms.smu.edu", "smu-reports"); rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s: status %d, want 403", tc.method, tc.path, rec.Code)
+		}
+	}
+}
+
+func TestAPIKeyInvalid(t *testing.T) {
+	router, _ := setupAPIKeyRouter(t)
+	if rec := apiKeyRequest(router, http.MethodGet, "/api/v1/admin/stats", "rpms.smu.edu", "rpms_000000000000_guess"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status %d, want 401", rec.Code)
+	}
+}
+
+func TestAPIKeyTenant(t *testing.T) {
+	router, resolver := setupAPIKeyRouter(t)
+
+	// Another tenant's hostname refuses the key
+	if rec := apiKeyRequest(router, http.MethodGet, "/api/v1/papers", "rpms.smu.edu", "aau-papers"); rec.Code != http.StatusForbidden {
+		t.Errorf("foreign host: status %d, want 403", rec.Code)
+	}
+
+	// An unmapped hostname acts in the key's tenant
+	rec := apiKeyRequest(router, http.MethodGet, "/api/v1/papers", "api.example.org", "aau-papers")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unmapped host: status %d: %s", rec.Code, rec.Body)
+	}
+	if want := resolver["aau"].ID.String() + " " + ServiceAccountRole; rec.Body.String() != want {
+		t.Errorf("unmapped host: body %q, want %q", rec.Body, want)
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rpms-backend/internal/audit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AuditLog is where the audit middleware records requests
+type AuditLog interface {
+	Snapshot(ctx context.Context, entityType, id string) (map[string]interface{}, error)
+	Append(ctx context.Context, e *audit.Entry) error
+}
+
+// maxCapturedBody bounds how much of a creation response is kept to find the new entity's ID
+const maxCapturedBody = 64 << 10
+
+// bodyCapture keeps the start of the response body while writing it through
+type bodyCapture struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyCapture) Write(b []byte) (int, error) {
+	if room := maxCapturedBody - w.body.Len(); room > 0 {
+		if len(b) < room {
+			room = len(b)
+		}
+		w.body.Write(b[:room])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Audit records every mutating request, whether it succeeded or not, with the fields of the
+// affected entity that it changed. It has to run after the tenant is resolved; the actor is
+// read once the request is handled, so it sees what the authentication middleware set.
+func Audit(log AuditLog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if route == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		action, entityType, idParam := audit.Describe(c.Request.Method, route)
+		entityID := ""
+		if idParam != "" {
+			entityID = c.Param(idParam)
+		}
+
+		before, err := log.Snapshot(ctx, entityType, entityID)
+		if err != nil {
+			fmt.Printf("Failed to snapshot %s %s for the audit log: %v\n", entityType, entityID, err)
+		}
+
+		var capture *bodyCapture
+		if entityID == "" {
+			capture = &bodyCapture{ResponseWriter: c.Writer}
+			c.Writer = capture
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if capture != nil {
+			var created struct {
+				ID interface{} `json:"id"`
+			}
+			if json.Unmarshal(capture.body.Bytes(), &created) == nil && created.ID != nil {
+				entityID = fmt.Sprint(created.ID)
+			}
+		}
+
+		var changes map[string]audit.Change
+		if status < http.StatusBadRequest {
+			after, err := log.Snapshot(ctx, entityType, entityID)
+			if err != nil {
+				fmt.Printf("Failed to snapshot %s %s for the audit log: %v\n", entityType, entityID, err)
+			}
+			changes = audit.Diff(before, after)
+		}
+
+		entry := &audit.Entry{
+			ActorEmail: c.GetString("email"),
+			ActorRole:  c.GetString("role"),
+			Action:     action,
+			EntityType: entityType,
+			EntityID:   entityID,
+			Method:     c.Request.Method,
+			Path:       route,
+			Status:     status,
+			Changes:    changes,
+			IPAddress:  c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			CreatedAt:  time.Now(),
+		}
+		if id, err := uuid.Parse(c.GetString("tenant_id")); err == nil {
+			entry.TenantID = id
+		}
+		if id, err := uuid.Parse(c.GetString("user_id")); err == nil {
+			entry.ActorID = &id
+		}
+
+		// Sealing waits for the chain lock, so it happens off the request
+		go func() {
+			if err := log.Append(context.Background(), entry); err != nil {
+				fmt.Printf("Failed to write audit entry %s: %v\n", action, err)
+			}
+		}()
+	}
+}
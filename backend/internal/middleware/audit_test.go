@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"rpms-backend/internal/audit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// memoryAuditLog holds one row per entity and hands appended entries to a channel
+type memoryAuditLog struct {
+	rows    map[string]map[string]interface{}
+	entries chan *audit.Entry
+}
+
+func (l *memoryAuditLog) Snapshot(ctx context.Context, entityType, id string) (map[string]interface{}, error) {
+	if row, ok := l.rows[entityType+"/"+id]; ok {
+		copied := map[string]interface{}{}
+		for k, v := range row {
+			copied[k] = v
+		}
+		return copied, nil
+	}
+	return nil, nil
+}
+
+func (l *memoryAuditLog) Append(ctx context.Context, e *audit.Entry) error {
+	l.entries <- e
+	return nil
+}
+
+func (l *memoryAuditLog) next(t *testing.T) *audit.Entry {
+	t.Helper()
+	select {
+	case e := <-l.entries:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no audit entry was written")
+		return nil
+	}
+}
+
+func TestAuditRecordsMutations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := &memoryAuditLog{rows: map[string]map[string]interface{}{}, entries: make(chan *audit.Entry, 4)}
+	tenantID, actor := uuid.New(), uuid.New()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("tenant_id", tenantID.String()) }, Audit(log))
+	authed := func(c *gin.Context) { c.Set("user_id", actor.String()); c.Set("email", "editor@example.edu") }
+	router.GET("/papers/:id", authed, func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/papers", authed, func(c *gin.Context) {
+		log.rows["paper/p1"] = map[string]interface{}{"title": "Soil health", "status": "draft"}
+		c.JSON(http.StatusCreated, gin.H{"id": "p1", "title": "Soil health"})
+	})
+	router.PUT("/papers/:id", authed, func(c *gin.Context) {
+		log.rows["paper/"+c.Param("id")]["status"] = "submitted"
+		c.Status(http.StatusOK)
+	})
+	router.DELETE("/papers/:id", authed, func(c *gin.Context) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	})
+
+	serve := func(method, path string) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("User-Agent", "audit-test")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(http.MethodGet, "/papers/p1")
+	serve(http.MethodPost, "/papers")
+	created := log.next(t)
+	if created.Action != "paper.create" || created.EntityID != "p1" || created.Status != http.StatusCreated {
+		t.Errorf("creation entry = %s %s %d", created.Action, created.EntityID, created.Status)
+	}
+	if created.ActorID == nil || *created.ActorID != actor || created.TenantID != tenantID || created.UserAgent != "audit-test" {
+		t.Errorf("creation entry has actor %v, tenant %v, user agent %q", created.ActorID, created.TenantID, created.UserAgent)
+	}
+	if c := created.Changes["title"]; c.Before != nil || c.After != "Soil health" {
+		t.Errorf("creation change = %+v", c)
+	}
+
+	serve(http.MethodPut, "/papers/p1")
+	updated := log.next(t)
+	if len(updated.Changes) != 1 || updated.Changes["status"].Before != "draft" || updated.Changes["status"].After != "submitted" {
+		t.Errorf("update changes = %+v", updated.Changes)
+	}
+
+	// Refused requests are logged too, without changes
+	serve(http.MethodDelete, "/papers/p1")
+	refused := log.next(t)
+	if refused.Action != "paper.delete" || refused.Status != http.StatusForbidden || len(refused.Changes) != 0 {
+		t.Errorf("refused entry = %s %d %v", refused.Action, refused.Status, refused.Changes)
+	}
+
+	select {
+	case e := <-log.entries:
+		t.Errorf("unexpected entry %s", e.Action)
+	default:
+	}
+}
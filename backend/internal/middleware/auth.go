@@ -1,16 +1,27 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"rpms-backend/internal/auth"
+	"rpms-backend/internal/tenant"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// AuthMiddleware accepts valid bearer tokens that are not on the revocation list, if one is given.
+// A revocation list that also tracks sessions gets each use of a session reported. With an
+// API key authenticator, service accounts may instead send an X-API-Key header.
+func AuthMiddleware(jwtManager *auth.JWTManager, revocations auth.RevocationList, apiKeys auth.APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key := c.GetHeader(auth.APIKeyHeader); key != "" && apiKeys != nil {
+			authenticateAPIKey(c, apiKeys, key)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
@@ -33,16 +44,126 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		if claims.Scope != "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Two-factor authentication required"})
+			c.Abort()
+			return
+		}
+		if revocations != nil {
+			revoked, err := revocations.IsRevoked(c.Request.Context(), claims)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check token"})
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
+		if tracker, ok := revocations.(auth.SessionTracker); ok {
+			ip := c.ClientIP()
+			go func() {
+				if err := tracker.Touch(context.Background(), claims, ip); err != nil {
+					fmt.Printf("Failed to update session %s: %v\n", claims.SessionID, err)
+				}
+			}()
+		}
 
 		// Set user claims in context
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
 
 		c.Next()
 	}
 }
 
+// ServiceAccountRole is the role reported for requests made with an API key
+const ServiceAccountRole = "service_account"
+
+// authenticateAPIKey admits a service account to the routes its key's scopes allow, acting in
+// the key's tenant with the permissions of those scopes instead of any roles
+func authenticateAPIKey(c *gin.Context, apiKeys auth.APIKeyAuthenticator, key string) {
+	principal, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), key, c.ClientIP())
+	if err == auth.ErrInvalidAPIKey {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
+		c.Abort()
+		return
+	}
+	if !auth.ScopesAllow(principal.Scopes, c.Request.Method, c.FullPath()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key scopes do not allow this request", "scopes": principal.Scopes})
+		c.Abort()
+		return
+	}
+
+	if c.GetString("tenant_id") != principal.Tenant.ID.String() {
+		if c.GetBool("tenant_from_host") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key was issued for another tenant"})
+			c.Abort()
+			return
+		}
+		c.Set(tenant.ContextKey, principal.Tenant)
+		c.Set("tenant_id", principal.Tenant.ID.String())
+	}
+
+	c.Set("user_id", principal.ServiceAccountID.String())
+	c.Set("email", "")
+	c.Set("role", ServiceAccountRole)
+	c.Set("service_account_id", principal.ServiceAccountID.String())
+	c.Set("api_key_id", principal.KeyID.String())
+	c.Set(permissionsKey, auth.ScopePermissions(principal.Scopes))
+
+	c.Next()
+}
+
+// PreAuthMiddleware accepts only pre-auth tokens of the given scope, issued while a sign in
+// waits for its second factor
+func PreAuthMiddleware(jwtManager *auth.JWTManager, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Abort()
+			return
+		}
+
+		claims, err := jwtManager.ValidateToken(parts[1])
+		if err != nil || claims.Scope != scope {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign in token"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("token_scope", claims.Scope)
+
+		c.Next()
+	}
+}
+
+// MFAVerifyOnly admits sign ins waiting for their second factor code
+func MFAVerifyOnly(jwtManager *auth.JWTManager) gin.HandlerFunc {
+	return PreAuthMiddleware(jwtManager, auth.ScopeMFAVerify)
+}
+
+// MFAEnrollOnly admits sign ins that must enroll a second factor first
+func MFAEnrollOnly(jwtManager *auth.JWTManager) gin.HandlerFunc {
+	return PreAuthMiddleware(jwtManager, auth.ScopeMFAEnroll)
+}
+
+// RoleMiddleware checks the primary role only; routes are guarded with RequirePermission,
+// which also honours additional roles
 func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
@@ -77,23 +198,3 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
-
-func AdminOnly() gin.HandlerFunc {
-	return RoleMiddleware("admin")
-}
-
-func EditorOrAdmin() gin.HandlerFunc {
-	return RoleMiddleware("editor", "admin")
-}
-
-func AuthorOrAdmin() gin.HandlerFunc {
-	return RoleMiddleware("author", "admin")
-}
-
-func CoordinatorOrAdmin() gin.HandlerFunc {
-	return RoleMiddleware("coordinator", "admin")
-}
-
-func EditorOrCoordinatorOrAdmin() gin.HandlerFunc {
-	return RoleMiddleware("editor", "coordinator", "admin")
-}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// permissionsKey is the gin context key caching the caller's rbac.Set for the request
+const permissionsKey = "permissions"
+
+// Permissions returns the permissions of the authenticated caller, loading them once per request
+func Permissions(c *gin.Context, resolver rbac.Resolver) (rbac.Set, error) {
+	if cached, ok := c.Get(permissionsKey); ok {
+		if set, ok := cached.(rbac.Set); ok {
+			return set, nil
+		}
+	}
+	userID, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		return rbac.Set{}, nil
+	}
+	set, err := resolver.Permissions(c.Request.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	c.Set(permissionsKey, set)
+	return set, nil
+}
+
+// RequirePermission lets the request through only if one of the caller's roles grants permission
+func RequirePermission(resolver rbac.Resolver, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		set, err := Permissions(c, resolver)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			c.Abort()
+			return
+		}
+		if !set.Has(permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions", "required": permission})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rpms-backend/internal/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type staticResolver struct {
+	sets  map[uuid.UUID]rbac.Set
+	calls int
+}
+
+func (r *staticResolver) Permissions(ctx context.Context, userID uuid.UUID) (rbac.Set, error) {
+	r.calls++
+	return r.sets[userID], nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lecturer, author := uuid.New(), uuid.New()
+	resolver := &staticResolver{sets: map[uuid.UUID]rbac.Set{
+		lecturer: rbac.NewSet(rbac.DefaultRoles[rbac.RoleAuthor], rbac.DefaultRoles[rbac.RoleEditor]),
+		author:   rbac.NewSet(rbac.DefaultRoles[rbac.RoleAuthor]),
+	}}
+
+	router := gin.New()
+	router.POST("/papers/:id/recommend",
+		func(c *gin.Context) { c.Set("user_id", c.GetHeader("X-User")) },
+		RequirePermission(resolver, rbac.PaperSubmit),
+		RequirePermission(resolver, rbac.PaperRecommend),
+		func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	cases := map[string]int{
+		lecturer.String(): http.StatusNoContent,
+		author.String():   http.StatusForbidden,
+		"":                http.StatusForbidden,
+	}
+	for user, want := range cases {
+		resolver.calls = 0
+		req := httptest.NewRequest(http.MethodPost, "/papers/1/recommend", nil)
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("user %q: status %d, want %d", user, rec.Code, want)
+		}
+		if resolver.calls > 1 {
+			t.Errorf("user %q: permissions loaded %d times in one request", user, resolver.calls)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TenantMiddleware resolves the tenant of a request from its hostname, or from the
+// tenant claim of its bearer token when the hostname is not mapped to any tenant.
+// A token issued for one tenant is rejected on another tenant's hostname.
+func TenantMiddleware(resolver tenant.Resolver, jwtManager *auth.JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		current, err := resolver.ByHost(ctx, c.Request.Host)
+		fromHost := err == nil
+		if err != nil && err != tenant.ErrNotFound {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve tenant"})
+			c.Abort()
+			return
+		}
+
+		if claimed := tokenTenant(c, jwtManager); claimed != uuid.Nil && (current == nil || claimed != current.ID) {
+			if fromHost {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Token was issued for another tenant"})
+				c.Abort()
+				return
+			}
+			current, err = resolver.ByID(ctx, claimed)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unknown tenant"})
+				c.Abort()
+				return
+			}
+		}
+
+		if current == nil {
+			current, err = resolver.ByID(ctx, tenant.DefaultID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve tenant"})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(tenant.ContextKey, current)
+		c.Set("tenant_id", current.ID.String())
+		c.Set("tenant_from_host", fromHost)
+
+		c.Next()
+	}
+}
+
+// tokenTenant returns the tenant claim of a valid bearer token, if any. Tokens issued
+// before tenants existed carry no claim and belong to the default tenant; invalid
+// tokens are left for AuthMiddleware to reject on protected routes.
+func tokenTenant(c *gin.Context, jwtManager *auth.JWTManager) uuid.UUID {
+	parts := strings.Split(c.GetHeader("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return uuid.Nil
+	}
+	claims, err := jwtManager.ValidateToken(parts[1])
+	if err != nil {
+		return uuid.Nil
+	}
+	if claims.TenantID == "" {
+		return tenant.DefaultID
+	}
+	id, err := uuid.Parse(claims.TenantID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
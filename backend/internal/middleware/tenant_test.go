@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rpms-backend/internal/auth"
+	"rpms-backend/internal/config"
+	"rpms-backend/internal/models"
+	"rpms-backend/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type fakeResolver map[string]*tenant.Tenant
+
+func (f fakeResolver) ByHost(_ context.Context, host string) (*tenant.Tenant, error) {
+	for _, t := range f {
+		for _, h := range t.Hostnames {
+			if h == tenant.NormalizeHost(host) {
+				return t, nil
+			}
+		}
+	}
+	return nil, tenant.ErrNotFound
+}
+
+func (f fakeResolver) ByID(_ context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+	for _, t := range f {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, tenant.ErrNotFound
+}
+
+func setupTenantRouter(t *testing.T) (*gin.Engine, *auth.JWTManager, fakeResolver) {
+	gin.SetMode(gin.TestMode)
+	resolver := fakeResolver{
+		"smu": {ID: tenant.DefaultID, Slug: "smu", Hostnames: []string{"rpms.smu.edu"}},
+		"aau": {ID: uuid.New(), Slug: "aau", Hostnames: []string{"rpms.aau.edu"}},
+	}
+	jwtManager := auth.NewJWTManager(&config.Config{JWT: config.JWTConfig{Secret: "test", Expiry: "1h"}})
+
+	router := gin.New()
+	router.Use(TenantMiddleware(resolver, jwtManager))
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("tenant_id"))
+	})
+	return router, jwtManager, resolver
+}
+
+func tenantRequest(t *testing.T, router *gin.Engine, host, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = host
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func tokenFor(t *testing.T, jwtManager *auth.JWTManager, tenantID uuid.UUID) string {
+	token, err := jwtManager.GenerateToken(&models.User{ID: uuid.New(), Role: "author", TenantID: tenantID}, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestTenantMiddlewareResolvesHost(t *testing.T) {
+	router, _, resolver := setupTenantRouter(t)
+
+	w := tenantRequest(t, router, "RPMS.aau.edu:443", "")
+	if w.Code != http.StatusOK || w.Body.String() != resolver["aau"].ID.String() {
+		t.Fatalf("got %d %q, want aau tenant", w.Code, w.Body.String())
+	}
+}
+
+func TestTenantMiddlewareFallsBackToDefault(t *testing.T) {
+	router, _, _ := setupTenantRouter(t)
+
+	w := tenantRequest(t, router, "localhost:8080", "")
+	if w.Code != http.StatusOK || w.Body.String() != tenant.DefaultID.String() {
+		t.Fatalf("got %d %q, want default tenant", w.Code, w.Body.String())
+	}
+}
+
+func TestTenantMiddlewareUsesClaimOnUnknownHost(t *testing.T) {
+	router, jwtManager, resolver := setupTenantRouter(t)
+	aau := resolver["aau"].ID
+
+	w := tenantRequest(t, router, "localhost:8080", tokenFor(t, jwtManager, aau))
+	if w.Code != http.StatusOK || w.Body.String() != aau.String() {
+		t.Fatalf("got %d %q, want tenant from token", w.Code, w.Body.String())
+	}
+}
+
+func TestTenantMiddlewareRejectsTokenFromOtherTenant(t *testing.T) {
+	router, jwtManager, resolver := setupTenantRouter(t)
+
+	w := tenantRequest(t, router, "rpms.smu.edu", tokenFor(t, jwtManager, resolver["aau"].ID))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("got %d, want 403 for a token issued by another tenant", w.Code)
+	}
+
+	w = tenantRequest(t, router, "rpms.smu.edu", tokenFor(t, jwtManager, tenant.DefaultID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 for a token of the host's tenant", w.Code)
+	}
+}
+
+func TestTenantMiddlewareRejectsUnknownClaim(t *testing.T) {
+	router, jwtManager, _ := setupTenantRouter(t)
+
+	w := tenantRequest(t, router, "localhost", tokenFor(t, jwtManager, uuid.New()))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("got %d, want 401 for a token of an unknown tenant", w.Code)
+	}
+}
+
+func TestTenantMiddlewareTreatsLegacyTokenAsDefault(t *testing.T) {
+	router, jwtManager, _ := setupTenantRouter(t)
+	legacy := tokenFor(t, jwtManager, uuid.Nil)
+
+	if w := tenantRequest(t, router, "rpms.aau.edu", legacy); w.Code != http.StatusForbidden {
+		t.Fatalf("got %d, want 403 for a pre-tenant token on another tenant's host", w.Code)
+	}
+	if w := tenantRequest(t, router, "rpms.smu.edu", legacy); w.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200 for a pre-tenant token on the default tenant's host", w.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccountSummary is a user as listed in the admin user directory
+type AccountSummary struct {
+	ID                     uuid.UUID  `json:"id"`
+	Email                  string     `json:"email"`
+	Name                   string     `json:"name"`
+	Role                   string     `json:"role"`
+	IsVerified             bool       `json:"is_verified"`
+	IsActive               bool       `json:"is_active"`
+	ReverificationRequired bool       `json:"reverification_required"`
+	MFAEnabled             bool       `json:"mfa_enabled"`
+	DeactivatedAt          *time.Time `json:"deactivated_at,omitempty"`
+	LastLoginAt            *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt              time.Time  `json:"created_at"`
+}
+
+type AccountPage struct {
+	Users []AccountSummary `json:"users"`
+	Total int              `json:"total"`
+	Page  int              `json:"page"`
+	Limit int              `json:"limit"`
+}
+
+// AccountDetail is everything an admin sees about one user
+type AccountDetail struct {
+	AccountSummary
+	Profile User       `json:"profile"`
+	Roles   *UserRoles `json:"roles"`
+	// Owned content that has to be reassigned before the account can be deleted
+	Papers  int `json:"papers"`
+	Events  int `json:"events"`
+	News    int `json:"news"`
+	Calls   int `json:"calls"`
+	Reviews int `json:"reviews"`
+}
+
+type ChangeUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=author editor admin coordinator"`
+}
+
+// AdminResetPasswordRequest sets the password directly when given, otherwise the user is
+// emailed a reset link
+type AdminResetPasswordRequest struct {
+	Password string `json:"password" binding:"omitempty,min=6"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review assignment statuses. An invitation is accepted or declined by the reviewer and
+// completed when they submit their review; editors may cancel it in between.
+const (
+	AssignmentInvited   = "invited"
+	AssignmentAccepted  = "accepted"
+	AssignmentDeclined  = "declined"
+	AssignmentCompleted = "completed"
+	AssignmentCancelled = "cancelled"
+)
+
+// ReviewAssignment is an invitation for a user to review a paper by a due date
+type ReviewAssignment struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	PaperID       uuid.UUID  `json:"paper_id" db:"paper_id"`
+	PaperTitle    string     `json:"paper_title" db:"paper_title"`
+	ReviewerID    uuid.UUID  `json:"reviewer_id" db:"reviewer_id"`
+	ReviewerName  string     `json:"reviewer_name" db:"reviewer_name"`
+	AssignedBy    *uuid.UUID `json:"assigned_by,omitempty" db:"assigned_by"`
+	Status        string     `json:"status" db:"status"`
+	DueAt         *time.Time `json:"due_at,omitempty" db:"due_at"`
+	RespondedAt   *time.Time `json:"responded_at,omitempty" db:"responded_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	DeclineReason string     `json:"decline_reason,omitempty" db:"decline_reason"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+func (a *ReviewAssignment) IsOpen() bool {
+	return a.Status == AssignmentInvited || a.Status == AssignmentAccepted
+}
+
+// IsOverdue reports whether an open assignment has passed its due date
+func (a *ReviewAssignment) IsOverdue(now time.Time) bool {
+	return a.IsOpen() && a.DueAt != nil && now.After(*a.DueAt)
+}
+
+type CreateReviewAssignmentRequest struct {
+	ReviewerID uuid.UUID  `json:"reviewer_id" binding:"required"`
+	DueAt      *time.Time `json:"due_at"`
+}
+
+type DeclineReviewAssignmentRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// ReviewerStats are a reviewer's performance and workload figures
+type ReviewerStats struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	Email  string    `json:"email"`
+	Role   string    `json:"role"`
+
+	ReviewsCompleted int `json:"reviews_completed"`
+	// AverageTurnaroundDays is measured from assignment to submission
+	AverageTurnaroundDays *float64 `json:"average_turnaround_days"`
+	Invitations           int      `json:"invitations"`
+	Declined              int      `json:"declined"`
+	DeclineRate           *float64 `json:"decline_rate"`
+	Overdue               int      `json:"overdue"`
+	CompletedLate         int      `json:"completed_late"`
+	OpenLoad              int      `json:"open_load"`
+
+	// Calibration: how often the recommendation matched the final decision, and how the
+	// reviewer's ratings compare with other reviewers of the same papers (positive is more lenient)
+	AverageRating     *float64 `json:"average_rating"`
+	DecidedReviews    int      `json:"decided_reviews"`
+	DecisionAgreement *float64 `json:"decision_agreement"`
+	RatingDeviation   *float64 `json:"rating_deviation"`
+}
+
+// DefaultReviewDays is how long a reviewer has when an assignment is made without a due date
+const DefaultReviewDays = 21
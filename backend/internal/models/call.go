@@ -0,0 +1,153 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Call represents a call for papers or a grant call that papers are submitted against
+type Call struct {
+	ID                 uuid.UUID         `json:"id" db:"id"`
+	Title              string            `json:"title" db:"title"`
+	Description        string            `json:"description" db:"description"`
+	Type               string            `json:"type" db:"type"` // "call_for_papers" or "grant"
+	EligibleCategories []string          `json:"eligible_categories" db:"eligible_categories"`
+	EligibleRanks      []string          `json:"eligible_ranks" db:"eligible_ranks"`
+	RequiredFields     []string          `json:"required_fields" db:"required_fields"`
+	Rubric             []RubricCriterion `json:"rubric" db:"rubric"`
+	OpensAt            time.Time         `json:"opens_at" db:"opens_at"`
+	ClosesAt           time.Time         `json:"closes_at" db:"closes_at"`
+	CoordinatorID      uuid.UUID         `json:"coordinator_id" db:"coordinator_id"`
+	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// RubricCriterion is a single scoring criterion reviewers apply to submissions of a call
+type RubricCriterion struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Weight      float64 `json:"weight"`
+	MaxScore    int     `json:"max_score"`
+}
+
+type CreateCallRequest struct {
+	Title              string            `json:"title" binding:"required,max=500"`
+	Description        string            `json:"description"`
+	Type               string            `json:"type" binding:"required,oneof=call_for_papers grant"`
+	EligibleCategories []string          `json:"eligible_categories"`
+	EligibleRanks      []string          `json:"eligible_ranks"`
+	RequiredFields     []string          `json:"required_fields"`
+	Rubric             []RubricCriterion `json:"rubric"`
+	OpensAt            time.Time         `json:"opens_at" binding:"required"`
+	ClosesAt           time.Time         `json:"closes_at" binding:"required"`
+}
+
+type UpdateCallRequest struct {
+	Title              string            `json:"title" binding:"required,max=500"`
+	Description        string            `json:"description"`
+	Type               string            `json:"type" binding:"required,oneof=call_for_papers grant"`
+	EligibleCategories []string          `json:"eligible_categories"`
+	EligibleRanks      []string          `json:"eligible_ranks"`
+	RequiredFields     []string          `json:"required_fields"`
+	Rubric             []RubricCriterion `json:"rubric"`
+	OpensAt            time.Time         `json:"opens_at" binding:"required"`
+	ClosesAt           time.Time         `json:"closes_at" binding:"required"`
+}
+
+// CallSubmission is a paper in a call's editorial queue
+type CallSubmission struct {
+	ID            uuid.UUID `json:"id"`
+	Title         string    `json:"title"`
+	Status        string    `json:"status"`
+	Type          string    `json:"type"`
+	AuthorID      uuid.UUID `json:"author_id"`
+	AuthorName    string    `json:"author_name"`
+	AuthorEmail   string    `json:"author_email"`
+	ReviewCount   int       `json:"review_count"`
+	AverageRating *float64  `json:"average_rating"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type CallStats struct {
+	CallID          uuid.UUID      `json:"call_id"`
+	State           string         `json:"state"`
+	Submissions     int            `json:"submissions"`
+	ByStatus        map[string]int `json:"by_status"`
+	Reviewed        int            `json:"reviewed"`
+	AwaitingReview  int            `json:"awaiting_review"`
+	AverageRating   *float64       `json:"average_rating"`
+	DistinctAuthors int            `json:"distinct_authors"`
+}
+
+// callFieldValues maps the paper fields a call may require onto the submitted values
+var callFieldValues = map[string]func(*CreatePaperRequest) string{
+	"abstract":                  func(r *CreatePaperRequest) string { return r.Abstract },
+	"content":                   func(r *CreatePaperRequest) string { return r.Content },
+	"file_url":                  func(r *CreatePaperRequest) string { return r.FileUrl },
+	"publication_title_amharic": func(r *CreatePaperRequest) string { return r.PublicationTitleAmharic },
+	"publication_isced_band":    func(r *CreatePaperRequest) string { return r.PublicationISCEDBand },
+	"publication_type":          func(r *CreatePaperRequest) string { return r.PublicationType },
+	"journal_type":              func(r *CreatePaperRequest) string { return r.JournalType },
+	"journal_name":              func(r *CreatePaperRequest) string { return r.JournalName },
+}
+
+// IsValidCallField reports whether a call may list the given paper field as required
+func IsValidCallField(field string) bool {
+	_, ok := callFieldValues[field]
+	return ok
+}
+
+func (c *Call) IsUpcoming(now time.Time) bool {
+	return now.Before(c.OpensAt)
+}
+
+func (c *Call) IsClosed(now time.Time) bool {
+	return !now.Before(c.ClosesAt)
+}
+
+func (c *Call) IsOpen(now time.Time) bool {
+	return !c.IsUpcoming(now) && !c.IsClosed(now)
+}
+
+// State returns "upcoming", "open" or "closed" for the given time
+func (c *Call) State(now time.Time) string {
+	switch {
+	case c.IsUpcoming(now):
+		return "upcoming"
+	case c.IsClosed(now):
+		return "closed"
+	default:
+		return "open"
+	}
+}
+
+// IsEligible checks an author's category and rank against the call's eligibility lists.
+// An empty list places no restriction.
+func (c *Call) IsEligible(authorCategory, academicRank string) bool {
+	return matchesAny(c.EligibleCategories, authorCategory) && matchesAny(c.EligibleRanks, academicRank)
+}
+
+// MissingFields returns the required fields left empty in a submission
+func (c *Call) MissingFields(req *CreatePaperRequest) []string {
+	var missing []string
+	for _, field := range c.RequiredFields {
+		value, ok := callFieldValues[field]
+		if ok && value(req) == "" {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+func matchesAny(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCallIsEligible(t *testing.T) {
+	cases := []struct {
+		name           string
+		categories     []string
+		ranks          []string
+		category, rank string
+		want           bool
+	}{
+		{"no restrictions", nil, nil, "Student", "", true},
+		{"category listed", []string{"Researcher", "Academic Staff"}, nil, "Academic Staff", "Lecturer", true},
+		{"category not listed", []string{"Researcher"}, nil, "Student", "Lecturer", false},
+		{"rank listed", nil, []string{"Professor", "Associate Professor"}, "Researcher", "Professor", true},
+		{"rank not listed", nil, []string{"Professor"}, "Researcher", "Lecturer", false},
+		{"both must match", []string{"Researcher"}, []string{"Professor"}, "Researcher", "Lecturer", false},
+		{"empty value against a list", []string{"Researcher"}, nil, "", "", false},
+		{"case sensitive", []string{"Researcher"}, nil, "researcher", "", false},
+	}
+	for _, tc := range cases {
+		call := Call{EligibleCategories: tc.categories, EligibleRanks: tc.ranks}
+		if got := call.IsEligible(tc.category, tc.rank); got != tc.want {
+			t.Errorf("%s: IsEligible(%q, %q) = %v, want %v", tc.name, tc.category, tc.rank, got, tc.want)
+		}
+	}
+}
+
+func TestCallMissingFields(t *testing.T) {
+	cases := []struct {
+		name     string
+		required []string
+		req      CreatePaperRequest
+		want     []string
+	}{
+		{"nothing required", nil, CreatePaperRequest{}, nil},
+		{"all present", []string{"abstract", "file_url"}, CreatePaperRequest{Abstract: "a", FileUrl: "f"}, nil},
+		{"some missing, in call order", []string{"journal_name", "abstract", "file_url"}, CreatePaperRequest{Abstract: "a"}, []string{"journal_name", "file_url"}},
+		{"unknown fields ignored", []string{"budget", "abstract"}, CreatePaperRequest{}, []string{"abstract"}},
+	}
+	for _, tc := range cases {
+		call := Call{RequiredFields: tc.required}
+		if got := call.MissingFields(&tc.req); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: MissingFields = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCallState(t *testing.T) {
+	opens := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	call := Call{OpensAt: opens, ClosesAt: opens.AddDate(0, 1, 0)}
+	cases := []struct {
+		at   time.Time
+		want string
+	}{
+		{opens.Add(-time.Second), "upcoming"},
+		{opens, "open"},
+		{call.ClosesAt.Add(-time.Second), "open"},
+		{call.ClosesAt, "closed"},
+	}
+	for _, tc := range cases {
+		if got := call.State(tc.at); got != tc.want {
+			t.Errorf("State(%s) = %q, want %q", tc.at, got, tc.want)
+		}
+	}
+}
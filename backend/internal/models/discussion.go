@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaperStaff is an editor or coordinator assigned to handle a paper
+type PaperStaff struct {
+	PaperID    uuid.UUID  `json:"paper_id" db:"paper_id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Email      string     `json:"email" db:"email"`
+	Role       string     `json:"role" db:"role"`
+	AssignedBy *uuid.UUID `json:"assigned_by,omitempty" db:"assigned_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+type AssignPaperStaffRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// Discussion message kinds. Decisions are pinned automatically.
+const (
+	DiscussionComment  = "comment"
+	DiscussionDecision = "decision"
+)
+
+// DiscussionMessage is a post in a paper's internal editorial discussion
+type DiscussionMessage struct {
+	ID             uuid.UUID   `json:"id" db:"id"`
+	PaperID        uuid.UUID   `json:"paper_id" db:"paper_id"`
+	AuthorID       uuid.UUID   `json:"author_id" db:"author_id"`
+	AuthorName     string      `json:"author_name" db:"author_name"`
+	Kind           string      `json:"kind" db:"kind"`
+	Body           string      `json:"body" db:"body"`
+	Mentions       []uuid.UUID `json:"mentions" db:"mentions"`
+	AttachmentURL  *string     `json:"attachment_url,omitempty" db:"attachment_url"`
+	AttachmentName *string     `json:"attachment_name,omitempty" db:"attachment_name"`
+	AttachmentType *string     `json:"attachment_type,omitempty" db:"attachment_type"`
+	AttachmentSize *int        `json:"attachment_size,omitempty" db:"attachment_size"`
+	Pinned         bool        `json:"pinned" db:"pinned"`
+	CreatedAt      time.Time   `json:"created_at" db:"created_at"`
+}
+
+type PostDiscussionMessageRequest struct {
+	Body           string      `json:"body" binding:"max=10000"`
+	Kind           string      `json:"kind" binding:"omitempty,oneof=comment decision"`
+	Mentions       []uuid.UUID `json:"mentions" binding:"omitempty,max=20"`
+	AttachmentURL  *string     `json:"attachment_url" binding:"omitempty,url"`
+	AttachmentName *string     `json:"attachment_name"`
+	AttachmentType *string     `json:"attachment_type"`
+	AttachmentSize *int        `json:"attachment_size"`
+}
+
+type PinDiscussionMessageRequest struct {
+	Pinned bool `json:"pinned"`
+}
+
+// PaperDiscussion is a paper's discussion thread with its pinned messages listed separately
+type PaperDiscussion struct {
+	Pinned   []DiscussionMessage `json:"pinned"`
+	Messages []DiscussionMessage `json:"messages"`
+	Staff    []PaperStaff        `json:"staff"`
+}
@@ -7,18 +7,19 @@ import (
 )
 
 type Event struct {
-	ID            uuid.UUID `json:"id" db:"id"`
-	Title         string    `json:"title" db:"title"`
-	Description   string    `json:"description" db:"description"`
-	Category      string    `json:"category" db:"category"`
-	Status        string    `json:"status" db:"status"`
-	ImageURL      string    `json:"image_url" db:"image_url"`
-	VideoURL      string    `json:"video_url" db:"video_url"`
-	Date          time.Time `json:"date" db:"date"`
-	Location      string    `json:"location" db:"location"`
-	CoordinatorID uuid.UUID `json:"coordinator_id" db:"coordinator_id"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	ID            uuid.UUID  `json:"id" db:"id"`
+	Title         string     `json:"title" db:"title"`
+	Description   string     `json:"description" db:"description"`
+	Category      string     `json:"category" db:"category"`
+	Status        string     `json:"status" db:"status"`
+	ImageURL      string     `json:"image_url" db:"image_url"`
+	VideoURL      string     `json:"video_url" db:"video_url"`
+	Date          time.Time  `json:"date" db:"date"`
+	Location      string     `json:"location" db:"location"`
+	CoordinatorID uuid.UUID  `json:"coordinator_id" db:"coordinator_id"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	PublishAt     *time.Time `json:"publish_at,omitempty" db:"publish_at"`
 }
 
 type CreateEventRequest struct {
@@ -0,0 +1,45 @@
+package models
+
+import "strings"
+
+// ISCEDFields are the ISCED-F 2013 broad fields of education and training, by code
+var ISCEDFields = map[string]string{
+	"00": "Generic programmes and qualifications",
+	"01": "Education",
+	"02": "Arts and humanities",
+	"03": "Social sciences, journalism and information",
+	"04": "Business, administration and law",
+	"05": "Natural sciences, mathematics and statistics",
+	"06": "Information and communication technologies",
+	"07": "Engineering, manufacturing and construction",
+	"08": "Agriculture, forestry, fisheries and veterinary",
+	"09": "Health and welfare",
+	"10": "Services",
+}
+
+const (
+	maxKeywords      = 30
+	maxKeywordLength = 100
+)
+
+// NormalizeKeywords trims and lowercases keywords, dropping blanks and duplicates.
+// A nil input stays nil so that callers can tell "not provided" from "cleared".
+func NormalizeKeywords(keywords []string) []string {
+	if keywords == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(keywords))
+	out := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		k = strings.ToLower(strings.Join(strings.Fields(k), " "))
+		if k == "" || seen[k] || len(k) > maxKeywordLength {
+			continue
+		}
+		seen[k] = true
+		out = append(out, k)
+		if len(out) == maxKeywords {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Journal struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	ISSN        string    `json:"issn" db:"issn"`
+	Publisher   string    `json:"publisher" db:"publisher"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type JournalVolume struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	JournalID uuid.UUID `json:"journal_id" db:"journal_id"`
+	Number    int       `json:"number" db:"number"`
+	Year      int       `json:"year" db:"year"`
+	Title     string    `json:"title" db:"title"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type JournalIssue struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	VolumeID        uuid.UUID  `json:"volume_id" db:"volume_id"`
+	Number          int        `json:"number" db:"number"`
+	Title           string     `json:"title" db:"title"`
+	PublicationDate *time.Time `json:"publication_date" db:"publication_date"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type CreateJournalRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	ISSN        string `json:"issn"`
+	Publisher   string `json:"publisher"`
+	Description string `json:"description"`
+}
+
+type CreateVolumeRequest struct {
+	Number int    `json:"number" binding:"required,min=1"`
+	Year   int    `json:"year" binding:"required,min=1900"`
+	Title  string `json:"title"`
+}
+
+type CreateIssueRequest struct {
+	Number          int        `json:"number" binding:"required,min=1"`
+	Title           string     `json:"title"`
+	PublicationDate *time.Time `json:"publication_date"`
+}
+
+type AssignIssuePaperRequest struct {
+	PaperID   uuid.UUID `json:"paper_id" binding:"required"`
+	PageStart int       `json:"page_start" binding:"required,min=1"`
+	PageEnd   int       `json:"page_end" binding:"required,min=1"`
+	Order     int       `json:"order"`
+}
+
+// TableOfContentsEntry is a paper as listed in an issue's table of contents
+type TableOfContentsEntry struct {
+	Order         int       `json:"order"`
+	PaperID       uuid.UUID `json:"paper_id"`
+	Title         string    `json:"title"`
+	AuthorName    string    `json:"author_name"`
+	PublicationID string    `json:"publication_id"`
+	PageStart     int       `json:"page_start"`
+	PageEnd       int       `json:"page_end"`
+}
+
+type TableOfContents struct {
+	Journal Journal                `json:"journal"`
+	Volume  JournalVolume          `json:"volume"`
+	Issue   JournalIssue           `json:"issue"`
+	Entries []TableOfContentsEntry `json:"entries"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sign in attempt outcomes
+const (
+	LoginSucceeded          = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginThrottled          = "throttled"
+	LoginLocked             = "locked"
+)
+
+// LoginAttempt is the audit record of one sign in attempt
+type LoginAttempt struct {
+	ID        int64      `json:"id"`
+	Email     string     `json:"email"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	IPAddress string     `json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	Outcome   string     `json:"outcome"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// LoginLockStatus describes the failed sign in history of an account
+type LoginLockStatus struct {
+	Locked         bool           `json:"locked"`
+	LockedUntil    *time.Time     `json:"locked_until,omitempty"`
+	FailedAttempts int            `json:"failed_attempts"`
+	Attempts       []LoginAttempt `json:"attempts"`
+}
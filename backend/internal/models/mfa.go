@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// RecoveryCodeCount is how many recovery codes are issued at a time
+const RecoveryCodeCount = 10
+
+// MFAChallenge is returned by sign in instead of a session when a second factor is needed
+type MFAChallenge struct {
+	MFARequired bool `json:"mfa_required"`
+	// EnrollmentRequired means the user's role requires 2FA but none is set up yet
+	EnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	MFAToken           string `json:"mfa_token"`
+	ExpiresIn          int    `json:"expires_in"`
+}
+
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAVerifyRequest carries either an authenticator code or a recovery code
+type MFAVerifyRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFAEnableResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	// Session is set when enabling completed a sign in that required enrollment
+	Session *LoginResponse `json:"session,omitempty"`
+}
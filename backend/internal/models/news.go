@@ -7,17 +7,18 @@ import (
 )
 
 type News struct {
-	ID        uuid.UUID `json:"id"`
-	Title     string    `json:"title"`
-	Summary   string    `json:"summary"`
-	Content   string    `json:"content"`
-	Category  string    `json:"category"`
-	Status    string    `json:"status"`
-	ImageURL  string    `json:"image_url"`
-	VideoURL  string    `json:"video_url"`
-	EditorID  uuid.UUID `json:"editor_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID  `json:"id"`
+	Title     string     `json:"title"`
+	Summary   string     `json:"summary"`
+	Content   string     `json:"content"`
+	Category  string     `json:"category"`
+	Status    string     `json:"status"`
+	ImageURL  string     `json:"image_url"`
+	VideoURL  string     `json:"video_url"`
+	EditorID  uuid.UUID  `json:"editor_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
 }
 
 type CreateNewsRequest struct {
@@ -18,6 +18,32 @@ type Paper struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 
+	// Subject keywords, used with the title and abstract to suggest reviewers
+	Keywords []string `json:"keywords" db:"keywords"`
+
+	// Call the paper was submitted against, if any
+	CallID *uuid.UUID `json:"call_id" db:"call_id"`
+
+	// Placement in one of our own journal issues
+	IssueID    *uuid.UUID `json:"issue_id" db:"issue_id"`
+	PageStart  int        `json:"page_start" db:"page_start"`
+	PageEnd    int        `json:"page_end" db:"page_end"`
+	IssueOrder int        `json:"issue_order" db:"issue_order"`
+
+	// Withdrawal and retraction
+	WithdrawnAt      *time.Time `json:"withdrawn_at,omitempty" db:"withdrawn_at"`
+	WithdrawalReason string     `json:"withdrawal_reason,omitempty" db:"withdrawal_reason"`
+	RetractedAt      *time.Time `json:"retracted_at,omitempty" db:"retracted_at"`
+	RetractionNotice string     `json:"retraction_notice,omitempty" db:"retraction_notice"`
+
+	// Scheduled publication and manuscript embargo
+	PublishAt    *time.Time `json:"publish_at,omitempty" db:"publish_at"`
+	EmbargoUntil *time.Time `json:"embargo_until,omitempty" db:"embargo_until"`
+	Embargoed    bool       `json:"embargoed,omitempty" db:"-"`
+
+	// Organizational unit (department, college or institution) the paper is attributed to
+	UnitID *uuid.UUID `json:"unit_id" db:"unit_id"`
+
 	// Editor Submission Fields
 	InstitutionCode         string     `json:"institution_code" db:"institution_code"`
 	PublicationID           string     `json:"publication_id" db:"publication_id"`
@@ -51,16 +77,20 @@ type Paper struct {
 }
 
 type CreatePaperRequest struct {
-	Title                   string `json:"title" binding:"required,max=500"`
-	Abstract                string `json:"abstract"`
-	Content                 string `json:"content"`
-	FileUrl                 string `json:"file_url"`
-	Type                    string `json:"type"`
-	PublicationTitleAmharic string `json:"publication_title_amharic"`
-	PublicationISCEDBand    string `json:"publication_isced_band"`
-	PublicationType         string `json:"publication_type"`
-	JournalType             string `json:"journal_type"`
-	JournalName             string `json:"journal_name"`
+	Title                   string   `json:"title" binding:"required,max=500"`
+	Abstract                string   `json:"abstract"`
+	Content                 string   `json:"content"`
+	FileUrl                 string   `json:"file_url"`
+	Type                    string   `json:"type"`
+	PublicationTitleAmharic string   `json:"publication_title_amharic"`
+	PublicationISCEDBand    string   `json:"publication_isced_band"`
+	PublicationType         string   `json:"publication_type"`
+	JournalType             string   `json:"journal_type"`
+	JournalName             string   `json:"journal_name"`
+	Keywords                []string `json:"keywords" binding:"omitempty,max=30"`
+
+	// Defaults to the author's primary affiliation
+	UnitID *uuid.UUID `json:"unit_id"`
 }
 
 type UpdatePaperRequest struct {
@@ -69,6 +99,8 @@ type UpdatePaperRequest struct {
 	Content  string `json:"content"`
 	FileUrl  string `json:"file_url"`
 	Status   string `json:"status" binding:"oneof=draft submitted under_review approved rejected recommended_for_publication published"`
+	// Omit to keep the current keywords
+	Keywords []string `json:"keywords" binding:"omitempty,max=30"`
 
 	// Editor Fields
 	InstitutionCode         string    `json:"institution_code"`
@@ -102,6 +134,47 @@ type UpdatePaperRequest struct {
 	SubmittedToIncubator     string  `json:"submitted_to_incubator"`
 }
 
+type AssignPaperUnitRequest struct {
+	UnitID *uuid.UUID `json:"unit_id"`
+}
+
+type WithdrawPaperRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RetractPaperRequest struct {
+	Notice string `json:"notice" binding:"required"`
+}
+
+type PaperDecisionRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected published"`
+}
+
+// DecisionFrom lists, for each decision, the statuses a paper may be in when it is made
+var DecisionFrom = map[string][]string{
+	"approved":  {"under_review", "recommended_for_publication"},
+	"rejected":  {"submitted", "under_review", "recommended_for_publication"},
+	"published": {"approved", "recommended_for_publication"},
+}
+
+// RecommendFrom lists the statuses a paper may be recommended for publication from
+var RecommendFrom = []string{"submitted", "under_review"}
+
+// DeletableStatuses are the statuses of papers that never reached or left the review process,
+// which may be deleted; later papers are withdrawn or retracted instead
+var DeletableStatuses = []string{"draft", "withdrawn"}
+
+// Retraction is the public notice attached to a retracted paper
+type Retraction struct {
+	PaperID       uuid.UUID `json:"paper_id"`
+	Title         string    `json:"title"`
+	AuthorName    string    `json:"author_name"`
+	PublicationID string    `json:"publication_id"`
+	JournalName   string    `json:"journal_name"`
+	RetractedAt   time.Time `json:"retracted_at"`
+	Notice        string    `json:"notice"`
+}
+
 type PaperWithAuthor struct {
 	Paper
 	AuthorName           string `json:"author_name" db:"author_name"`
@@ -147,6 +220,68 @@ func (p *Paper) IsPublished() bool {
 	return p.Status == "published"
 }
 
+func (p *Paper) IsWithdrawn() bool {
+	return p.Status == "withdrawn"
+}
+
+func (p *Paper) IsRetracted() bool {
+	return p.Status == "retracted"
+}
+
+// WithdrawableStatuses are the statuses of papers awaiting a decision, which their author may withdraw
+var WithdrawableStatuses = []string{"draft", "submitted", "under_review"}
+
+// CanWithdraw reports whether the author may still withdraw the paper, i.e. no decision has been made
+func (p *Paper) CanWithdraw() bool {
+	for _, status := range WithdrawableStatuses {
+		if p.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Paper) CanRetract() bool {
+	return p.IsPublished()
+}
+
+// IsUnderEmbargo reports whether the manuscript must still be kept private at the given time
+func (p *Paper) IsUnderEmbargo(now time.Time) bool {
+	return p.EmbargoUntil != nil && now.Before(*p.EmbargoUntil)
+}
+
+// MaskEmbargoedManuscript hides the manuscript of an embargoed paper from everyone but its
+// author and staff, who are those reading all papers
+func (p *Paper) MaskEmbargoedManuscript(viewerID string, staff bool, now time.Time) {
+	if !p.IsUnderEmbargo(now) {
+		return
+	}
+	p.Embargoed = true
+	if p.AuthorID.String() == viewerID || staff {
+		return
+	}
+	p.FileUrl = ""
+	p.Content = ""
+}
+
+// SchedulableStatuses are the statuses of papers accepted but not yet published, whose
+// publication may be scheduled
+var SchedulableStatuses = []string{"approved", "recommended_for_publication"}
+
+// EmbargoableStatuses are the statuses of papers whose manuscript may be embargoed: accepted
+// papers and published ones
+var EmbargoableStatuses = []string{"approved", "recommended_for_publication", "published"}
+
+// CanSchedule reports whether the paper has been accepted but not yet published
+func (p *Paper) CanSchedule() bool {
+	for _, status := range SchedulableStatuses {
+		if p.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Paper) CanEdit() bool {
 	return p.IsDraft()
 }
@@ -158,3 +293,8 @@ func (p *Paper) CanSubmit() bool {
 func (p *Paper) CanReview() bool {
 	return p.IsSubmitted() || p.IsUnderReview()
 }
+
+// HasFinalDecision reports whether the paper has left review for good, after which its reviews are locked
+func (p *Paper) HasFinalDecision() bool {
+	return p.IsApproved() || p.IsRejected() || p.IsPublished() || p.IsWithdrawn() || p.IsRetracted()
+}
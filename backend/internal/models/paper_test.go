@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPaperCanSchedule(t *testing.T) {
+	want := map[string]bool{
+		"draft":                       false,
+		"submitted":                   false,
+		"under_review":                false,
+		"recommended_for_publication": true,
+		"approved":                    true,
+		"rejected":                    false,
+		"published":                   false,
+		"withdrawn":                   false,
+		"retracted":                   false,
+	}
+	for status, schedulable := range want {
+		paper := Paper{Status: status}
+		if got := paper.CanSchedule(); got != schedulable {
+			t.Errorf("CanSchedule() for %s = %v, want %v", status, got, schedulable)
+		}
+	}
+}
+
+func TestPaperIsUnderEmbargo(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		end := now.Add(d)
+		return &end
+	}
+	cases := []struct {
+		name  string
+		until *time.Time
+		want  bool
+	}{
+		{"no embargo", nil, false},
+		{"ends later", at(time.Hour), true},
+		{"ends now", at(0), false},
+		{"ended", at(-time.Hour), false},
+	}
+	for _, tc := range cases {
+		paper := Paper{EmbargoUntil: tc.until}
+		if got := paper.IsUnderEmbargo(now); got != tc.want {
+			t.Errorf("%s: IsUnderEmbargo = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPaperMaskEmbargoedManuscript(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+	author := uuid.New()
+	cases := []struct {
+		name         string
+		until        *time.Time
+		viewer       string
+		staff        bool
+		wantEmbargo  bool
+		wantReadable bool
+	}{
+		{"no embargo", nil, uuid.NewString(), false, false, true},
+		{"embargo ended", &earlier, uuid.NewString(), false, false, true},
+		{"embargoed for the public", &later, "", false, true, false},
+		{"embargoed for another user", &later, uuid.NewString(), false, true, false},
+		{"embargoed for the author", &later, author.String(), false, true, true},
+		{"embargoed for staff", &later, uuid.NewString(), true, true, true},
+	}
+	for _, tc := range cases {
+		paper := Paper{AuthorID: author, EmbargoUntil: tc.until, FileUrl: "papers/1.pdf", Content: "manuscript"}
+		paper.MaskEmbargoedManuscript(tc.viewer, tc.staff, now)
+		if paper.Embargoed != tc.wantEmbargo {
+			t.Errorf("%s: Embargoed = %v, want %v", tc.name, paper.Embargoed, tc.wantEmbargo)
+		}
+		readable := paper.FileUrl != "" && paper.Content != ""
+		masked := paper.FileUrl == "" && paper.Content == ""
+		if readable != tc.wantReadable || masked == tc.wantReadable {
+			t.Errorf("%s: file %q content %q, want readable %v", tc.name, paper.FileUrl, paper.Content, tc.wantReadable)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PublicPaper is the subset of a published paper that is safe to show without authentication
+type PublicPaper struct {
+	ID              uuid.UUID  `json:"id"`
+	Slug            string     `json:"slug"`
+	Title           string     `json:"title"`
+	TitleAmharic    string     `json:"title_amharic,omitempty"`
+	Abstract        string     `json:"abstract"`
+	Type            string     `json:"type"`
+	AuthorName      string     `json:"author_name"`
+	CoInvestigators string     `json:"co_investigators,omitempty"`
+	PublicationID   string     `json:"publication_id,omitempty"`
+	PublicationDate *time.Time `json:"publication_date,omitempty"`
+	Year            int        `json:"year"`
+	ISCEDBand       string     `json:"isced_band,omitempty"`
+	PublicationType string     `json:"publication_type,omitempty"`
+	JournalName     string     `json:"journal_name,omitempty"`
+	IssueID         *uuid.UUID `json:"issue_id,omitempty"`
+	PageStart       int        `json:"page_start,omitempty"`
+	PageEnd         int        `json:"page_end,omitempty"`
+	Embargoed       bool       `json:"embargoed"`
+	EmbargoUntil    *time.Time `json:"embargo_until,omitempty"`
+	DownloadURL     string     `json:"download_url,omitempty"`
+}
+
+type PublicCatalogPage struct {
+	Papers []PublicPaper `json:"papers"`
+	Total  int           `json:"total"`
+	Page   int           `json:"page"`
+	Limit  int           `json:"limit"`
+}
+
+// CatalogFacet is a browse value with the number of published papers under it
+type CatalogFacet struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type CatalogFacets struct {
+	Years      []CatalogFacet `json:"years"`
+	ISCEDBands []CatalogFacet `json:"isced_bands"`
+	Journals   []CatalogFacet `json:"journals"`
+}
+
+// PaperSlug builds the public URL slug of a paper, e.g. "soil-erosion-in-the-rift-valley-1a2b3c4d".
+// The trailing ID prefix keeps slugs unique and lets lookups survive title edits.
+func PaperSlug(id uuid.UUID, title string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+		if sb.Len() >= 80 {
+			break
+		}
+	}
+	base := strings.TrimSuffix(sb.String(), "-")
+	prefix := id.String()[:8]
+	if base == "" {
+		return prefix
+	}
+	return base + "-" + prefix
+}
+
+// SlugIDPrefix extracts the ID prefix from a paper slug
+func SlugIDPrefix(slug string) string {
+	if i := strings.LastIndex(slug, "-"); i >= 0 {
+		return slug[i+1:]
+	}
+	return slug
+}
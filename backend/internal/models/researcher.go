@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileVisibility holds the researcher's choices of what their public profile shows.
+// It is stored in users.preferences under "profile_visibility".
+type ProfileVisibility struct {
+	Public       bool `json:"public"`
+	ShowBio      bool `json:"show_bio"`
+	ShowORCID    bool `json:"show_orcid"`
+	ShowPapers   bool `json:"show_papers"`
+	ShowProjects bool `json:"show_projects"`
+	ShowMetrics  bool `json:"show_metrics"`
+	ShowFunding  bool `json:"show_funding"`
+}
+
+// ProfileVisibilityFrom reads the visibility toggles from user preferences. Profiles are private
+// and funding figures hidden until the researcher turns them on; once public, the other sections
+// show unless turned off.
+func ProfileVisibilityFrom(preferences map[string]interface{}) ProfileVisibility {
+	v := ProfileVisibility{
+		ShowBio:      true,
+		ShowORCID:    true,
+		ShowPapers:   true,
+		ShowProjects: true,
+		ShowMetrics:  true,
+	}
+	raw, ok := preferences["profile_visibility"].(map[string]interface{})
+	if !ok {
+		return v
+	}
+	toggles := map[string]*bool{
+		"public":        &v.Public,
+		"show_bio":      &v.ShowBio,
+		"show_orcid":    &v.ShowORCID,
+		"show_papers":   &v.ShowPapers,
+		"show_projects": &v.ShowProjects,
+		"show_metrics":  &v.ShowMetrics,
+		"show_funding":  &v.ShowFunding,
+	}
+	for key, target := range toggles {
+		if b, ok := raw[key].(bool); ok {
+			*target = b
+		}
+	}
+	return v
+}
+
+// ValidORCID checks the 0000-0000-0000-000X format and the ISO 7064 11,2 check digit
+func ValidORCID(orcid string) bool {
+	if len(orcid) != 19 {
+		return false
+	}
+	total := 0
+	for i, r := range orcid {
+		if i == 4 || i == 9 || i == 14 {
+			if r != '-' {
+				return false
+			}
+			continue
+		}
+		if i == 18 {
+			break
+		}
+		if r < '0' || r > '9' {
+			return false
+		}
+		total = (total + int(r-'0')) * 2
+	}
+	check := (12 - total%11) % 11
+	last := orcid[18]
+	if check == 10 {
+		return last == 'X'
+	}
+	return last == byte('0'+check)
+}
+
+type PaperContributor struct {
+	PaperID   uuid.UUID `json:"paper_id" db:"paper_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type AddContributorRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role"`
+}
+
+type YearCount struct {
+	Year  int `json:"year"`
+	Count int `json:"count"`
+}
+
+type ResearcherMetrics struct {
+	TotalPublications  int         `json:"total_publications"`
+	PublicationsByYear []YearCount `json:"publications_by_year"`
+	ProjectsLed        int         `json:"projects_led"`
+	FundingAttracted   *float64    `json:"funding_attracted,omitempty"`
+}
+
+// ResearcherProfile is the public view of a researcher
+type ResearcherProfile struct {
+	ID            uuid.UUID          `json:"id"`
+	Name          string             `json:"name"`
+	AcademicRank  string             `json:"academic_rank"`
+	Qualification string             `json:"qualification"`
+	Bio           string             `json:"bio,omitempty"`
+	Avatar        string             `json:"avatar"`
+	ORCID         string             `json:"orcid,omitempty"`
+	Papers        []PublicPaper      `json:"papers,omitempty"`
+	Projects      []PublicPaper      `json:"projects,omitempty"`
+	Metrics       *ResearcherMetrics `json:"metrics,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "testing"
+
+func TestProfileVisibilityFrom(t *testing.T) {
+	defaults := ProfileVisibilityFrom(nil)
+	if defaults.Public || defaults.ShowFunding {
+		t.Errorf("defaults = %+v, profiles and funding must be opt-in", defaults)
+	}
+	if !defaults.ShowBio || !defaults.ShowPapers || !defaults.ShowMetrics {
+		t.Errorf("defaults = %+v, sections of a public profile show unless turned off", defaults)
+	}
+
+	v := ProfileVisibilityFrom(map[string]interface{}{
+		"profile_visibility": map[string]interface{}{"public": true, "show_funding": true, "show_orcid": false, "show_bio": "no"},
+	})
+	if !v.Public || !v.ShowFunding || v.ShowORCID || !v.ShowBio {
+		t.Errorf("visibility = %+v", v)
+	}
+}
@@ -1,11 +1,20 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Review statuses. Drafts are only visible to their reviewer; a review is locked once
+// the paper has a final decision and can no longer be changed.
+const (
+	ReviewDraft     = "draft"
+	ReviewSubmitted = "submitted"
+	ReviewLocked    = "locked"
+)
+
 type Review struct {
 	ID               uuid.UUID `json:"id" db:"id"`
 	PaperID          uuid.UUID `json:"paper_id" db:"paper_id"`
@@ -21,41 +30,78 @@ type Review struct {
 	Contribution     int       `json:"contribution_knowledge" db:"contribution_knowledge"`
 	TechnicalQuality int       `json:"technical_quality" db:"technical_quality"`
 	Comments         string    `json:"comments" db:"comments"`
-	Recommendation   string    `json:"recommendation" db:"recommendation"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	// ConfidentialComments are addressed to the editors only and never shown to the author
+	ConfidentialComments string             `json:"confidential_comments,omitempty" db:"confidential_comments"`
+	Recommendation       string             `json:"recommendation" db:"recommendation"`
+	Attachments          []ReviewAttachment `json:"attachments,omitempty"`
+	Status               string             `json:"status" db:"status"`
+	SubmittedAt          *time.Time         `json:"submitted_at,omitempty" db:"submitted_at"`
+	LockedAt             *time.Time         `json:"locked_at,omitempty" db:"locked_at"`
+	CreatedAt            time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time          `json:"updated_at" db:"updated_at"`
 }
 
 type CreateReviewRequest struct {
-	PaperID          uuid.UUID `json:"paper_id" binding:"required"`
-	ReviewerID       uuid.UUID `json:"reviewer_id"`
-	Rating           int       `json:"rating" binding:"required,min=0,max=100"`
-	ProblemStatement int       `json:"problem_statement" binding:"required,min=0,max=100"`
-	LiteratureReview int       `json:"literature_review" binding:"required,min=0,max=100"`
-	Methodology      int       `json:"methodology" binding:"required,min=0,max=100"`
-	Results          int       `json:"results" binding:"required,min=0,max=100"`
-	Conclusion       int       `json:"conclusion" binding:"required,min=0,max=100"`
-	Originality      int       `json:"originality" binding:"required,min=0,max=100"`
-	ClarityOrg       int       `json:"clarity_organization" binding:"required,min=0,max=100"`
-	Contribution     int       `json:"contribution_knowledge" binding:"required,min=0,max=100"`
-	TechnicalQuality int       `json:"technical_quality" binding:"required,min=0,max=100"`
-	Comments         string    `json:"comments"`
-	Recommendation   string    `json:"recommendation" binding:"required,oneof=accept minor_revision major_revision reject"`
+	PaperID              uuid.UUID               `json:"paper_id" binding:"required"`
+	ReviewerID           uuid.UUID               `json:"reviewer_id"`
+	Rating               int                     `json:"rating" binding:"min=0,max=100"`
+	ProblemStatement     int                     `json:"problem_statement" binding:"min=0,max=100"`
+	LiteratureReview     int                     `json:"literature_review" binding:"min=0,max=100"`
+	Methodology          int                     `json:"methodology" binding:"min=0,max=100"`
+	Results              int                     `json:"results" binding:"min=0,max=100"`
+	Conclusion           int                     `json:"conclusion" binding:"min=0,max=100"`
+	Originality          int                     `json:"originality" binding:"min=0,max=100"`
+	ClarityOrg           int                     `json:"clarity_organization" binding:"min=0,max=100"`
+	Contribution         int                     `json:"contribution_knowledge" binding:"min=0,max=100"`
+	TechnicalQuality     int                     `json:"technical_quality" binding:"min=0,max=100"`
+	Comments             string                  `json:"comments"`
+	ConfidentialComments string                  `json:"confidential_comments"`
+	Recommendation       string                  `json:"recommendation" binding:"omitempty,oneof=accept minor_revision major_revision reject"`
+	Attachments          []ReviewAttachmentInput `json:"attachments" binding:"omitempty,max=10,dive"`
+	Status               string                  `json:"status" binding:"omitempty,oneof=draft submitted"`
 }
 
 type UpdateReviewRequest struct {
-	Rating           int    `json:"rating" binding:"required,min=0,max=100"`
-	ProblemStatement int    `json:"problem_statement" binding:"required,min=0,max=100"`
-	LiteratureReview int    `json:"literature_review" binding:"required,min=0,max=100"`
-	Methodology      int    `json:"methodology" binding:"required,min=0,max=100"`
-	Results          int    `json:"results" binding:"required,min=0,max=100"`
-	Conclusion       int    `json:"conclusion" binding:"required,min=0,max=100"`
-	Originality      int    `json:"originality" binding:"required,min=0,max=100"`
-	ClarityOrg       int    `json:"clarity_organization" binding:"required,min=0,max=100"`
-	Contribution     int    `json:"contribution_knowledge" binding:"required,min=0,max=100"`
-	TechnicalQuality int    `json:"technical_quality" binding:"required,min=0,max=100"`
-	Comments         string `json:"comments"`
-	Recommendation   string `json:"recommendation" binding:"required,oneof=accept minor_revision major_revision reject"`
+	Rating               int    `json:"rating" binding:"min=0,max=100"`
+	ProblemStatement     int    `json:"problem_statement" binding:"min=0,max=100"`
+	LiteratureReview     int    `json:"literature_review" binding:"min=0,max=100"`
+	Methodology          int    `json:"methodology" binding:"min=0,max=100"`
+	Results              int    `json:"results" binding:"min=0,max=100"`
+	Conclusion           int    `json:"conclusion" binding:"min=0,max=100"`
+	Originality          int    `json:"originality" binding:"min=0,max=100"`
+	ClarityOrg           int    `json:"clarity_organization" binding:"min=0,max=100"`
+	Contribution         int    `json:"contribution_knowledge" binding:"min=0,max=100"`
+	TechnicalQuality     int    `json:"technical_quality" binding:"min=0,max=100"`
+	Comments             string `json:"comments"`
+	ConfidentialComments string `json:"confidential_comments"`
+	Recommendation       string `json:"recommendation" binding:"omitempty,oneof=accept minor_revision major_revision reject"`
+	// Attachments replaces the review's attachments when present; omit it to keep them
+	Attachments []ReviewAttachmentInput `json:"attachments" binding:"omitempty,max=10,dive"`
+	Status      string                  `json:"status" binding:"omitempty,oneof=draft submitted"`
+}
+
+// ReviewAttachment is a file attached to a review, typically an annotated copy of the manuscript.
+// Confidential attachments are, like confidential comments, for the editors only.
+type ReviewAttachment struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	ReviewID     uuid.UUID `json:"review_id" db:"review_id"`
+	FileURL      string    `json:"file_url" db:"file_url"`
+	FileName     string    `json:"file_name" db:"file_name"`
+	FileType     string    `json:"file_type" db:"file_type"`
+	FileSize     int64     `json:"file_size" db:"file_size"`
+	Note         string    `json:"note" db:"note"`
+	Confidential bool      `json:"confidential" db:"confidential"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReviewAttachmentInput describes a file already uploaded through /upload
+type ReviewAttachmentInput struct {
+	FileURL      string `json:"file_url" binding:"required,url"`
+	FileName     string `json:"file_name" binding:"required"`
+	FileType     string `json:"file_type"`
+	FileSize     int64  `json:"file_size" binding:"min=0"`
+	Note         string `json:"note"`
+	Confidential bool   `json:"confidential"`
 }
 
 type ReviewWithReviewer struct {
@@ -84,3 +130,52 @@ func (r *Review) IsReject() bool {
 func (r *Review) IsValidRating() bool {
 	return r.Rating >= 1 && r.Rating <= 5
 }
+
+// RedactForAuthor removes everything addressed to the editors only
+func (r *Review) RedactForAuthor() {
+	r.ConfidentialComments = ""
+	visible := r.Attachments[:0]
+	for _, a := range r.Attachments {
+		if !a.Confidential {
+			visible = append(visible, a)
+		}
+	}
+	r.Attachments = visible
+}
+
+func (r *Review) IsDraft() bool {
+	return r.Status == ReviewDraft
+}
+
+func (r *Review) IsLocked() bool {
+	return r.Status == ReviewLocked
+}
+
+// MissingForSubmission lists the fields a draft still needs before it can be submitted
+func (r *Review) MissingForSubmission() []string {
+	var missing []string
+	scores := []struct {
+		name  string
+		value int
+	}{
+		{"rating", r.Rating},
+		{"problem_statement", r.ProblemStatement},
+		{"literature_review", r.LiteratureReview},
+		{"methodology", r.Methodology},
+		{"results", r.Results},
+		{"conclusion", r.Conclusion},
+		{"originality", r.Originality},
+		{"clarity_organization", r.ClarityOrg},
+		{"contribution_knowledge", r.Contribution},
+		{"technical_quality", r.TechnicalQuality},
+	}
+	for _, score := range scores {
+		if score.value == 0 {
+			missing = append(missing, score.name)
+		}
+	}
+	if strings.TrimSpace(r.Recommendation) == "" {
+		missing = append(missing, "recommendation")
+	}
+	return missing
+}
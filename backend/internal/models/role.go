@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is a named set of permissions within a tenant
+type Role struct {
+	ID          *uuid.UUID `json:"id,omitempty"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Permissions []string   `json:"permissions"`
+	// BuiltIn roles are the ones users.role can hold; they cannot be renamed or deleted
+	BuiltIn   bool       `json:"built_in"`
+	UserCount int        `json:"user_count"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type UpdateRoleRequest struct {
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// SetUserRolesRequest lists the roles a user holds besides their primary role
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required"`
+}
+
+// UserRoles describes the roles and resulting permissions of a user
+type UserRoles struct {
+	UserID      uuid.UUID `json:"user_id"`
+	PrimaryRole string    `json:"primary_role"`
+	Roles       []string  `json:"roles"`
+	Permissions []string  `json:"permissions"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ScheduleRequest sets the time at which a draft news item or event goes public
+type ScheduleRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"`
+}
+
+// SchedulePaperRequest sets when an accepted paper is published and, optionally,
+// until when its manuscript stays private after publication
+type SchedulePaperRequest struct {
+	PublishAt    time.Time  `json:"publish_at" binding:"required"`
+	EmbargoUntil *time.Time `json:"embargo_until"`
+}
+
+// EmbargoRequest sets or, when null, lifts the embargo on a paper's manuscript
+type EmbargoRequest struct {
+	EmbargoUntil *time.Time `json:"embargo_until"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ServiceAccount is a non-human caller of the API, such as a reporting integration
+type ServiceAccount struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Scopes      []string   `json:"scopes"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	Keys        []APIKey   `json:"keys,omitempty"`
+}
+
+// APIKey describes a key of a service account; the key itself is only shown once, on creation
+type APIKey struct {
+	ID               uuid.UUID  `json:"id"`
+	ServiceAccountID uuid.UUID  `json:"service_account_id"`
+	Prefix           string     `json:"prefix"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	RotatedFrom      *uuid.UUID `json:"rotated_from,omitempty"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP       string     `json:"last_used_ip"`
+	CreatedAt        time.Time  `json:"created_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewAPIKey is a freshly issued key together with its plaintext value
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes" binding:"required"`
+}
+
+type UpdateServiceAccountRequest struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	Scopes      []string `json:"scopes"`
+	Disabled    *bool    `json:"disabled"`
+}
+
+// CreateAPIKeyRequest sets how long a new key is valid; zero days means it never expires
+type CreateAPIKeyRequest struct {
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// RotateAPIKeyRequest replaces a key, keeping the old one valid for a grace period so
+// the integration can switch over
+type RotateAPIKeyRequest struct {
+	ExpiresInDays int `json:"expires_in_days"`
+	GraceHours    int `json:"grace_hours"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// SSOCallbackRequest carries what the identity provider sent the user back to the frontend with
+type SSOCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	UnitInstitution = "institution"
+	UnitCollege     = "college"
+	UnitDepartment  = "department"
+)
+
+// parentUnitType maps each unit type onto the type its parent must have
+var parentUnitType = map[string]string{
+	UnitInstitution: "",
+	UnitCollege:     UnitInstitution,
+	UnitDepartment:  UnitCollege,
+}
+
+// IsValidUnitType reports whether t is one of the known unit types
+func IsValidUnitType(t string) bool {
+	_, ok := parentUnitType[t]
+	return ok
+}
+
+// ParentUnitType returns the type the parent of a unit of type t must have; institutions have no parent
+func ParentUnitType(t string) string {
+	return parentUnitType[t]
+}
+
+// OrgUnit is an institution, college or department
+type OrgUnit struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	ParentID  *uuid.UUID `json:"parent_id" db:"parent_id"`
+	Type      string     `json:"type" db:"type"`
+	Name      string     `json:"name" db:"name"`
+	Code      string     `json:"code" db:"code"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	Children  []OrgUnit  `json:"children,omitempty" db:"-"`
+}
+
+type CreateUnitRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+	Type     string     `json:"type" binding:"required,oneof=institution college department"`
+	Name     string     `json:"name" binding:"required,max=255"`
+	Code     string     `json:"code" binding:"max=50"`
+}
+
+type UpdateUnitRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Code string `json:"code" binding:"max=50"`
+}
+
+// UserAffiliation links a user to a unit; a user has at most one primary affiliation
+type UserAffiliation struct {
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	UnitID    uuid.UUID `json:"unit_id" db:"unit_id"`
+	UnitName  string    `json:"unit_name"`
+	UnitType  string    `json:"unit_type"`
+	IsPrimary bool      `json:"is_primary" db:"is_primary"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type AddAffiliationRequest struct {
+	UnitID    uuid.UUID `json:"unit_id" binding:"required"`
+	IsPrimary bool      `json:"is_primary"`
+}
+
+// PaperStats summarises papers and projects, optionally restricted to a unit and everything below it
+type PaperStats struct {
+	Total           int            `json:"total"`
+	ByStatus        map[string]int `json:"by_status"`
+	Published       int            `json:"published"`
+	Projects        int            `json:"projects"`
+	Funding         float64        `json:"funding"`
+	DistinctAuthors int            `json:"distinct_authors"`
+	Reviews         int            `json:"reviews"`
+}
+
+// UnitStats is a unit's rolled-up statistics with a drill-down into its direct children
+type UnitStats struct {
+	Unit     OrgUnit     `json:"unit"`
+	Stats    PaperStats  `json:"stats"`
+	Children []UnitStats `json:"children,omitempty"`
+}
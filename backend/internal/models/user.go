@@ -29,6 +29,13 @@ type User struct {
 	EmploymentType string `json:"employment_type" db:"employment_type"`
 	Gender         string `json:"gender" db:"gender"`
 	DateOfBirth    string `json:"date_of_birth" db:"date_of_birth"`
+	ORCID          string `json:"orcid" db:"orcid"`
+
+	// Reviewer expertise: free keywords and ISCED-F broad field codes
+	ExpertiseKeywords []string `json:"expertise_keywords" db:"expertise_keywords"`
+	ExpertiseFields   []string `json:"expertise_fields" db:"expertise_fields"`
+
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
 }
 
 type CreateUserRequest struct {
@@ -65,13 +72,41 @@ type ResendCodeRequest struct {
 type LoginResponse struct {
 	User  User   `json:"user"`
 	Token string `json:"token"`
+	// ExpiresIn is the lifetime of Token in seconds
+	ExpiresIn    int       `json:"expires_in"`
+	RefreshToken string    `json:"refresh_token"`
+	SessionID    uuid.UUID `json:"session_id"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	// All ends every sign in of the user, not just this one
+	All bool `json:"all"`
 }
 
 type UpdateProfileRequest struct {
 	Name        string                 `json:"name"`
 	Avatar      string                 `json:"avatar"`
 	Bio         string                 `json:"bio"`
+	ORCID       string                 `json:"orcid"`
 	Preferences map[string]interface{} `json:"preferences"`
+
+	// Omit to keep the current expertise
+	ExpertiseKeywords []string `json:"expertise_keywords" binding:"omitempty,max=30"`
+	ExpertiseFields   []string `json:"expertise_fields" binding:"omitempty,dive,oneof=00 01 02 03 04 05 06 07 08 09 10"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
 }
 
 type ChangePasswordRequest struct {
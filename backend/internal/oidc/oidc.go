@@ -0,0 +1,234 @@
+// Package oidc signs users in with an OpenID Connect provider using the authorization code
+// flow with PKCE. The provider's endpoints are discovered from its issuer URL and ID tokens
+// are verified against the provider's published RS256 keys.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotConfigured means no issuer is set, so single sign-on is off
+	ErrNotConfigured = errors.New("single sign-on is not configured")
+	// ErrExchangeFailed means the provider refused the authorization code
+	ErrExchangeFailed = errors.New("authorization code exchange failed")
+)
+
+// Config identifies the application to the provider
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider is the part of a provider's discovery document the flow needs
+type Provider struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	CodeChallengeMethods  []string `json:"code_challenge_methods_supported"`
+}
+
+// Tokens is a token endpoint response
+type Tokens struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Client talks to one provider. Discovery happens on first use and is kept once it succeeds.
+type Client struct {
+	config Config
+	http   *http.Client
+
+	mu       sync.Mutex
+	provider *Provider
+	keys     *keySet
+}
+
+// NewClient returns a client for the configured provider; a nil http client uses a default
+// with a timeout
+func NewClient(config Config, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	return &Client{config: config, http: httpClient}
+}
+
+// Enabled reports whether an issuer is configured
+func (c *Client) Enabled() bool {
+	return c.config.Issuer != "" && c.config.ClientID != ""
+}
+
+// Issuer returns the configured issuer URL, which identifies linked accounts
+func (c *Client) Issuer() string {
+	return c.config.Issuer
+}
+
+// Discover fetches and checks the provider's discovery document
+func (c *Client) Discover(ctx context.Context) (*Provider, error) {
+	if !c.Enabled() {
+		return nil, ErrNotConfigured
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.provider != nil {
+		return c.provider, nil
+	}
+
+	var provider Provider
+	if err := getJSON(ctx, c.http, c.config.Issuer+"/.well-known/openid-configuration", &provider); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	// The document must describe the issuer it was fetched from, or tokens could be minted elsewhere
+	if strings.TrimSuffix(provider.Issuer, "/") != c.config.Issuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", provider.Issuer, c.config.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("discovery: provider metadata is incomplete")
+	}
+	if len(provider.CodeChallengeMethods) > 0 && !contains(provider.CodeChallengeMethods, "S256") {
+		return nil, errors.New("discovery: provider does not support S256 PKCE")
+	}
+
+	c.provider = &provider
+	c.keys = newKeySet(c.http, provider.JWKSURI)
+	return c.provider, nil
+}
+
+// AuthCodeURL returns the provider page that signs the user in and sends them back to the
+// redirect URL with a code
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	provider, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(provider.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.config.ClientID)
+	q.Set("redirect_uri", c.config.RedirectURL)
+	q.Set("scope", strings.Join(c.scopes(), " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", Challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// scopes always include openid, without which there is no ID token
+func (c *Client) scopes() []string {
+	if contains(c.config.Scopes, "openid") {
+		return c.config.Scopes
+	}
+	return append([]string{"openid"}, c.config.Scopes...)
+}
+
+// Exchange trades an authorization code and its PKCE verifier for tokens
+func (c *Client) Exchange(ctx context.Context, code, verifier string) (*Tokens, error) {
+	provider, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.config.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	// Confidential clients authenticate with HTTP basic auth, public clients only name themselves
+	if c.config.ClientSecret == "" {
+		form.Set("client_id", c.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		json.Unmarshal(body, &failure)
+		return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, failure.Error, failure.Description)
+	}
+
+	var tokens Tokens
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: no ID token in the response", ErrExchangeFailed)
+	}
+	return &tokens, nil
+}
+
+// SignIn completes the flow: it exchanges the code and verifies the ID token against the
+// nonce sent with the authorization request
+func (c *Client) SignIn(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	tokens, err := c.Exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	return c.VerifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
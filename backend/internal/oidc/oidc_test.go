@@ -0,0 +1,253 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"rpms-backend/internal/oidc"
+	"rpms-backend/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// authorize runs the browser's part of the flow against the test provider and returns the
+// code it redirected back with, along with the verifier and nonce of the request
+func authorize(t *testing.T, p *oidctest.Provider, client *oidc.Client) (code, verifier, nonce string) {
+	t.Helper()
+	state, _ := oidc.RandomString()
+	nonce, _ = oidc.RandomString()
+	verifier, _ = oidc.RandomString()
+
+	authURL, err := client.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, returned := p.Authorize(authURL)
+	if returned != state {
+		t.Fatalf("state %q came back as %q", state, returned)
+	}
+	return code, verifier, nonce
+}
+
+func TestSignIn(t *testing.T) {
+	m := oidctest.New(t)
+	m.SetUser(jwt.MapClaims{"sub": "staff-1001", "email": "a.bekele@smu.edu", "email_verified": true, "given_name": "Abebe", "family_name": "Bekele"})
+	client := m.Client()
+
+	code, verifier, nonce := authorize(t, m, client)
+	claims, err := client.SignIn(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "staff-1001" || claims.Email != "a.bekele@smu.edu" || !claims.EmailVerified {
+		t.Errorf("claims = %+v", claims)
+	}
+	if claims.Name != "Abebe Bekele" {
+		t.Errorf("name = %q, want the given and family names", claims.Name)
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	m := oidctest.New(t)
+	client := oidc.NewClient(oidc.Config{Issuer: m.Issuer() + "/", ClientID: oidctest.ClientID, RedirectURL: oidctest.RedirectURL, Scopes: []string{"email"}}, http.DefaultClient)
+
+	raw, err := client.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(raw)
+	q := u.Query()
+	if q.Get("code_challenge") != oidc.Challenge("verifier") || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("PKCE parameters = %q, %q", q.Get("code_challenge"), q.Get("code_challenge_method"))
+	}
+	if q.Get("scope") != "openid email" {
+		t.Errorf("scope = %q, openid must always be requested", q.Get("scope"))
+	}
+	if q.Get("redirect_uri") != oidctest.RedirectURL || q.Get("client_id") != oidctest.ClientID {
+		t.Errorf("client parameters = %v", q)
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	// BASE64URL(SHA256(verifier)) without padding, as computed by
+	// printf %s <verifier> | openssl dgst -sha256 -binary | base64 | tr '+/' '-_' | tr -d '='
+	if got := oidc.Challenge("rpms-code-verifier-0123456789-abcdefghijklmnop"); got != "7vmDatbPBDdcVobS0rwn80Sjr4vGau1Jzc6h2PMPJcA" {
+		t.Errorf("Challenge = %q", got)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	m := oidctest.New(t)
+	client := m.Client()
+
+	code, _, nonce := authorize(t, m, client)
+	other, _ := oidc.RandomString()
+	if _, err := client.SignIn(context.Background(), code, other, nonce); !errors.Is(err, oidc.ErrExchangeFailed) {
+		t.Errorf("err = %v, want ErrExchangeFailed", err)
+	}
+}
+
+func TestCodeIsSingleUse(t *testing.T) {
+	m := oidctest.New(t)
+	client := m.Client()
+
+	code, verifier, nonce := authorize(t, m, client)
+	if _, err := client.SignIn(context.Background(), code, verifier, nonce); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SignIn(context.Background(), code, verifier, nonce); !errors.Is(err, oidc.ErrExchangeFailed) {
+		t.Errorf("replayed code: err = %v, want ErrExchangeFailed", err)
+	}
+}
+
+func TestSignInRejectsTamperedTokens(t *testing.T) {
+	cases := map[string]func(jwt.MapClaims){
+		"other audience":  func(c jwt.MapClaims) { c["aud"] = "someone-else" },
+		"other issuer":    func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+		"expired":         func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no expiry":       func(c jwt.MapClaims) { delete(c, "exp") },
+		"issued later":    func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() },
+		"replayed nonce":  func(c jwt.MapClaims) { c["nonce"] = "from-another-sign-in" },
+		"no subject":      func(c jwt.MapClaims) { delete(c, "sub") },
+		"shared audience": func(c jwt.MapClaims) { c["aud"] = []string{oidctest.ClientID, "other-app"}; c["azp"] = "other-app" },
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := oidctest.New(t)
+			m.SetTamper(tamper)
+			client := m.Client()
+
+			code, verifier, nonce := authorize(t, m, client)
+			if _, err := client.SignIn(context.Background(), code, verifier, nonce); !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Errorf("err = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenSignature(t *testing.T) {
+	m := oidctest.New(t)
+	client := m.Client()
+	ctx := context.Background()
+
+	if _, err := client.VerifyIDToken(ctx, m.Sign(m.IDClaims("n")), "n"); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+
+	// Signed with a key the provider never published
+	stranger, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, m.IDClaims("n"))
+	forged.Header["kid"] = m.KeyID()
+	raw, _ := forged.SignedString(stranger)
+	if _, err := client.VerifyIDToken(ctx, raw, "n"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("forged signature: err = %v", err)
+	}
+
+	// Signed with the client secret, as if it were a shared key
+	hmac, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, m.IDClaims("n")).SignedString([]byte(oidctest.ClientSecret))
+	if _, err := client.VerifyIDToken(ctx, hmac, "n"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("HS256 token: err = %v", err)
+	}
+
+	// Body of one token under the signature of another
+	parts := strings.Split(m.Sign(m.IDClaims("n")), ".")
+	other := m.IDClaims("n")
+	other["sub"] = "admin"
+	parts[1] = strings.Split(m.Sign(other), ".")[1]
+	if _, err := client.VerifyIDToken(ctx, strings.Join(parts, "."), "n"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("swapped payload: err = %v", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	m := oidctest.New(t)
+	client := m.Client()
+	ctx := context.Background()
+
+	old := m.Sign(m.IDClaims("n"))
+	if _, err := client.VerifyIDToken(ctx, old, "n"); err != nil {
+		t.Fatal(err)
+	}
+	m.RotateKey()
+	if _, err := client.VerifyIDToken(ctx, m.Sign(m.IDClaims("n")), "n"); err != nil {
+		t.Fatalf("token signed with the rotated key: %v", err)
+	}
+	if _, err := client.VerifyIDToken(ctx, old, "n"); err != nil {
+		t.Fatalf("token signed with the previous key: %v", err)
+	}
+	if fetches := m.KeyFetches(); fetches != 2 {
+		t.Errorf("key set fetched %d times, want once initially and once after rotation", fetches)
+	}
+}
+
+func TestDiscoveryRejectsOtherIssuer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 "https://login.example.com",
+			"authorization_endpoint": "https://login.example.com/authorize",
+			"token_endpoint":         "https://login.example.com/token",
+			"jwks_uri":               "https://login.example.com/jwks",
+		})
+	}))
+	defer server.Close()
+
+	client := oidc.NewClient(oidc.Config{Issuer: server.URL, ClientID: oidctest.ClientID}, server.Client())
+	if _, err := client.Discover(context.Background()); err == nil {
+		t.Error("discovery accepted a document for another issuer")
+	}
+}
+
+func TestNotConfigured(t *testing.T) {
+	client := oidc.NewClient(oidc.Config{}, nil)
+	if client.Enabled() {
+		t.Error("client without an issuer is enabled")
+	}
+	if _, err := client.AuthCodeURL(context.Background(), "s", "n", "v"); !errors.Is(err, oidc.ErrNotConfigured) {
+		t.Errorf("err = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestRoleMapping(t *testing.T) {
+	rules, err := oidc.ParseRoleMap("rpms-admins=admin, research-office=coordinator,journal-editors=editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapping := oidc.RoleMapping{Claim: "groups", Rules: rules, Default: "author"}
+
+	cases := []struct {
+		claims map[string]interface{}
+		want   string
+	}{
+		{map[string]interface{}{"groups": []interface{}{"staff", "journal-editors"}}, "editor"},
+		{map[string]interface{}{"groups": []interface{}{"journal-editors", "rpms-admins"}}, "admin"},
+		{map[string]interface{}{"groups": "research-office"}, "coordinator"},
+		{map[string]interface{}{"groups": []interface{}{"students"}}, "author"},
+		{map[string]interface{}{}, "author"},
+	}
+	for _, tc := range cases {
+		if got := mapping.Role(tc.claims); got != tc.want {
+			t.Errorf("Role(%v) = %q, want %q", tc.claims, got, tc.want)
+		}
+	}
+
+	nested := oidc.RoleMapping{Claim: "realm_access.roles", Rules: rules, Default: "author"}
+	claims := map[string]interface{}{"realm_access": map[string]interface{}{"roles": []interface{}{"rpms-admins"}}}
+	if got := nested.Role(claims); got != "admin" {
+		t.Errorf("nested claim: Role = %q, want admin", got)
+	}
+
+	for _, bad := range []string{"admins", "=admin", "admins="} {
+		if _, err := oidc.ParseRoleMap(bad); err == nil {
+			t.Errorf("ParseRoleMap(%q) accepted a malformed rule", bad)
+		}
+	}
+}
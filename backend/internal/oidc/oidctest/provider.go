@@ -0,0 +1,279 @@
+// Package oidctest runs a local OpenID Connect provider for tests. Its authorization
+// endpoint signs in whichever user the test describes, without a login page, and its token
+// endpoint enforces client authentication, single-use codes and PKCE like a real provider.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"rpms-backend/internal/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// The client the provider knows
+const (
+	ClientID     = "rpms"
+	ClientSecret = "s3cret"
+	RedirectURL  = "https://rpms.example.edu/auth/sso/callback"
+)
+
+// pendingCode is an authorization code handed out and not yet exchanged
+type pendingCode struct {
+	challenge   string
+	redirectURI string
+	nonce       string
+	user        jwt.MapClaims
+}
+
+type Provider struct {
+	t      testing.TB
+	server *httptest.Server
+
+	mu         sync.Mutex
+	keys       map[string]*rsa.PrivateKey
+	kid        string
+	codes      map[string]pendingCode
+	user       jwt.MapClaims
+	tamper     func(jwt.MapClaims)
+	keyFetches int
+}
+
+// New starts a provider that is shut down when the test ends. Until SetUser is called it
+// signs in a user with only a subject.
+func New(t testing.TB) *Provider {
+	p := &Provider{t: t, keys: map[string]*rsa.PrivateKey{}, codes: map[string]pendingCode{}, user: jwt.MapClaims{"sub": "user-1"}}
+	p.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *Provider) Issuer() string {
+	return p.server.URL
+}
+
+// Config returns the client configuration the provider accepts
+func (p *Provider) Config() oidc.Config {
+	return oidc.Config{
+		Issuer:       p.Issuer(),
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// Client returns an oidc client of the provider
+func (p *Provider) Client() *oidc.Client {
+	return oidc.NewClient(p.Config(), p.server.Client())
+}
+
+// SetUser sets the claims of the user the next authorization request signs in; "sub" is required
+func (p *Provider) SetUser(claims jwt.MapClaims) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = claims
+}
+
+// SetTamper edits the claims of the ID tokens issued from now on, nil issuing them unchanged
+func (p *Provider) SetTamper(tamper func(jwt.MapClaims)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tamper = tamper
+}
+
+// RotateKey starts signing with a new key, still publishing the previous ones
+func (p *Provider) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.kid = fmt.Sprintf("key-%d", len(p.keys)+1)
+	p.keys[p.kid] = key
+}
+
+// KeyID returns the ID of the current signing key
+func (p *Provider) KeyID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.kid
+}
+
+// KeyFetches counts requests for the key set
+func (p *Provider) KeyFetches() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.keyFetches
+}
+
+// IDClaims returns valid ID token claims for the current user and the nonce
+func (p *Provider) IDClaims(nonce string) jwt.MapClaims {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.idClaims(p.user, nonce)
+}
+
+func (p *Provider) idClaims(user jwt.MapClaims, nonce string) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.Issuer(),
+		"aud":   ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+	}
+	for k, v := range user {
+		claims[k] = v
+	}
+	return claims
+}
+
+// Sign issues a token with the current key
+func (p *Provider) Sign(claims jwt.MapClaims) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sign(claims)
+}
+
+func (p *Provider) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.kid
+	signed, err := token.SignedString(p.keys[p.kid])
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return signed
+}
+
+// Authorize plays the browser: it opens an authorization URL and returns the code and state
+// the provider redirects back with
+func (p *Provider) Authorize(authURL string) (code, state string) {
+	p.t.Helper()
+	browser := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := browser.Get(authURL)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		p.t.Fatalf("authorization request: status %d", resp.StatusCode)
+	}
+	back, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return back.Query().Get("code"), back.Query().Get("state")
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                p.Issuer(),
+		"authorization_endpoint":                p.Issuer() + "/authorize",
+		"token_endpoint":                        p.Issuer() + "/token",
+		"jwks_uri":                              p.Issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != ClientID || q.Get("response_type") != "code" ||
+		q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	code, err := oidc.RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.mu.Lock()
+	p.codes[code] = pendingCode{
+		challenge:   q.Get("code_challenge"),
+		redirectURI: q.Get("redirect_uri"),
+		nonce:       q.Get("nonce"),
+		user:        p.user,
+	}
+	p.mu.Unlock()
+
+	back, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := back.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	back.RawQuery = params.Encode()
+	http.Redirect(w, r, back.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fail := func(status int, code string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": code})
+	}
+	if id, secret, ok := r.BasicAuth(); !ok || id != ClientID || secret != ClientSecret {
+		fail(http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		fail(http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	code := r.PostForm.Get("code")
+	pending, ok := p.codes[code]
+	delete(p.codes, code)
+	if !ok || pending.redirectURI != r.PostForm.Get("redirect_uri") ||
+		oidc.Challenge(r.PostForm.Get("code_verifier")) != pending.challenge {
+		fail(http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	claims := p.idClaims(pending.user, pending.nonce)
+	if p.tamper != nil {
+		p.tamper(claims)
+	}
+	json.NewEncoder(w).Encode(oidc.Tokens{AccessToken: "opaque", TokenType: "Bearer", IDToken: p.sign(claims), ExpiresIn: 300})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keyFetches++
+	keys := []map[string]string{}
+	for kid, key := range p.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA", "use": "sig", "alg": "RS256", "kid": kid,
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
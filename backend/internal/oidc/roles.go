@@ -0,0 +1,82 @@
+package oidc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RoleRule gives Role to users whose role claim contains Value
+type RoleRule struct {
+	Value string
+	Role  string
+}
+
+// RoleMapping derives a user's role from a claim of their ID token
+type RoleMapping struct {
+	// Claim names the claim, with dots reaching into nested objects such as "realm_access.roles"
+	Claim   string
+	Rules   []RoleRule
+	Default string
+}
+
+// ParseRoleMap reads rules written as "value=role,value=role"; earlier rules take precedence
+func ParseRoleMap(spec string) ([]RoleRule, error) {
+	var rules []RoleRule
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 || i == len(pair)-1 {
+			return nil, fmt.Errorf("role mapping %q is not of the form value=role", pair)
+		}
+		rules = append(rules, RoleRule{Value: strings.TrimSpace(pair[:i]), Role: strings.TrimSpace(pair[i+1:])})
+	}
+	return rules, nil
+}
+
+// Role returns the role of the first rule whose value the claim holds, or the default
+func (m RoleMapping) Role(claims map[string]interface{}) string {
+	values := claimValues(claims, m.Claim)
+	for _, rule := range m.Rules {
+		for _, v := range values {
+			if v == rule.Value {
+				return rule.Role
+			}
+		}
+	}
+	return m.Default
+}
+
+// claimValues returns a claim as a list of strings, whether the provider sent a list or a
+// single value
+func claimValues(claims map[string]interface{}, path string) []string {
+	if path == "" {
+		return nil
+	}
+	var value interface{} = claims
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
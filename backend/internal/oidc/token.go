@@ -0,0 +1,106 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidIDToken means an ID token failed verification
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+// clockSkew tolerates small differences between our clock and the provider's
+const clockSkew = time.Minute
+
+// Claims is the identity an ID token asserts
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// Raw holds every claim of the token, for mapping groups to roles
+	Raw map[string]interface{}
+}
+
+// VerifyIDToken checks an ID token's RS256 signature against the provider's keys, its issuer,
+// audience, expiry and nonce, and returns its claims
+func (c *Client) VerifyIDToken(ctx context.Context, raw, nonce string) (*Claims, error) {
+	provider, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	keys := c.keys
+	c.mu.Unlock()
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return keys.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(provider.Issuer),
+		jwt.WithAudience(c.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// A token for several audiences must have been issued to us (OIDC Core 3.1.3.7)
+	if audience, _ := claims.GetAudience(); len(audience) > 1 {
+		if azp, _ := claims["azp"].(string); azp != c.config.ClientID {
+			return nil, fmt.Errorf("%w: authorized party %q", ErrInvalidIDToken, azp)
+		}
+	}
+	// The nonce ties the token to the authorization request this sign in started with
+	if got, _ := claims["nonce"].(string); nonce == "" || subtle.ConstantTimeCompare([]byte(got), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+
+	out := &Claims{Subject: subject, Raw: claims}
+	out.Email, _ = claims["email"].(string)
+	out.Email = strings.TrimSpace(out.Email)
+	// Some providers send the flag as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		out.EmailVerified = verified
+	case string:
+		out.EmailVerified = verified == "true"
+	}
+	out.Name = displayName(claims)
+	return out, nil
+}
+
+// displayName picks the best name the provider gave, falling back to the email's local part
+func displayName(claims jwt.MapClaims) string {
+	str := func(name string) string {
+		s, _ := claims[name].(string)
+		return strings.TrimSpace(s)
+	}
+	if name := str("name"); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(str("given_name") + " " + str("family_name")); name != "" {
+		return name
+	}
+	if name := str("preferred_username"); name != "" {
+		return name
+	}
+	email := str("email")
+	if i := strings.Index(email, "@"); i > 0 {
+		return email[:i]
+	}
+	return email
+}
@@ -0,0 +1,76 @@
+// Package ratelimit counts requests per key in fixed windows, in memory. It is meant for
+// low-volume endpoints such as password reset where a single instance's view is enough.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// Limiter allows up to Limit events per key in each Window
+type Limiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastPrune time.Time
+}
+
+func New(limit int, per time.Duration) *Limiter {
+	return &Limiter{limit: limit, window: per, now: time.Now, windows: map[string]*window{}}
+}
+
+// Allow records an event for key and reports whether it is within the limit
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// RetryAfter is how long until key is allowed again; zero when it already is
+func (l *Limiter) RetryAfter(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[key]
+	if !ok || w.count < l.limit {
+		return 0
+	}
+	if wait := l.window - l.now().Sub(w.start); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// prune drops expired windows, at most once per window length
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.window {
+		return
+	}
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+	l.lastPrune = now
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterWindow(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := New(3, time.Hour)
+	l.now = func() time.Time { return clock }
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow("a@example.com") {
+			t.Fatalf("event %d should be allowed", i+1)
+		}
+	}
+	if l.Allow("a@example.com") {
+		t.Fatal("fourth event in the window should be refused")
+	}
+	if !l.Allow("b@example.com") {
+		t.Fatal("keys should be counted separately")
+	}
+	if got := l.RetryAfter("a@example.com"); got != time.Hour {
+		t.Errorf("RetryAfter = %v, want 1h", got)
+	}
+
+	clock = clock.Add(59 * time.Minute)
+	if l.Allow("a@example.com") {
+		t.Fatal("window has not elapsed yet")
+	}
+	clock = clock.Add(time.Minute)
+	if !l.Allow("a@example.com") {
+		t.Fatal("a new window should allow events again")
+	}
+	if got := l.RetryAfter("a@example.com"); got != 0 {
+		t.Errorf("RetryAfter = %v, want 0", got)
+	}
+}
+
+func TestLimiterPrune(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := New(1, time.Minute)
+	l.now = func() time.Time { return clock }
+
+	l.Allow("a")
+	l.Allow("b")
+	clock = clock.Add(2 * time.Minute)
+	l.Allow("c")
+
+	if len(l.windows) != 1 {
+		t.Errorf("expired windows kept: %d entries", len(l.windows))
+	}
+}
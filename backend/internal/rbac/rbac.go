@@ -0,0 +1,160 @@
+// Package rbac defines the named permissions routes are guarded by and the built-in roles
+// that group them. Roles are stored per tenant and can be edited by admins; a user holds
+// their primary role (users.role) plus any roles granted in user_roles.
+package rbac
+
+import "sort"
+
+// Permissions
+const (
+	PaperSubmit    = "paper.submit"
+	PaperReadAll   = "paper.read_all"
+	PaperEdit      = "paper.edit"
+	PaperRecommend = "paper.recommend"
+	PaperReview    = "paper.review"
+	PaperAssign    = "paper.assign"
+	PaperDiscuss   = "paper.discuss"
+	PaperPublish   = "paper.publish"
+
+	ReviewReadAll          = "review.read_all"
+	ReviewReadConfidential = "review.read_confidential"
+
+	UnitView            = "unit.view"
+	UnitManage          = "unit.manage"
+	CallManage          = "call.manage"
+	CallViewSubmissions = "call.view_submissions"
+	JournalManage       = "journal.manage"
+	EventPublish        = "event.publish"
+	NewsPublish         = "news.publish"
+
+	UserManage   = "user.manage"
+	RoleManage   = "role.manage"
+	TenantManage = "tenant.manage"
+	DataImport   = "data.import"
+	ReportView   = "report.view"
+	AuditRead    = "audit.read"
+
+	ServiceAccountManage = "service_account.manage"
+)
+
+// Descriptions documents every permission; a name not listed here is not a permission
+var Descriptions = map[string]string{
+	PaperSubmit:    "Submit papers and manage one's own submissions",
+	PaperReadAll:   "See every paper of the institution, not only one's own and published ones",
+	PaperEdit:      "Edit paper details, contributors and organizational unit",
+	PaperRecommend: "Recommend papers for publication",
+	PaperReview:    "Review any paper without an invitation",
+	PaperAssign:    "Invite reviewers and assign editorial staff",
+	PaperDiscuss:   "Take part in the internal editorial discussion",
+	PaperPublish:   "Schedule, embargo and retract publications",
+
+	ReviewReadAll:          "See all submitted reviews",
+	ReviewReadConfidential: "Read reviewers' confidential comments to the editor",
+
+	UnitView:            "See members and statistics of organizational units",
+	UnitManage:          "Create, edit and delete organizational units",
+	CallManage:          "Create, edit and delete calls for papers",
+	CallViewSubmissions: "See submissions and statistics of calls",
+	JournalManage:       "Manage journals, volumes and issues",
+	EventPublish:        "Create, edit and publish events",
+	NewsPublish:         "Create, edit and publish news",
+
+	UserManage:   "Create staff accounts and manage users",
+	RoleManage:   "Edit roles and grant them to users",
+	TenantManage: "Edit the institution's configuration",
+	DataImport:   "Import legacy data",
+	ReportView:   "See administrative statistics",
+	AuditRead:    "Search, export and verify the audit log",
+
+	ServiceAccountManage: "Manage service accounts and their API keys",
+}
+
+// Built-in roles, seeded for every tenant. They match the values of users.role.
+const (
+	RoleAuthor      = "author"
+	RoleEditor      = "editor"
+	RoleCoordinator = "coordinator"
+	RoleAdmin       = "admin"
+)
+
+// DefaultRoles are the permissions of the built-in roles, reproducing the access each
+// role had before permissions existed
+var DefaultRoles = map[string][]string{
+	RoleAuthor: {PaperSubmit},
+	RoleEditor: {
+		PaperReadAll, PaperEdit, PaperRecommend, PaperReview, PaperAssign, PaperDiscuss,
+		ReviewReadAll, ReviewReadConfidential,
+		UnitView, CallViewSubmissions, JournalManage,
+	},
+	RoleCoordinator: {
+		PaperReadAll, PaperEdit, PaperDiscuss,
+		ReviewReadAll,
+		UnitView, CallManage, CallViewSubmissions, EventPublish, NewsPublish,
+	},
+	RoleAdmin: All(),
+}
+
+// All returns every permission, sorted
+func All() []string {
+	all := make([]string, 0, len(Descriptions))
+	for name := range Descriptions {
+		all = append(all, name)
+	}
+	sort.Strings(all)
+	return all
+}
+
+// Valid reports whether name is a known permission
+func Valid(name string) bool {
+	_, ok := Descriptions[name]
+	return ok
+}
+
+// IsBuiltIn reports whether a role is one of the built-in roles
+func IsBuiltIn(role string) bool {
+	_, ok := DefaultRoles[role]
+	return ok
+}
+
+// BuiltInRolesWith returns the built-in roles that grant permission, sorted
+func BuiltInRolesWith(permission string) []string {
+	var roles []string
+	for role, permissions := range DefaultRoles {
+		for _, p := range permissions {
+			if p == permission {
+				roles = append(roles, role)
+				break
+			}
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Set is the combined permissions of a user's roles
+type Set map[string]bool
+
+func NewSet(permissions ...[]string) Set {
+	s := Set{}
+	for _, list := range permissions {
+		for _, p := range list {
+			s[p] = true
+		}
+	}
+	return s
+}
+
+// Has reports whether the set grants permission
+func (s Set) Has(permission string) bool {
+	return s[permission]
+}
+
+// List returns the permissions in the set, sorted
+func (s Set) List() []string {
+	list := make([]string, 0, len(s))
+	for p := range s {
+		list = append(list, p)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,82 @@
+package rbac
+
+import "testing"
+
+func TestDefaultRolesUseKnownPermissions(t *testing.T) {
+	for role, permissions := range DefaultRoles {
+		for _, p := range permissions {
+			if !Valid(p) {
+				t.Errorf("role %s has unknown permission %q", role, p)
+			}
+		}
+	}
+}
+
+func TestAdminHasEveryPermission(t *testing.T) {
+	admin := NewSet(DefaultRoles[RoleAdmin])
+	for _, p := range All() {
+		if !admin.Has(p) {
+			t.Errorf("admin lacks %s", p)
+		}
+	}
+}
+
+// The built-in roles must keep the access the old role checks gave
+func TestDefaultRolesMatchLegacyAccess(t *testing.T) {
+	cases := []struct {
+		permission string
+		roles      []string
+	}{
+		{PaperSubmit, []string{RoleAuthor, RoleAdmin}},
+		{PaperReview, []string{RoleEditor, RoleAdmin}},
+		{PaperEdit, []string{RoleEditor, RoleCoordinator, RoleAdmin}},
+		{PaperPublish, []string{RoleAdmin}},
+		{CallManage, []string{RoleCoordinator, RoleAdmin}},
+		{EventPublish, []string{RoleCoordinator, RoleAdmin}},
+		{JournalManage, []string{RoleEditor, RoleAdmin}},
+		{UserManage, []string{RoleAdmin}},
+	}
+	for _, tc := range cases {
+		allowed := map[string]bool{}
+		for _, r := range tc.roles {
+			allowed[r] = true
+		}
+		for role, permissions := range DefaultRoles {
+			if got := NewSet(permissions).Has(tc.permission); got != allowed[role] {
+				t.Errorf("%s has %s = %v, want %v", role, tc.permission, got, allowed[role])
+			}
+		}
+	}
+}
+
+func TestSetCombinesRoles(t *testing.T) {
+	lecturer := NewSet(DefaultRoles[RoleAuthor], DefaultRoles[RoleEditor])
+	if !lecturer.Has(PaperSubmit) || !lecturer.Has(PaperReview) {
+		t.Error("author and editor roles should combine")
+	}
+	if lecturer.Has(UserManage) {
+		t.Error("combined set gained an unrelated permission")
+	}
+	list := lecturer.List()
+	for i := 1; i < len(list); i++ {
+		if list[i-1] >= list[i] {
+			t.Fatalf("List not sorted: %v", list)
+		}
+	}
+}
+
+func TestBuiltInRolesWith(t *testing.T) {
+	got := BuiltInRolesWith(PaperDiscuss)
+	want := []string{RoleAdmin, RoleCoordinator, RoleEditor}
+	if len(got) != len(want) {
+		t.Fatalf("BuiltInRolesWith(%s) = %v, want %v", PaperDiscuss, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("BuiltInRolesWith(%s) = %v, want %v", PaperDiscuss, got, want)
+		}
+	}
+	if got := BuiltInRolesWith(ServiceAccountManage); len(got) != 1 || got[0] != RoleAdmin {
+		t.Errorf("BuiltInRolesWith(%s) = %v, want only admin", ServiceAccountManage, got)
+	}
+}
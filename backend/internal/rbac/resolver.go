@@ -0,0 +1,132 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"rpms-backend/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Resolver returns the permissions a user holds
+type Resolver interface {
+	Permissions(ctx context.Context, userID uuid.UUID) (Set, error)
+}
+
+const cacheTTL = 30 * time.Second
+
+type cached struct {
+	set     Set
+	roles   []string
+	expires time.Time
+}
+
+// HolderCondition returns an SQL condition on the users row aliased u that holds when the user
+// has the permission bound to parameter permissionArg through any of their roles. Like DBResolver,
+// a primary role the tenant has not stored falls back to the built-in roles bound to parameter
+// builtInArg, which should be BuiltInRolesWith(permission).
+func HolderCondition(permissionArg, builtInArg int) string {
+	return fmt.Sprintf(`(EXISTS (
+		SELECT 1 FROM roles r
+		WHERE r.tenant_id = u.tenant_id AND $%[1]d = ANY(r.permissions)
+		  AND (r.name = u.role OR r.id IN (SELECT role_id FROM user_roles WHERE user_id = u.id))
+	) OR (u.role = ANY($%[2]d::text[]) AND NOT EXISTS (
+		SELECT 1 FROM roles r WHERE r.tenant_id = u.tenant_id AND r.name = u.role
+	)))`, permissionArg, builtInArg)
+}
+
+// DBResolver reads roles from the roles and user_roles tables, caching each user's
+// permissions briefly. Role edits call Invalidate so they apply immediately on this instance.
+type DBResolver struct {
+	db     *database.Database
+	mu     sync.Mutex
+	byUser map[uuid.UUID]cached
+}
+
+func NewDBResolver(db *database.Database) *DBResolver {
+	return &DBResolver{db: db, byUser: map[uuid.UUID]cached{}}
+}
+
+func (r *DBResolver) Permissions(ctx context.Context, userID uuid.UUID) (Set, error) {
+	entry, err := r.lookup(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return entry.set, nil
+}
+
+// Roles returns the names of the roles a user holds, the primary role first
+func (r *DBResolver) Roles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	entry, err := r.lookup(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return entry.roles, nil
+}
+
+func (r *DBResolver) lookup(ctx context.Context, userID uuid.UUID) (cached, error) {
+	r.mu.Lock()
+	entry, ok := r.byUser[userID]
+	r.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry, nil
+	}
+
+	var primary string
+	if err := r.db.Pool.QueryRow(ctx, "SELECT role FROM users WHERE id = $1", userID).Scan(&primary); err != nil {
+		return cached{}, err
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT r.name, r.permissions
+		FROM roles r
+		JOIN users u ON u.tenant_id = r.tenant_id
+		WHERE u.id = $1
+		  AND (r.name = u.role OR r.id IN (SELECT role_id FROM user_roles WHERE user_id = $1))
+		ORDER BY (r.name = u.role) DESC, r.name
+	`, userID)
+	if err != nil {
+		return cached{}, err
+	}
+	defer rows.Close()
+
+	entry = cached{set: Set{}, expires: time.Now().Add(cacheTTL)}
+	primaryFound := false
+	for rows.Next() {
+		var name string
+		var permissions []string
+		if err := rows.Scan(&name, &permissions); err != nil {
+			return cached{}, err
+		}
+		primaryFound = primaryFound || name == primary
+		entry.roles = append(entry.roles, name)
+		for _, p := range permissions {
+			entry.set[p] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return cached{}, err
+	}
+	// Tenants created without seeded roles fall back to the built-in definition
+	if !primaryFound {
+		entry.roles = append([]string{primary}, entry.roles...)
+		for _, p := range DefaultRoles[primary] {
+			entry.set[p] = true
+		}
+	}
+
+	r.mu.Lock()
+	r.byUser[userID] = entry
+	r.mu.Unlock()
+	return entry, nil
+}
+
+// Invalidate drops all cached permissions
+func (r *DBResolver) Invalidate() {
+	r.mu.Lock()
+	r.byUser = map[uuid.UUID]cached{}
+	r.mu.Unlock()
+}
@@ -0,0 +1,142 @@
+// Package recommend ranks candidate reviewers for a paper by topic similarity,
+// current workload and conflicts of interest. Everything is computed locally from
+// the candidates' past papers and declared expertise.
+package recommend
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Paper is the submission reviewers are sought for
+type Paper struct {
+	Title    string
+	Abstract string
+	Keywords []string
+}
+
+// Candidate is a potential reviewer
+type Candidate struct {
+	UserID uuid.UUID
+	Name   string
+	Email  string
+	// Papers holds the title, abstract and keywords of each of the candidate's past papers
+	Papers []string
+	// Expertise holds declared expertise keywords and ISCED fields
+	Expertise []string
+	// OpenReviews is the number of reviews the candidate is currently working on
+	OpenReviews int
+	// Conflict explains why the candidate must not review the paper, if they must not
+	Conflict string
+}
+
+// Suggestion is a ranked candidate
+type Suggestion struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Score        float64   `json:"score"`
+	Similarity   float64   `json:"similarity"`
+	OpenReviews  int       `json:"open_reviews"`
+	MatchedTerms []string  `json:"matched_terms"`
+	Conflict     string    `json:"conflict,omitempty"`
+}
+
+// Options tune the ranking
+type Options struct {
+	// Limit caps the number of suggestions; 0 means no limit
+	Limit int
+	// WorkloadPenalty is how much each open review discounts a candidate's score
+	WorkloadPenalty float64
+	// ExpertiseWeight is how many times declared expertise counts relative to a past paper
+	ExpertiseWeight int
+	// IncludeConflicts lists conflicted candidates after everyone else instead of dropping them
+	IncludeConflicts bool
+}
+
+// DefaultOptions are the options used by the suggested reviewers endpoint
+var DefaultOptions = Options{Limit: 10, WorkloadPenalty: 0.25, ExpertiseWeight: 2}
+
+// keywordWeight is how many times the paper's own keywords count against its title and abstract
+const keywordWeight = 2
+
+func (p Paper) terms() []string {
+	terms := Tokenize(p.Title + " " + p.Abstract)
+	keywords := Tokenize(strings.Join(p.Keywords, " "))
+	for i := 0; i < keywordWeight; i++ {
+		terms = append(terms, keywords...)
+	}
+	return terms
+}
+
+func (c Candidate) terms(expertiseWeight int) []string {
+	var terms []string
+	for _, p := range c.Papers {
+		terms = append(terms, Tokenize(p)...)
+	}
+	expertise := Tokenize(strings.Join(c.Expertise, " "))
+	for i := 0; i < expertiseWeight; i++ {
+		terms = append(terms, expertise...)
+	}
+	return terms
+}
+
+// Rank scores every candidate against the paper. Candidates without any topical overlap
+// are left out. The score is the TF-IDF cosine similarity discounted by workload.
+func Rank(paper Paper, candidates []Candidate, opts Options) []Suggestion {
+	if opts.ExpertiseWeight <= 0 {
+		opts.ExpertiseWeight = 1
+	}
+
+	query := paper.terms()
+	profiles := make([][]string, len(candidates))
+	docs := make([][]string, 0, len(candidates)+1)
+	docs = append(docs, query)
+	for i, c := range candidates {
+		profiles[i] = c.terms(opts.ExpertiseWeight)
+		docs = append(docs, profiles[i])
+	}
+
+	corpus := NewCorpus(docs)
+	target := corpus.Vector(query)
+
+	suggestions := make([]Suggestion, 0, len(candidates))
+	for i, c := range candidates {
+		if c.Conflict != "" && !opts.IncludeConflicts {
+			continue
+		}
+		profile := corpus.Vector(profiles[i])
+		similarity := Cosine(target, profile)
+		if similarity == 0 {
+			continue
+		}
+		suggestions = append(suggestions, Suggestion{
+			UserID:       c.UserID,
+			Name:         c.Name,
+			Email:        c.Email,
+			Similarity:   similarity,
+			Score:        similarity / (1 + opts.WorkloadPenalty*float64(c.OpenReviews)),
+			OpenReviews:  c.OpenReviews,
+			MatchedTerms: topShared(target, profile, 5),
+			Conflict:     c.Conflict,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if (a.Conflict == "") != (b.Conflict == "") {
+			return a.Conflict == ""
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Name < b.Name
+	})
+
+	if opts.Limit > 0 && len(suggestions) > opts.Limit {
+		suggestions = suggestions[:opts.Limit]
+	}
+	return suggestions
+}
@@ -0,0 +1,100 @@
+package recommend
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("The Soils of the Rift-Valley: salinity studies in 2019, and irrigation!")
+	want := []string{"soil", "rift", "valley", "salinity", "study", "irrigation"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize = %v, want %v", got, want)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	a := Vector{"soil": 1, "water": 1}
+	if got := Cosine(a, a); got < 0.999 || got > 1.001 {
+		t.Errorf("identical vectors: got %f, want 1", got)
+	}
+	if got := Cosine(a, Vector{"malaria": 1}); got != 0 {
+		t.Errorf("disjoint vectors: got %f, want 0", got)
+	}
+	if got := Cosine(a, Vector{}); got != 0 {
+		t.Errorf("empty vector: got %f, want 0", got)
+	}
+}
+
+func TestIDFFavoursRareTerms(t *testing.T) {
+	corpus := NewCorpus([][]string{{"soil", "crop"}, {"soil", "malaria"}, {"soil"}})
+	if corpus.IDF("soil") >= corpus.IDF("malaria") {
+		t.Errorf("expected a common term to weigh less than a rare one: soil %f, malaria %f",
+			corpus.IDF("soil"), corpus.IDF("malaria"))
+	}
+}
+
+func TestRank(t *testing.T) {
+	paper := Paper{
+		Title:    "Irrigation scheduling and soil salinity in the Rift Valley",
+		Abstract: "We measure soil salinity under drip irrigation on smallholder farms.",
+		Keywords: []string{"soil salinity", "irrigation"},
+	}
+
+	soil := Candidate{UserID: uuid.New(), Name: "Soil Scientist",
+		Papers: []string{"Soil salinity mapping of irrigated farms", "Drip irrigation for smallholders"}}
+	busy := Candidate{UserID: uuid.New(), Name: "Busy Agronomist",
+		Papers: []string{"Soil salinity mapping of irrigated farms", "Drip irrigation for smallholders"}, OpenReviews: 4}
+	declared := Candidate{UserID: uuid.New(), Name: "Hydrologist", Expertise: []string{"irrigation", "water management"}}
+	unrelated := Candidate{UserID: uuid.New(), Name: "Epidemiologist", Papers: []string{"Malaria incidence in children"}}
+	coauthor := Candidate{UserID: uuid.New(), Name: "Co-author",
+		Papers: []string{"Soil salinity in the Rift Valley"}, Conflict: "co-author of the paper's author"}
+
+	got := Rank(paper, []Candidate{unrelated, busy, declared, coauthor, soil}, DefaultOptions)
+
+	var names []string
+	for _, s := range got {
+		names = append(names, s.Name)
+	}
+	want := []string{"Soil Scientist", "Busy Agronomist", "Hydrologist"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("Rank order = %v, want %v", names, want)
+	}
+
+	if math.Abs(got[0].Similarity-got[1].Similarity) > 1e-9 {
+		t.Errorf("expected equal similarity for identical profiles, got %f and %f", got[0].Similarity, got[1].Similarity)
+	}
+	if got[1].Score >= got[0].Score {
+		t.Errorf("expected workload to lower the score: %f >= %f", got[1].Score, got[0].Score)
+	}
+	if len(got[0].MatchedTerms) == 0 {
+		t.Error("expected matched terms to be reported")
+	}
+}
+
+func TestRankIncludeConflicts(t *testing.T) {
+	paper := Paper{Title: "Soil salinity"}
+	conflicted := Candidate{UserID: uuid.New(), Name: "A", Papers: []string{"Soil salinity"}, Conflict: "author"}
+	other := Candidate{UserID: uuid.New(), Name: "B", Papers: []string{"Soil erosion"}}
+
+	got := Rank(paper, []Candidate{conflicted, other}, Options{IncludeConflicts: true})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 suggestions, got %d", len(got))
+	}
+	if got[0].Name != "B" || got[1].Conflict != "author" {
+		t.Errorf("expected conflicted candidate last, got %+v", got)
+	}
+}
+
+func TestRankLimit(t *testing.T) {
+	var candidates []Candidate
+	for i := 0; i < 5; i++ {
+		candidates = append(candidates, Candidate{UserID: uuid.New(), Name: string(rune('A' + i)), Papers: []string{"soil"}})
+	}
+	if got := Rank(Paper{Title: "soil"}, candidates, Options{Limit: 3}); len(got) != 3 {
+		t.Errorf("expected 3 suggestions, got %d", len(got))
+	}
+}
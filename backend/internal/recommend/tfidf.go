@@ -0,0 +1,152 @@
+package recommend
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// stopwords are common English words that carry no topical signal
+var stopwords = map[string]bool{
+	"about": true, "after": true, "also": true, "among": true, "and": true, "are": true, "based": true,
+	"been": true, "between": true, "but": true, "can": true, "case": true, "for": true, "from": true,
+	"has": true, "have": true, "how": true, "into": true, "its": true, "not": true, "new": true,
+	"our": true, "paper": true, "results": true, "study": true, "such": true,
+	"than": true, "that": true, "the": true, "their": true, "these": true, "this": true, "through": true,
+	"using": true, "was": true, "were": true, "what": true, "which": true, "while": true, "with": true,
+	"within": true, "use": true, "used": true, "research": true, "analysis": true,
+}
+
+// Tokenize lowercases text and splits it into terms, dropping stopwords, numbers and
+// words shorter than three letters. Plural "s" endings are folded so that "soils"
+// and "soil" count as the same term.
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		if len([]rune(w)) < 3 || stopwords[w] || isNumber(w) {
+			continue
+		}
+		terms = append(terms, stem(w))
+	}
+	return terms
+}
+
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func stem(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case len(w) > 4 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// Vector is a sparse TF-IDF weighted term vector
+type Vector map[string]float64
+
+// termCounts counts the terms of a document
+func termCounts(terms []string) map[string]float64 {
+	counts := make(map[string]float64, len(terms))
+	for _, t := range terms {
+		counts[t]++
+	}
+	return counts
+}
+
+// Corpus holds the document frequencies used to weight term vectors
+type Corpus struct {
+	docs int
+	df   map[string]int
+}
+
+// NewCorpus builds document frequencies over the given tokenized documents
+func NewCorpus(docs [][]string) *Corpus {
+	c := &Corpus{docs: len(docs), df: make(map[string]int)}
+	for _, doc := range docs {
+		seen := make(map[string]bool, len(doc))
+		for _, t := range doc {
+			if !seen[t] {
+				seen[t] = true
+				c.df[t]++
+			}
+		}
+	}
+	return c
+}
+
+// IDF is the smoothed inverse document frequency of a term
+func (c *Corpus) IDF(term string) float64 {
+	return math.Log(float64(c.docs+1)/float64(c.df[term]+1)) + 1
+}
+
+// Vector weights a tokenized document by sublinear term frequency and IDF
+func (c *Corpus) Vector(terms []string) Vector {
+	v := make(Vector)
+	for t, n := range termCounts(terms) {
+		v[t] = (1 + math.Log(n)) * c.IDF(t)
+	}
+	return v
+}
+
+func (v Vector) norm() float64 {
+	var sum float64
+	for _, w := range v {
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// Cosine returns the cosine similarity of two vectors, 0 when either is empty
+func Cosine(a, b Vector) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot float64
+	for t, w := range a {
+		dot += w * b[t]
+	}
+	if dot == 0 {
+		return 0
+	}
+	return dot / (a.norm() * b.norm())
+}
+
+// topShared returns up to n terms present in both vectors, strongest first
+func topShared(a, b Vector, n int) []string {
+	type weighted struct {
+		term   string
+		weight float64
+	}
+	var shared []weighted
+	for t, w := range a {
+		if bw, ok := b[t]; ok {
+			shared = append(shared, weighted{t, w * bw})
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].weight != shared[j].weight {
+			return shared[i].weight > shared[j].weight
+		}
+		return shared[i].term < shared[j].term
+	})
+
+	terms := make([]string, 0, n)
+	for i := 0; i < len(shared) && i < n; i++ {
+		terms = append(terms, shared[i].term)
+	}
+	return terms
+}
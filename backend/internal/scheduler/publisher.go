@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"rpms-backend/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Publisher periodically publishes papers, news and events whose scheduled time
+// has arrived and lifts expired manuscript embargoes.
+type Publisher struct {
+	db       *database.Database
+	interval time.Duration
+}
+
+func NewPublisher(db *database.Database, interval time.Duration) *Publisher {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &Publisher{db: db, interval: interval}
+}
+
+// Start runs the publisher until ctx is cancelled
+func (p *Publisher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := p.RunOnce(ctx); err != nil {
+				log.Printf("[publisher] %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// scheduled is an item that just went public and whose owner should be told
+type scheduled struct {
+	id      uuid.UUID
+	title   string
+	ownerID *uuid.UUID
+}
+
+// RunOnce publishes everything that is due and lifts expired embargoes
+func (p *Publisher) RunOnce(ctx context.Context) error {
+	papers, err := p.collect(ctx, `
+		UPDATE papers
+		SET status = 'published', publication_date = COALESCE(publication_date, publish_at), updated_at = NOW()
+		WHERE status IN ('approved', 'recommended_for_publication') AND publish_at <= NOW() AND deleted_at IS NULL
+		RETURNING id, title, author_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to publish scheduled papers: %w", err)
+	}
+	for _, paper := range papers {
+		// The papers are already published, so a failure here is logged rather than stopping the run
+		if err := p.db.LockReviews(ctx, paper.id); err != nil {
+			log.Printf("[publisher] failed to lock reviews of paper %s: %v", paper.id, err)
+		}
+		p.notify(ctx, paper.ownerID, fmt.Sprintf("Your paper '%s' has been published", paper.title), &paper.id)
+		p.notifyRole(ctx, "editor", fmt.Sprintf("Scheduled paper '%s' has been published", paper.title), &paper.id)
+	}
+
+	news, err := p.collect(ctx, `
+		UPDATE news SET status = 'published', updated_at = NOW()
+		WHERE status = 'draft' AND publish_at <= NOW()
+		RETURNING id, title, editor_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to publish scheduled news: %w", err)
+	}
+	for _, item := range news {
+		p.notify(ctx, item.ownerID, fmt.Sprintf("Your scheduled news '%s' is now published", item.title), nil)
+	}
+
+	events, err := p.collect(ctx, `
+		UPDATE events SET status = 'published', updated_at = NOW()
+		WHERE status = 'draft' AND publish_at <= NOW()
+		RETURNING id, title, coordinator_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to publish scheduled events: %w", err)
+	}
+	for _, event := range events {
+		p.notify(ctx, event.ownerID, fmt.Sprintf("Your scheduled event '%s' is now published", event.title), nil)
+	}
+
+	lifted, err := p.collect(ctx, `
+		UPDATE papers SET embargo_until = NULL, updated_at = NOW()
+		WHERE embargo_until <= NOW() AND status = 'published' AND deleted_at IS NULL
+		RETURNING id, title, author_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to lift embargoes: %w", err)
+	}
+	for _, paper := range lifted {
+		p.notify(ctx, paper.ownerID, fmt.Sprintf("The embargo on your paper '%s' has ended and the manuscript is now available", paper.title), &paper.id)
+	}
+
+	if n := len(papers) + len(news) + len(events) + len(lifted); n > 0 {
+		log.Printf("[publisher] published %d papers, %d news, %d events; lifted %d embargoes", len(papers), len(news), len(events), len(lifted))
+	}
+	return nil
+}
+
+func (p *Publisher) collect(ctx context.Context, query string) ([]scheduled, error) {
+	rows, err := p.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []scheduled
+	for rows.Next() {
+		var item scheduled
+		if err := rows.Scan(&item.id, &item.title, &item.ownerID); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (p *Publisher) notify(ctx context.Context, userID *uuid.UUID, message string, paperID *uuid.UUID) {
+	if userID == nil {
+		return
+	}
+	if _, err := p.db.Pool.Exec(ctx,
+		"INSERT INTO notifications (user_id, message, paper_id) VALUES ($1, $2, $3)",
+		*userID, message, paperID); err != nil {
+		log.Printf("[publisher] failed to notify user %s: %v", *userID, err)
+	}
+}
+
+// notifyRole notifies everyone with the role at the paper's institution
+func (p *Publisher) notifyRole(ctx context.Context, role, message string, paperID *uuid.UUID) {
+	_, err := p.db.Pool.Exec(ctx, `
+		INSERT INTO notifications (user_id, message, paper_id)
+		SELECT id, $2, $3 FROM users WHERE role = $1 AND tenant_id = (SELECT tenant_id FROM papers WHERE id = $3)
+	`, role, message, paperID)
+	if err != nil {
+		log.Printf("[publisher] failed to notify %ss: %v", role, err)
+	}
+}
@@ -30,6 +30,17 @@ func NewSupabaseStorage(url, serviceRoleKey, bucketName string) *SupabaseStorage
 	}
 }
 
+// WithBucket returns a copy of the storage that writes to another bucket of the same project.
+// An empty name keeps the current bucket.
+func (s *SupabaseStorage) WithBucket(bucketName string) *SupabaseStorage {
+	if bucketName == "" || bucketName == s.BucketName {
+		return s
+	}
+	clone := *s
+	clone.BucketName = bucketName
+	return &clone
+}
+
 // UploadFile uploads a file to Supabase Storage and returns the public URL
 func (s *SupabaseStorage) UploadFile(file multipart.File, header *multipart.FileHeader) (string, error) {
 	// Generate unique filename
@@ -113,7 +113,7 @@ func (s *Client) Verify(email, token string) (*User, error) {
 	if resp.StatusCode != http.StatusOK {
 		var errResp map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		return nil, fmt.Errorf("supabase verify failed: %v", errResp)
+		return nil, &SupabaseError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("supabase verify failed: %v", errResp)}
 	}
 
 	var result VerifyResponse
@@ -205,7 +205,7 @@ func (s *Client) SignIn(email, password string) (*SignInResponse, error) {
 	if resp.StatusCode != http.StatusOK {
 		var errResp map[string]interface{}
 		json.NewDecoder(resp.Body).Decode(&errResp)
-		return nil, fmt.Errorf("supabase signin failed: %v", errResp)
+		return nil, &SupabaseError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("supabase signin failed: %v", errResp)}
 	}
 
 	var result SignInResponse
@@ -262,3 +262,71 @@ func (s *Client) AdminCreateUser(email, password string, userMetadata map[string
 
 	return &result, nil
 }
+
+type AdminUpdateUserRequest struct {
+	Password     string                 `json:"password,omitempty"`
+	BanDuration  string                 `json:"ban_duration,omitempty"`
+	UserMetadata map[string]interface{} `json:"user_metadata,omitempty"`
+}
+
+// AdminUpdatePassword sets a user's password without requiring the old one
+func (s *Client) AdminUpdatePassword(userID, password string) error {
+	return s.AdminUpdateUser(userID, AdminUpdateUserRequest{Password: password})
+}
+
+// AdminUpdateUser changes the given attributes of a user; empty fields are left untouched.
+// BanDuration takes a Go style duration such as "876000h", or "none" to lift a ban.
+func (s *Client) AdminUpdateUser(userID string, update AdminUpdateUserRequest) error {
+	url := fmt.Sprintf("%s/auth/v1/admin/users/%s", s.config.Supabase.URL, userID)
+	reqBody, _ := json.Marshal(update)
+
+	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("apikey", s.config.Supabase.ServiceRoleKey)
+	req.Header.Set("Authorization", "Bearer "+s.config.Supabase.ServiceRoleKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return &SupabaseError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("supabase admin update user failed: %v", errResp)}
+	}
+
+	return nil
+}
+
+// AdminDeleteUser removes a user from Supabase Auth
+func (s *Client) AdminDeleteUser(userID string) error {
+	url := fmt.Sprintf("%s/auth/v1/admin/users/%s", s.config.Supabase.URL, userID)
+
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("apikey", s.config.Supabase.ServiceRoleKey)
+	req.Header.Set("Authorization", "Bearer "+s.config.Supabase.ServiceRoleKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		var errResp map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return &SupabaseError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("supabase admin delete user failed: %v", errResp)}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package tenant
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"rpms-backend/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const cacheTTL = time.Minute
+
+type cached struct {
+	tenant  *Tenant
+	expires time.Time
+}
+
+// DBResolver resolves tenants from the tenants table, caching lookups briefly
+type DBResolver struct {
+	db     *database.Database
+	mu     sync.Mutex
+	byHost map[string]cached
+	byID   map[uuid.UUID]cached
+}
+
+func NewDBResolver(db *database.Database) *DBResolver {
+	return &DBResolver{
+		db:     db,
+		byHost: map[string]cached{},
+		byID:   map[uuid.UUID]cached{},
+	}
+}
+
+const tenantColumns = "id, slug, name, hostnames, config"
+
+func (r *DBResolver) ByHost(ctx context.Context, host string) (*Tenant, error) {
+	host = NormalizeHost(host)
+	r.mu.Lock()
+	if entry, ok := r.byHost[host]; ok && time.Now().Before(entry.expires) {
+		r.mu.Unlock()
+		return entry.tenant, nil
+	}
+	r.mu.Unlock()
+
+	t, err := r.load(ctx, "SELECT "+tenantColumns+" FROM tenants WHERE $1 = ANY(hostnames)", host)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.byHost[host] = cached{tenant: t, expires: time.Now().Add(cacheTTL)}
+	r.mu.Unlock()
+	return t, nil
+}
+
+func (r *DBResolver) ByID(ctx context.Context, id uuid.UUID) (*Tenant, error) {
+	r.mu.Lock()
+	if entry, ok := r.byID[id]; ok && time.Now().Before(entry.expires) {
+		r.mu.Unlock()
+		return entry.tenant, nil
+	}
+	r.mu.Unlock()
+
+	t, err := r.load(ctx, "SELECT "+tenantColumns+" FROM tenants WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.byID[id] = cached{tenant: t, expires: time.Now().Add(cacheTTL)}
+	r.mu.Unlock()
+	return t, nil
+}
+
+// Invalidate drops cached lookups, e.g. after a tenant's configuration changed
+func (r *DBResolver) Invalidate() {
+	r.mu.Lock()
+	r.byHost = map[string]cached{}
+	r.byID = map[uuid.UUID]cached{}
+	r.mu.Unlock()
+}
+
+func (r *DBResolver) load(ctx context.Context, query string, arg interface{}) (*Tenant, error) {
+	var t Tenant
+	err := r.db.Pool.QueryRow(ctx, query, arg).Scan(&t.ID, &t.Slug, &t.Name, &t.Hostnames, &t.Config)
+	if err == pgx.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,126 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"rpms-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// DefaultID is the tenant every pre-existing row belongs to and requests fall back to
+// when their hostname does not match any tenant.
+var DefaultID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// Default returns the built-in configuration of the default tenant, matching the row
+// seeded by the migrations
+func Default() *Tenant {
+	return &Tenant{
+		ID:   DefaultID,
+		Slug: "smu",
+		Name: "SMU",
+		Config: Config{
+			Branding:            Branding{DisplayName: "SMU"},
+			PublicationIDPrefix: "SMU_P",
+			PublicationIDStart:  201817001,
+		},
+	}
+}
+
+// ContextKey is the gin context key holding the resolved *Tenant
+const ContextKey = "tenant"
+
+var ErrNotFound = errors.New("tenant not found")
+
+type Branding struct {
+	DisplayName  string `json:"display_name"`
+	LogoURL      string `json:"logo_url"`
+	PrimaryColor string `json:"primary_color"`
+}
+
+// Config is the per-tenant configuration stored in tenants.config
+type Config struct {
+	Branding            Branding                 `json:"branding"`
+	PublicationIDPrefix string                   `json:"publication_id_prefix"`
+	PublicationIDStart  int64                    `json:"publication_id_start"`
+	Rubric              []models.RubricCriterion `json:"rubric"`
+	SupabaseBucket      string                   `json:"supabase_bucket"`
+	// MFARequiredRoles lists the roles that must sign in with a second factor
+	MFARequiredRoles []string `json:"mfa_required_roles"`
+}
+
+// RequiresMFA reports whether users with role must use two-factor authentication
+func (c Config) RequiresMFA(role string) bool {
+	for _, r := range c.MFARequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type Tenant struct {
+	ID        uuid.UUID `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	Hostnames []string  `json:"hostnames"`
+	Config    Config    `json:"config"`
+}
+
+// Resolver looks tenants up by request hostname or by ID (from a token claim)
+type Resolver interface {
+	ByHost(ctx context.Context, host string) (*Tenant, error)
+	ByID(ctx context.Context, id uuid.UUID) (*Tenant, error)
+}
+
+// NormalizeHost lowercases a Host header value and strips any port and trailing dot
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if strings.HasPrefix(host, "[") {
+		if i := strings.Index(host, "]"); i >= 0 {
+			return host[1:i]
+		}
+	}
+	if i := strings.LastIndex(host, ":"); i >= 0 && strings.Count(host, ":") == 1 {
+		host = host[:i]
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// PublicationIDPrefix returns the prefix of the tenant's publication IDs, e.g. "SMU_P"
+func (t *Tenant) PublicationIDPrefix() string {
+	if t.Config.PublicationIDPrefix != "" {
+		return t.Config.PublicationIDPrefix
+	}
+	return strings.ToUpper(t.Slug) + "_P"
+}
+
+// NextPublicationID returns the publication ID following last, the tenant's highest ID so far
+func (t *Tenant) NextPublicationID(last string) string {
+	prefix := t.PublicationIDPrefix()
+	start := t.Config.PublicationIDStart
+	if start <= 0 {
+		start = 1
+	}
+
+	if !strings.HasPrefix(last, prefix) {
+		return fmt.Sprintf("%s%d", prefix, start)
+	}
+	n, err := strconv.ParseInt(last[len(prefix):], 10, 64)
+	if err != nil || n < start {
+		return fmt.Sprintf("%s%d", prefix, start)
+	}
+	return fmt.Sprintf("%s%d", prefix, n+1)
+}
+
+// DisplayName is the name shown in the UI, falling back to the tenant name
+func (t *Tenant) DisplayName() string {
+	if t.Config.Branding.DisplayName != "" {
+		return t.Config.Branding.DisplayName
+	}
+	return t.Name
+}
@@ -0,0 +1,56 @@
+package tenant
+
+import "testing"
+
+func TestNormalizeHost(t *testing.T) {
+	cases := map[string]string{
+		"research.smu.edu":       "research.smu.edu",
+		"Research.SMU.edu:8443":  "research.smu.edu",
+		"research.smu.edu.":      "research.smu.edu",
+		" localhost:8080 ":       "localhost",
+		"[::1]:8080":             "::1",
+		"rpms.other-uni.edu.et.": "rpms.other-uni.edu.et",
+	}
+	for in, want := range cases {
+		if got := NormalizeHost(in); got != want {
+			t.Errorf("NormalizeHost(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNextPublicationID(t *testing.T) {
+	smu := &Tenant{Slug: "smu", Config: Config{PublicationIDPrefix: "SMU_P", PublicationIDStart: 201817001}}
+	other := &Tenant{Slug: "aau"}
+
+	cases := []struct {
+		tenant *Tenant
+		last   string
+		want   string
+	}{
+		{smu, "", "SMU_P201817001"},
+		{smu, "SMU_P201817001", "SMU_P201817002"},
+		{smu, "SMU_P201817999", "SMU_P201818000"},
+		{smu, "SMU_Pgarbage", "SMU_P201817001"},
+		{smu, "SMU_P5", "SMU_P201817001"},
+		{smu, "AAU_P7", "SMU_P201817001"},
+		{other, "", "AAU_P1"},
+		{other, "AAU_P41", "AAU_P42"},
+	}
+	for _, tc := range cases {
+		if got := tc.tenant.NextPublicationID(tc.last); got != tc.want {
+			t.Errorf("%s.NextPublicationID(%q) = %q, want %q", tc.tenant.Slug, tc.last, got, tc.want)
+		}
+	}
+}
+
+func TestRequiresMFA(t *testing.T) {
+	cfg := Config{MFARequiredRoles: []string{"admin", "editor"}}
+	for role, want := range map[string]bool{"admin": true, "editor": true, "author": false, "": false} {
+		if got := cfg.RequiresMFA(role); got != want {
+			t.Errorf("RequiresMFA(%q) = %v, want %v", role, got, want)
+		}
+	}
+	if (Config{}).RequiresMFA("admin") {
+		t.Error("no roles should require MFA by default")
+	}
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"rpms-backend/internal/api"
 	"rpms-backend/internal/config"
 	"rpms-backend/internal/database"
+	"rpms-backend/internal/scheduler"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -50,6 +53,16 @@ func main() {
 		if err := database.RunMigrations(db); err != nil {
 			log.Fatal("Failed to run migrations:", err)
 		}
+
+		// Publish scheduled papers, news and events in the background
+		interval, err := time.ParseDuration(cfg.Jobs.PublishInterval)
+		if err != nil {
+			log.Printf("Invalid PUBLISH_JOB_INTERVAL %q, using 1m", cfg.Jobs.PublishInterval)
+			interval = time.Minute
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		scheduler.NewPublisher(db, interval).Start(ctx)
 	}
 
 	// Initialize Gin router